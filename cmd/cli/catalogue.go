@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"what-to-watch/data"
+	"what-to-watch/genres"
+	"what-to-watch/handlers"
+)
+
+func manageCatalogue(h *handlers.Handlers, reader *bufio.Reader) {
+	fmt.Println("What would you like to do?")
+	fmt.Println("1. Add a show")
+	fmt.Println("2. Edit a show")
+	fmt.Println("3. Delete a show")
+	fmt.Print("Enter your choice (1-3): ")
+
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	switch input {
+	case "1":
+		addShow(h, reader)
+	case "2":
+		editShow(h, reader)
+	case "3":
+		deleteShow(h, reader)
+	default:
+		fmt.Println("Invalid input. Please enter a number from 1 to 3.")
+	}
+}
+
+func addShow(h *handlers.Handlers, reader *bufio.Reader) {
+	show, err := promptShowDetails(reader, data.Show{})
+	if err != nil {
+		fmt.Printf("Invalid input: %s\n", err)
+		return
+	}
+
+	added, err := h.AddShow(show)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Added %s to the catalogue.\n", added.Name)
+}
+
+func editShow(h *handlers.Handlers, reader *bufio.Reader) {
+	show, ok := selectCatalogueShow(h, reader, "Enter the Index of the show to edit (0 to cancel): ")
+	if !ok {
+		return
+	}
+
+	fmt.Println("Press enter to keep the current value.")
+	edited, err := promptShowDetails(reader, show)
+	if err != nil {
+		fmt.Printf("Invalid input: %s\n", err)
+		return
+	}
+
+	updated, err := h.UpdateShow(show.ID, edited)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Updated %s.\n", updated.Name)
+}
+
+func deleteShow(h *handlers.Handlers, reader *bufio.Reader) {
+	show, ok := selectCatalogueShow(h, reader, "Enter the Index of the show to delete (0 to cancel): ")
+	if !ok {
+		return
+	}
+
+	fmt.Printf("Delete %s from the catalogue? (y/N): ", show.Name)
+	input, _ := reader.ReadString('\n')
+	if !strings.EqualFold(strings.TrimSpace(input), "y") {
+		fmt.Println("No changes made.")
+		return
+	}
+
+	removed, err := h.DeleteShow(show.ID)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Deleted %s from the catalogue.\n", removed.Name)
+}
+
+// selectCatalogueShow lists the catalogue and prompts the user to pick a show from it.
+// It reports false if the user cancelled or the input was invalid.
+func selectCatalogueShow(h *handlers.Handlers, reader *bufio.Reader, prompt string) (data.Show, bool) {
+	shows, err := h.GetUnwatchedShows()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return data.Show{}, false
+	}
+
+	if len(shows) == 0 {
+		fmt.Println("No shows in the catalogue.")
+		return data.Show{}, false
+	}
+
+	fmt.Println(formatUnwatchedShowsTable(shows))
+
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" || input == "0" {
+		fmt.Println("No changes made.")
+		return data.Show{}, false
+	}
+
+	idx, err := strconv.Atoi(input)
+	if err != nil || idx < 1 || idx > len(shows) {
+		fmt.Printf("Invalid input: %s\n", input)
+		return data.Show{}, false
+	}
+
+	return shows[idx-1], true
+}
+
+// promptShowDetails prompts for each catalogue field, keeping the value from current
+// when the user enters nothing
+func promptShowDetails(reader *bufio.Reader, current data.Show) (data.Show, error) {
+	show := current
+	show.Name = promptField(reader, "Name", current.Name)
+	show.Genres = genres.Split(promptField(reader, "Genres (comma separated)", genres.Join(current.Genres)))
+	show.Provider = promptField(reader, "Provider", current.Provider)
+
+	counts := make([]string, len(current.Episodes))
+	for i, n := range current.Episodes {
+		counts[i] = strconv.Itoa(n)
+	}
+	episodes, err := parseEpisodeCounts(promptField(reader, "Episodes per series, e.g. 10,8,6", strings.Join(counts, ",")))
+	if err != nil {
+		return data.Show{}, err
+	}
+	show.Episodes = episodes
+
+	runtime, err := promptRuntime(reader, "Minutes per episode (0 if unknown)", current.Runtime)
+	if err != nil {
+		return data.Show{}, err
+	}
+	show.Runtime = runtime
+
+	runtimes := make([]string, len(current.SeriesRuntimes))
+	for i, n := range current.SeriesRuntimes {
+		runtimes[i] = strconv.Itoa(n)
+	}
+	seriesRuntimes, err := parseNumbers(promptField(reader, "Minutes per episode of each series if they differ, e.g. 30,45", strings.Join(runtimes, ",")), "runtimes")
+	if err != nil {
+		return data.Show{}, err
+	}
+	show.SeriesRuntimes = seriesRuntimes
+
+	return show, nil
+}
+
+// promptRuntime prompts for a number of minutes, keeping current when the user enters nothing
+func promptRuntime(reader *bufio.Reader, label string, current int) (int, error) {
+	value := ""
+	if current > 0 {
+		value = strconv.Itoa(current)
+	}
+
+	input := promptField(reader, label, value)
+	if input == "" {
+		return 0, nil
+	}
+
+	minutes, err := strconv.Atoi(input)
+	if err != nil {
+		return 0, fmt.Errorf("runtime must be a number of minutes, got %q", input)
+	}
+
+	return minutes, nil
+}
+
+// promptField prompts for a single value, showing and returning current if the user enters nothing
+func promptField(reader *bufio.Reader, label, current string) string {
+	if current != "" {
+		fmt.Printf("%s [%s]: ", label, current)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return current
+	}
+	return input
+}
+
+// parseEpisodeCounts parses a comma separated list of episode counts, one per series
+func parseEpisodeCounts(input string) ([]int, error) {
+	return parseNumbers(input, "episode counts")
+}
+
+// parseNumbers parses a comma separated list of numbers, naming what they are in any error
+func parseNumbers(input, what string) ([]int, error) {
+	var counts []int
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be numbers, got %q", what, part)
+		}
+		counts = append(counts, n)
+	}
+
+	return counts, nil
+}
@@ -2,48 +2,101 @@ package cli
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
+	"what-to-watch/data"
+	"what-to-watch/db"
+	"what-to-watch/films"
 	"what-to-watch/handlers"
+	"what-to-watch/history"
+	"what-to-watch/normalise"
+	"what-to-watch/recommend"
+	"what-to-watch/shows"
 )
 
-// Run starts the interactive CLI mode
-func Run() {
+// Run starts the interactive CLI mode backed by the given store, normalising genres and
+// providers with norm
+func Run(store db.Store, norm *normalise.Normaliser) {
+	h := handlers.NewWithNormaliser(store, norm)
 	reader := bufio.NewReader(os.Stdin)
 
 	// Display menu
 	fmt.Println("What would you like to view?")
 	fmt.Println("1. Currently watching shows")
 	fmt.Println("2. Films")
-	fmt.Println("3. Shows by genre")
-	fmt.Print("Enter your choice (1, 2, or 3): ")
+	fmt.Println("3. Browse by genre")
+	fmt.Println("4. Start watching a show")
+	fmt.Println("5. Completed shows")
+	fmt.Println("6. Watch history")
+	fmt.Println("7. Undo last watched episode")
+	fmt.Println("8. Jump to an episode")
+	fmt.Println("9. Manage show catalogue")
+	fmt.Println("10. Manage films")
+	fmt.Println("11. What should I watch tonight?")
+	fmt.Println("12. Pick something at random")
+	fmt.Println("13. Plan for the time I have")
+	fmt.Println("14. Viewing statistics")
+	fmt.Println("15. Browse by provider")
+	fmt.Println("16. Manage subscriptions")
+	fmt.Println("17. Search")
+	fmt.Print("Enter your choice (1-17): ")
 
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
 
 	switch input {
 	case "1":
-		viewShows(reader)
+		viewShows(h, reader)
 	case "2":
-		viewFilms()
+		viewFilms(h, reader)
 	case "3":
-		viewShowsByGenre(reader)
+		browseByGenre(h, reader)
+	case "4":
+		startWatchingShow(h, reader)
+	case "5":
+		viewCompletedShows(h)
+	case "6":
+		viewWatchHistory(h, reader)
+	case "7":
+		undoLastWatched(h)
+	case "8":
+		jumpToEpisode(h, reader)
+	case "9":
+		manageCatalogue(h, reader)
+	case "10":
+		manageFilms(h, reader)
+	case "11":
+		viewRecommendations(h, reader)
+	case "12":
+		randomPick(h, reader)
+	case "13":
+		planViewing(h, reader)
+	case "14":
+		viewStats(h)
+	case "15":
+		browseByProvider(h, reader)
+	case "16":
+		manageSubscriptions(h, reader)
+	case "17":
+		searchTitles(h, reader)
 	default:
-		fmt.Println("Invalid input. Please enter 1, 2, or 3.")
+		fmt.Println("Invalid input. Please enter a number from 1 to 17.")
 	}
 }
 
-func viewShows(reader *bufio.Reader) {
-	shows, err := handlers.GetCurrentlyWatchingShows()
+func viewShows(h *handlers.Handlers, reader *bufio.Reader) {
+	// the index typed is looked up in this same list, so narrow it before showing it
+	watching, err := h.GetCurrentlyWatchingShowsMatching(shows.Filter{SubscribedOnly: askSubscribedOnly(h, reader)})
 	if err != nil {
 		fmt.Printf("Error: %s\n", err)
 		return
 	}
 
-	fmt.Println(formatShowsTable(shows))
+	fmt.Println(formatShowsTable(watching))
 
 	// prompt user to mark a show as watched
 	fmt.Print("Enter the Index of the show you watched (0 to cancel): ")
@@ -55,55 +108,118 @@ func viewShows(reader *bufio.Reader) {
 	}
 
 	idx, err := strconv.Atoi(input)
-	if err != nil {
+	if err != nil || idx < 1 || idx > len(watching) {
 		fmt.Printf("Invalid input: %s\n", input)
 		return
 	}
 
-	isCompleted, err := handlers.MarkShowWatched(idx)
+	// prompt for how many episodes were watched, for catching up after a binge
+	fmt.Print("How many episodes did you watch? (1): ")
+	input, _ = reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	count := 1
+	if input != "" {
+		count, err = strconv.Atoi(input)
+		if err != nil || count < 1 {
+			fmt.Printf("Invalid input: %s\n", input)
+			return
+		}
+	}
+
+	isCompleted, err := h.MarkShowWatched(watching[idx-1].ID, count)
 	if err != nil {
 		fmt.Printf("Error: %s\n", err)
 		return
 	}
 
 	if isCompleted {
-		fmt.Printf("Show %d marked as watched and completed!\n", idx)
+		fmt.Printf("%s marked as watched and completed!\n", watching[idx-1].Name)
 	} else {
-		fmt.Printf("Show %d marked as watched.\n", idx)
+		fmt.Printf("%s marked as watched.\n", watching[idx-1].Name)
 	}
 }
 
-func viewFilms() {
-	films, err := handlers.GetAllFilms()
+// browseByGenre lists the unwatched shows and films with any, or all, of the chosen genres
+func browseByGenre(h *handlers.Handlers, reader *bufio.Reader) {
+	// Get available genres
+	genres, err := h.GetAvailableGenres()
 	if err != nil {
 		fmt.Printf("Error: %s\n", err)
 		return
 	}
 
-	fmt.Println(formatFilmsTable(films))
-}
+	if len(genres) == 0 {
+		fmt.Println("No genres available.")
+		return
+	}
 
-func viewShowsByGenre(reader *bufio.Reader) {
-	// Get available genres
-	genres, err := handlers.GetAvailableGenres()
+	// Display genres
+	fmt.Println("Available genres:")
+	fmt.Print(formatGenreCounts(genres))
+
+	// Get user selection, which may be several comma separated genres
+	fmt.Print("Enter the genre number, or several separated by commas (0 to cancel): ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" || input == "0" {
+		fmt.Println("No selection made.")
+		return
+	}
+
+	var selected []string
+	for _, part := range splitList(input) {
+		idx, err := strconv.Atoi(part)
+		if err != nil || idx < 1 || idx > len(genres) {
+			fmt.Printf("Invalid input: %s\n", part)
+			return
+		}
+		selected = append(selected, genres[idx-1].Genre)
+	}
+
+	matchAll := false
+	if len(selected) > 1 {
+		fmt.Print("Only show those with all of these genres? (y/N): ")
+		answer, _ := reader.ReadString('\n')
+		matchAll = strings.EqualFold(strings.TrimSpace(answer), "y")
+	}
+
+	subscribed := askSubscribedOnly(h, reader)
+
+	// Get shows and films for selected genres
+	unwatched, err := h.GetUnwatchedShowsMatching(shows.Filter{Genres: selected, MatchAll: matchAll, SubscribedOnly: subscribed})
 	if err != nil {
 		fmt.Printf("Error: %s\n", err)
 		return
 	}
 
-	if len(genres) == 0 {
-		fmt.Println("No genres available.")
+	unwatchedFilms, err := h.GetFilms(films.Filter{Genres: selected, MatchAll: matchAll, SubscribedOnly: subscribed})
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
 		return
 	}
 
-	// Display genres
-	fmt.Println("Available genres:")
-	for i, genre := range genres {
-		fmt.Printf("%d. %s\n", i+1, genre)
+	fmt.Printf("Unwatched shows and films in genre '%s':\n", strings.Join(selected, "', '"))
+	fmt.Println(formatBrowseTable(unwatched, unwatchedFilms))
+}
+
+// browseByProvider lists the unwatched shows and films on the chosen provider
+func browseByProvider(h *handlers.Handlers, reader *bufio.Reader) {
+	providers, err := h.GetAvailableProviders()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 
-	// Get user selection
-	fmt.Print("Enter the genre number (0 to cancel): ")
+	if len(providers) == 0 {
+		fmt.Println("No providers available.")
+		return
+	}
+
+	fmt.Println("Available providers:")
+	fmt.Print(formatProviderCounts(providers))
+
+	fmt.Print("Enter the provider number (0 to cancel): ")
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
 	if input == "" || input == "0" {
@@ -112,20 +228,300 @@ func viewShowsByGenre(reader *bufio.Reader) {
 	}
 
 	idx, err := strconv.Atoi(input)
-	if err != nil || idx < 1 || idx > len(genres) {
+	if err != nil || idx < 1 || idx > len(providers) {
+		fmt.Printf("Invalid input: %s\n", input)
+		return
+	}
+	provider := providers[idx-1].Provider
+
+	unwatched, err := h.GetUnwatchedShowsMatching(shows.Filter{Providers: []string{provider}})
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	unwatchedFilms, err := h.GetFilms(films.Filter{Providers: []string{provider}})
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Unwatched shows and films on %s:\n", provider)
+	fmt.Println(formatBrowseTable(unwatched, unwatchedFilms))
+}
+
+// maxSearchResults is the number of search results shown
+const maxSearchResults = 20
+
+// searchTitles searches the names, genres and providers of every show and film
+func searchTitles(h *handlers.Handlers, reader *bufio.Reader) {
+	fmt.Print("Search for: ")
+	query, _ := reader.ReadString('\n')
+	query = strings.TrimSpace(query)
+	if query == "" {
+		fmt.Println("No search entered.")
+		return
+	}
+
+	results, err := h.Search(query, maxSearchResults)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatSearchResults(results))
+}
+
+func startWatchingShow(h *handlers.Handlers, reader *bufio.Reader) {
+	shows, err := h.GetUnwatchedShows()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	if len(shows) == 0 {
+		fmt.Println("No unwatched shows available.")
+		return
+	}
+
+	fmt.Println(formatUnwatchedShowsTable(shows))
+
+	// prompt user to pick a show to start
+	fmt.Print("Enter the Index of the show to start watching (0 to cancel): ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" || input == "0" {
+		fmt.Println("No changes made.")
+		return
+	}
+
+	idx, err := strconv.Atoi(input)
+	if err != nil || idx < 1 || idx > len(shows) {
 		fmt.Printf("Invalid input: %s\n", input)
 		return
 	}
 
-	selectedGenre := genres[idx-1]
+	show, err := h.StartWatchingShow(shows[idx-1].ID)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Started watching %s from series 1 episode 1.\n", show.Name)
+}
+
+func viewCompletedShows(h *handlers.Handlers) {
+	shows, err := h.GetCompletedShows()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatCompletedShowsTable(shows))
+}
+
+func viewWatchHistory(h *handlers.Handlers, reader *bufio.Reader) {
+	// prompt for an optional date range
+	fmt.Print("Enter the start date (YYYY-MM-DD, blank for all): ")
+	fromInput, _ := reader.ReadString('\n')
+	fmt.Print("Enter the end date (YYYY-MM-DD, blank for all): ")
+	toInput, _ := reader.ReadString('\n')
+
+	from, to, err := history.ParseDateRange(strings.TrimSpace(fromInput), strings.TrimSpace(toInput))
+	if err != nil {
+		fmt.Printf("Invalid input: %s\n", err)
+		return
+	}
+
+	events, err := h.GetWatchHistory(from, to)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatWatchHistoryTable(events))
+}
+
+func undoLastWatched(h *handlers.Handlers) {
+	event, err := h.UndoLastWatched()
+	if errors.Is(err, history.ErrNothingToUndo) {
+		fmt.Println("Nothing to undo.")
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	if event.Kind == data.EventJump {
+		fmt.Printf("Undid the jump in %s, which is back on series %d episode %d.\n", event.Show, event.Series, event.Episode)
+		return
+	}
+	fmt.Printf("Undid %s series %d episode %d, which is now the next episode to watch.\n", event.Show, event.Series, event.Episode)
+}
+
+func jumpToEpisode(h *handlers.Handlers, reader *bufio.Reader) {
+	watching, err := h.GetCurrentlyWatchingShowsMatching(shows.Filter{SubscribedOnly: askSubscribedOnly(h, reader)})
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	if len(watching) == 0 {
+		fmt.Println("No shows currently being watched.")
+		return
+	}
+
+	fmt.Println(formatShowsTable(watching))
+
+	// prompt user to pick a show to move
+	fmt.Print("Enter the Index of the show to jump (0 to cancel): ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" || input == "0" {
+		fmt.Println("No changes made.")
+		return
+	}
+
+	idx, err := strconv.Atoi(input)
+	if err != nil || idx < 1 || idx > len(watching) {
+		fmt.Printf("Invalid input: %s\n", input)
+		return
+	}
+
+	// prompt for the position to jump to
+	fmt.Print("Enter the episode to jump to, e.g. S3E5: ")
+	input, _ = reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	series, episode, err := parseEpisodeCode(input)
+	if err != nil {
+		fmt.Printf("Invalid input: %s\n", input)
+		return
+	}
+
+	show, err := h.SetShowProgress(watching[idx-1].ID, series, episode)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("%s moved to series %d episode %d.\n", show.Name, series, episode)
+}
+
+// parseEpisodeCode parses an episode code such as "S3E5" (case-insensitive) into its
+// series and episode numbers
+func parseEpisodeCode(code string) (int, int, error) {
+	var series, episode int
+	if _, err := fmt.Sscanf(strings.ToUpper(code), "S%dE%d", &series, &episode); err != nil {
+		return 0, 0, fmt.Errorf("episode code must look like S3E5: %w", err)
+	}
+
+	return series, episode, nil
+}
+
+// recommendationCount is the number of recommendations shown in the CLI
+const recommendationCount = 5
+
+func viewRecommendations(h *handlers.Handlers, reader *bufio.Reader) {
+	fmt.Print("Favourite genres (comma separated, blank for none): ")
+	genres, _ := reader.ReadString('\n')
+	fmt.Print("Available providers (comma separated, blank for all): ")
+	providers, _ := reader.ReadString('\n')
+
+	prefs := recommend.Preferences{
+		Genres:         splitList(genres),
+		Providers:      splitList(providers),
+		SubscribedOnly: askSubscribedOnly(h, reader),
+	}
+
+	recs, err := h.GetRecommendations(prefs, recommendationCount)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatRecommendations(recs))
+}
+
+// splitList splits comma separated input into its trimmed, non-empty values
+func splitList(input string) []string {
+	var values []string
+	for _, v := range strings.Split(input, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func randomPick(h *handlers.Handlers, reader *bufio.Reader) {
+	fmt.Print("Genre (blank for any): ")
+	genre, _ := reader.ReadString('\n')
+	fmt.Print("Provider (blank for any): ")
+	provider, _ := reader.ReadString('\n')
+	fmt.Print("Show or film? (s/f, blank for either): ")
+	kind, _ := reader.ReadString('\n')
+	fmt.Print("Only shows in progress? (y/N): ")
+	inProgress, _ := reader.ReadString('\n')
+
+	filter := recommend.Filter{
+		Genre:          strings.TrimSpace(genre),
+		Provider:       strings.TrimSpace(provider),
+		InProgressOnly: strings.EqualFold(strings.TrimSpace(inProgress), "y"),
+	}
+
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "":
+	case "s", "show":
+		filter.Kind = recommend.KindShow
+	case "f", "film":
+		filter.Kind = recommend.KindFilm
+	default:
+		fmt.Println("Invalid input. Please enter s, f or leave blank.")
+		return
+	}
+
+	pick, err := h.RandomPick(filter, nil)
+	if errors.Is(err, recommend.ErrNoMatches) {
+		fmt.Println("Nothing matches those choices.")
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatPick(pick))
+}
+
+// planCount is the number of plans shown in the CLI
+const planCount = 5
+
+func planViewing(h *handlers.Handlers, reader *bufio.Reader) {
+	fmt.Print("How many minutes do you have? ")
+	input, _ := reader.ReadString('\n')
+	minutes, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || minutes < 1 {
+		fmt.Println("Invalid input. Please enter a positive number of minutes.")
+		return
+	}
+
+	plans, err := h.PlanViewing(minutes, planCount)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatPlans(plans))
+}
 
-	// Get shows for selected genre
-	shows, err := handlers.GetUnwatchedShowsByGenre(selectedGenre)
+func viewStats(h *handlers.Handlers) {
+	st, err := h.GetStats()
 	if err != nil {
 		fmt.Printf("Error: %s\n", err)
 		return
 	}
 
-	fmt.Printf("Unwatched shows in genre '%s':\n", selectedGenre)
-	fmt.Println(formatShowsByGenreTable(shows))
+	fmt.Println(formatStats(st))
 }
@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"what-to-watch/data"
+	"what-to-watch/films"
+	"what-to-watch/genres"
+	"what-to-watch/handlers"
+)
+
+func viewFilms(h *handlers.Handlers, reader *bufio.Reader) {
+	unwatched, err := h.GetFilms(films.Filter{SubscribedOnly: askSubscribedOnly(h, reader)})
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatFilmsTable(unwatched))
+	if len(unwatched) == 0 {
+		return
+	}
+
+	// prompt user to mark a film as watched
+	fmt.Print("Enter the Index of the film you watched (0 to cancel): ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" || input == "0" {
+		fmt.Println("No changes made.")
+		return
+	}
+
+	idx, err := strconv.Atoi(input)
+	if err != nil || idx < 1 || idx > len(unwatched) {
+		fmt.Printf("Invalid input: %s\n", input)
+		return
+	}
+
+	film, err := h.SetFilmWatched(unwatched[idx-1].ID, true)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("%s marked as watched.\n", film.Name)
+}
+
+func manageFilms(h *handlers.Handlers, reader *bufio.Reader) {
+	fmt.Println("What would you like to do?")
+	fmt.Println("1. Add a film")
+	fmt.Println("2. Edit a film")
+	fmt.Println("3. Delete a film")
+	fmt.Println("4. View all films, including watched")
+	fmt.Println("5. Mark a film as unwatched")
+	fmt.Print("Enter your choice (1-5): ")
+
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	switch input {
+	case "1":
+		addFilm(h, reader)
+	case "2":
+		editFilm(h, reader)
+	case "3":
+		deleteFilm(h, reader)
+	case "4":
+		viewAllFilms(h)
+	case "5":
+		unwatchFilm(h, reader)
+	default:
+		fmt.Println("Invalid input. Please enter a number from 1 to 5.")
+	}
+}
+
+func addFilm(h *handlers.Handlers, reader *bufio.Reader) {
+	film, err := promptFilmDetails(reader, data.Film{})
+	if err != nil {
+		fmt.Printf("Invalid input: %s\n", err)
+		return
+	}
+
+	added, err := h.AddFilm(film)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Added %s.\n", added.Name)
+}
+
+func editFilm(h *handlers.Handlers, reader *bufio.Reader) {
+	film, ok := selectFilm(h, reader, "Enter the Index of the film to edit (0 to cancel): ")
+	if !ok {
+		return
+	}
+
+	fmt.Println("Press enter to keep the current value.")
+	edited, err := promptFilmDetails(reader, film)
+	if err != nil {
+		fmt.Printf("Invalid input: %s\n", err)
+		return
+	}
+
+	updated, err := h.UpdateFilm(film.ID, edited)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Updated %s.\n", updated.Name)
+}
+
+func deleteFilm(h *handlers.Handlers, reader *bufio.Reader) {
+	film, ok := selectFilm(h, reader, "Enter the Index of the film to delete (0 to cancel): ")
+	if !ok {
+		return
+	}
+
+	fmt.Printf("Delete %s? (y/N): ", film.Name)
+	input, _ := reader.ReadString('\n')
+	if !strings.EqualFold(strings.TrimSpace(input), "y") {
+		fmt.Println("No changes made.")
+		return
+	}
+
+	removed, err := h.DeleteFilm(film.ID)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Deleted %s.\n", removed.Name)
+}
+
+func viewAllFilms(h *handlers.Handlers) {
+	films, err := h.GetAllFilms()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatFilmsTable(films))
+}
+
+func unwatchFilm(h *handlers.Handlers, reader *bufio.Reader) {
+	film, ok := selectFilm(h, reader, "Enter the Index of the film to mark as unwatched (0 to cancel): ")
+	if !ok {
+		return
+	}
+
+	updated, err := h.SetFilmWatched(film.ID, false)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("%s marked as unwatched.\n", updated.Name)
+}
+
+// selectFilm lists every film, including watched ones, and prompts the user to pick one.
+// It reports false if the user cancelled or the input was invalid.
+func selectFilm(h *handlers.Handlers, reader *bufio.Reader, prompt string) (data.Film, bool) {
+	films, err := h.GetAllFilms()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return data.Film{}, false
+	}
+
+	if len(films) == 0 {
+		fmt.Println("No films found.")
+		return data.Film{}, false
+	}
+
+	fmt.Println(formatFilmsTable(films))
+
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" || input == "0" {
+		fmt.Println("No changes made.")
+		return data.Film{}, false
+	}
+
+	idx, err := strconv.Atoi(input)
+	if err != nil || idx < 1 || idx > len(films) {
+		fmt.Printf("Invalid input: %s\n", input)
+		return data.Film{}, false
+	}
+
+	return films[idx-1], true
+}
+
+// promptFilmDetails prompts for each film field, keeping the value from current
+// when the user enters nothing
+func promptFilmDetails(reader *bufio.Reader, current data.Film) (data.Film, error) {
+	film := current
+	film.Name = promptField(reader, "Name", current.Name)
+	film.Genres = genres.Split(promptField(reader, "Genres (comma separated)", genres.Join(current.Genres)))
+	film.Provider = promptField(reader, "Provider", current.Provider)
+
+	runtime, err := promptRuntime(reader, "Runtime in minutes (0 if unknown)", current.Runtime)
+	if err != nil {
+		return data.Film{}, err
+	}
+	film.Runtime = runtime
+
+	return film, nil
+}
@@ -4,15 +4,40 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 	"what-to-watch/data"
+	"what-to-watch/genres"
+	"what-to-watch/planner"
+	"what-to-watch/recommend"
+	"what-to-watch/search"
+	"what-to-watch/shows"
+	"what-to-watch/stats"
+	"what-to-watch/subscriptions"
 )
 
-// formatShowsTable formats shows into a table string
+// formatShowsTable formats shows into a table string, with how far through each show the user is
 func formatShowsTable(s []data.Show) string {
 	if len(s) == 0 {
 		return "No shows currently being watched.\n"
 	}
 
+	// progress columns are "-" when a show's position isn't one of its episodes
+	watched := make([]string, len(s))
+	left := make([]string, len(s))
+	complete := make([]string, len(s))
+	timeLeft := make([]string, len(s))
+	for i, r := range s {
+		watched[i], left[i], complete[i], timeLeft[i] = "-", "-", "-", "-"
+		if p := r.Progress; p != nil {
+			watched[i] = fmt.Sprintf("%d/%d", p.Watched, p.Total)
+			left[i] = strconv.Itoa(p.Remaining)
+			complete[i] = fmt.Sprintf("%.0f%%", p.PercentComplete)
+			if p.MinutesRemaining > 0 {
+				timeLeft[i] = fmt.Sprintf("%.1fh", p.HoursRemaining)
+			}
+		}
+	}
+
 	// compute column widths
 	wIndex := len("Index")
 	wName := len("Name")
@@ -20,12 +45,16 @@ func formatShowsTable(s []data.Show) string {
 	wProvider := len("Provider")
 	wSeries := len("Series")
 	wEpisode := len("Episode")
+	wWatched := len("Watched")
+	wLeft := len("Left")
+	wComplete := len("Complete")
+	wTimeLeft := len("Time left")
 
-	for _, r := range s {
+	for i, r := range s {
 		if l := len(r.Name); l > wName {
 			wName = l
 		}
-		if l := len(r.Genre); l > wGenre {
+		if l := len(genres.Join(r.Genres)); l > wGenre {
 			wGenre = l
 		}
 		if l := len(r.Provider); l > wProvider {
@@ -37,16 +66,25 @@ func formatShowsTable(s []data.Show) string {
 		if l := len(r.Episode); l > wEpisode {
 			wEpisode = l
 		}
+		if l := len(watched[i]); l > wWatched {
+			wWatched = l
+		}
+		if l := len(left[i]); l > wLeft {
+			wLeft = l
+		}
+		if l := len(timeLeft[i]); l > wTimeLeft {
+			wTimeLeft = l
+		}
 	}
 
 	// build format string (left-aligned columns, two spaces between)
-	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
-		wIndex, wName, wGenre, wProvider, wSeries, wEpisode)
+	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
+		wIndex, wName, wGenre, wProvider, wSeries, wEpisode, wWatched, wLeft, wComplete, wTimeLeft)
 
 	var buf strings.Builder
 
 	// header
-	buf.WriteString(fmt.Sprintf(format, "Index", "Name", "Genre", "Provider", "Series", "Episode"))
+	buf.WriteString(fmt.Sprintf(format, "Index", "Name", "Genre", "Provider", "Series", "Episode", "Watched", "Left", "Complete", "Time left"))
 
 	// separator line
 	parts := []string{
@@ -56,94 +94,196 @@ func formatShowsTable(s []data.Show) string {
 		strings.Repeat("-", wProvider),
 		strings.Repeat("-", wSeries),
 		strings.Repeat("-", wEpisode),
+		strings.Repeat("-", wWatched),
+		strings.Repeat("-", wLeft),
+		strings.Repeat("-", wComplete),
+		strings.Repeat("-", wTimeLeft),
 	}
-	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3], parts[4], parts[5]))
+	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3], parts[4], parts[5], parts[6], parts[7], parts[8], parts[9]))
 
 	// rows
 	for i, r := range s {
-		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), r.Name, r.Genre, r.Provider, r.Series, r.Episode))
+		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), r.Name, genres.Join(r.Genres), r.Provider, r.Series, r.Episode,
+			watched[i], left[i], complete[i], timeLeft[i]))
 	}
 
 	return buf.String()
 }
 
-// formatShowsByGenreTable formats unwatched shows into a table string
-func formatShowsByGenreTable(s []data.Show) string {
-	if len(s) == 0 {
-		return "No unwatched shows in this genre.\n"
+// formatBrowseTable formats unwatched shows and films into a single table string, shows first
+func formatBrowseTable(s []data.Show, f []data.Film) string {
+	if len(s) == 0 && len(f) == 0 {
+		return "No unwatched shows or films found.\n"
+	}
+
+	type row struct{ kind, name, genres, provider string }
+	rows := make([]row, 0, len(s)+len(f))
+	for _, r := range s {
+		rows = append(rows, row{"show", r.Name, genres.Join(r.Genres), r.Provider})
+	}
+	for _, r := range f {
+		rows = append(rows, row{"film", r.Name, genres.Join(r.Genres), r.Provider})
 	}
 
 	// compute column widths
 	wIndex := len("Index")
+	wKind := len("Kind")
 	wName := len("Name")
+	wGenre := len("Genre")
 	wProvider := len("Provider")
 
-	for _, r := range s {
+	for _, r := range rows {
+		if l := len(r.name); l > wName {
+			wName = l
+		}
+		if l := len(r.genres); l > wGenre {
+			wGenre = l
+		}
+		if l := len(r.provider); l > wProvider {
+			wProvider = l
+		}
+	}
+
+	// build format string (left-aligned columns, two spaces between)
+	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
+		wIndex, wKind, wName, wGenre, wProvider)
+
+	var buf strings.Builder
+
+	// header
+	buf.WriteString(fmt.Sprintf(format, "Index", "Kind", "Name", "Genre", "Provider"))
+
+	// separator line
+	parts := []string{
+		strings.Repeat("-", wIndex),
+		strings.Repeat("-", wKind),
+		strings.Repeat("-", wName),
+		strings.Repeat("-", wGenre),
+		strings.Repeat("-", wProvider),
+	}
+	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3], parts[4]))
+
+	// rows
+	for i, r := range rows {
+		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), r.kind, r.name, r.genres, r.provider))
+	}
+
+	return buf.String()
+}
+
+// formatSearchResults formats search results into a table string, best match first
+func formatSearchResults(results []search.Result) string {
+	if len(results) == 0 {
+		return "No matches.\n"
+	}
+
+	// compute column widths
+	wIndex := len("Index")
+	wKind := len("Kind")
+	wName := len("Name")
+	wGenre := len("Genre")
+	wProvider := len("Provider")
+	wStatus := len("Status")
+
+	for _, r := range results {
 		if l := len(r.Name); l > wName {
 			wName = l
 		}
+		if l := len(genres.Join(r.Genres)); l > wGenre {
+			wGenre = l
+		}
 		if l := len(r.Provider); l > wProvider {
 			wProvider = l
 		}
+		if l := len(searchStatus(r)); l > wStatus {
+			wStatus = l
+		}
 	}
 
 	// build format string (left-aligned columns, two spaces between)
-	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds\n",
-		wIndex, wName, wProvider)
+	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
+		wIndex, wKind, wName, wGenre, wProvider, wStatus)
 
 	var buf strings.Builder
 
 	// header
-	buf.WriteString(fmt.Sprintf(format, "Index", "Name", "Provider"))
+	buf.WriteString(fmt.Sprintf(format, "Index", "Kind", "Name", "Genre", "Provider", "Status"))
 
 	// separator line
 	parts := []string{
 		strings.Repeat("-", wIndex),
+		strings.Repeat("-", wKind),
 		strings.Repeat("-", wName),
+		strings.Repeat("-", wGenre),
 		strings.Repeat("-", wProvider),
+		strings.Repeat("-", wStatus),
 	}
-	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2]))
+	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3], parts[4], parts[5]))
 
 	// rows
-	for i, r := range s {
-		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), r.Name, r.Provider))
+	for i, r := range results {
+		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), string(r.Kind), r.Name, genres.Join(r.Genres), r.Provider, searchStatus(r)))
 	}
 
 	return buf.String()
 }
 
+// searchStatus describes whether a search result is being watched or has been watched
+func searchStatus(r search.Result) string {
+	switch {
+	case r.Watching:
+		return "watching"
+	case r.Watched:
+		return "watched"
+	default:
+		return ""
+	}
+}
+
 // formatFilmsTable formats films into a table string
 func formatFilmsTable(films []data.Film) string {
 	if len(films) == 0 {
 		return "No films found.\n"
 	}
 
+	watched := make([]string, len(films))
+	for i, f := range films {
+		watched[i] = "-"
+		if f.WatchedAt != nil {
+			watched[i] = f.WatchedAt.Local().Format("2006-01-02")
+		}
+	}
+
 	// compute column widths
 	wIndex := len("Index")
 	wName := len("Name")
 	wGenre := len("Genre")
 	wProvider := len("Provider")
+	wWatched := len("Watched")
 
-	for _, f := range films {
+	for i, f := range films {
 		if l := len(f.Name); l > wName {
 			wName = l
 		}
-		if l := len(f.Genre); l > wGenre {
+		if l := len(genres.Join(f.Genres)); l > wGenre {
 			wGenre = l
 		}
 		if l := len(f.Provider); l > wProvider {
 			wProvider = l
 		}
+		if l := len(watched[i]); l > wWatched {
+			wWatched = l
+		}
 	}
 
 	// build format string (left-aligned columns, two spaces between)
-	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
-		wIndex, wName, wGenre, wProvider)
+	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
+		wIndex, wName, wGenre, wProvider, wWatched)
 
 	var buf strings.Builder
 
 	// header
-	buf.WriteString(fmt.Sprintf(format, "Index", "Name", "Genre", "Provider"))
+	buf.WriteString(fmt.Sprintf(format, "Index", "Name", "Genre", "Provider", "Watched"))
 
 	// separator line
 	parts := []string{
@@ -151,13 +291,422 @@ func formatFilmsTable(films []data.Film) string {
 		strings.Repeat("-", wName),
 		strings.Repeat("-", wGenre),
 		strings.Repeat("-", wProvider),
+		strings.Repeat("-", wWatched),
 	}
-	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3]))
+	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3], parts[4]))
 
 	// rows
 	for i, f := range films {
-		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), f.Name, f.Genre, f.Provider))
+		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), f.Name, genres.Join(f.Genres), f.Provider, watched[i]))
 	}
 
 	return buf.String()
 }
+
+// formatUnwatchedShowsTable formats shows that haven't been started into a table string
+func formatUnwatchedShowsTable(s []data.Show) string {
+	if len(s) == 0 {
+		return "No unwatched shows.\n"
+	}
+
+	// compute column widths
+	wIndex := len("Index")
+	wName := len("Name")
+	wGenre := len("Genre")
+	wProvider := len("Provider")
+	wSeries := len("Series")
+
+	for _, r := range s {
+		if l := len(r.Name); l > wName {
+			wName = l
+		}
+		if l := len(genres.Join(r.Genres)); l > wGenre {
+			wGenre = l
+		}
+		if l := len(r.Provider); l > wProvider {
+			wProvider = l
+		}
+	}
+
+	// build format string (left-aligned columns, two spaces between)
+	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
+		wIndex, wName, wGenre, wProvider, wSeries)
+
+	var buf strings.Builder
+
+	// header
+	buf.WriteString(fmt.Sprintf(format, "Index", "Name", "Genre", "Provider", "Series"))
+
+	// separator line
+	parts := []string{
+		strings.Repeat("-", wIndex),
+		strings.Repeat("-", wName),
+		strings.Repeat("-", wGenre),
+		strings.Repeat("-", wProvider),
+		strings.Repeat("-", wSeries),
+	}
+	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3], parts[4]))
+
+	// rows
+	for i, r := range s {
+		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), r.Name, genres.Join(r.Genres), r.Provider, strconv.Itoa(len(r.Episodes))))
+	}
+
+	return buf.String()
+}
+
+// formatCompletedShowsTable formats completed shows into a table string
+func formatCompletedShowsTable(s []data.Show) string {
+	if len(s) == 0 {
+		return "No completed shows.\n"
+	}
+
+	// compute column widths
+	wIndex := len("Index")
+	wName := len("Name")
+	wGenre := len("Genre")
+	wProvider := len("Provider")
+	wCompleted := len("Completed")
+
+	completed := make([]string, len(s))
+	for i, r := range s {
+		completed[i] = "-"
+		if r.CompletedAt != nil {
+			completed[i] = r.CompletedAt.Local().Format("2006-01-02")
+		}
+
+		if l := len(r.Name); l > wName {
+			wName = l
+		}
+		if l := len(genres.Join(r.Genres)); l > wGenre {
+			wGenre = l
+		}
+		if l := len(r.Provider); l > wProvider {
+			wProvider = l
+		}
+		if l := len(completed[i]); l > wCompleted {
+			wCompleted = l
+		}
+	}
+
+	// build format string (left-aligned columns, two spaces between)
+	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
+		wIndex, wName, wGenre, wProvider, wCompleted)
+
+	var buf strings.Builder
+
+	// header
+	buf.WriteString(fmt.Sprintf(format, "Index", "Name", "Genre", "Provider", "Completed"))
+
+	// separator line
+	parts := []string{
+		strings.Repeat("-", wIndex),
+		strings.Repeat("-", wName),
+		strings.Repeat("-", wGenre),
+		strings.Repeat("-", wProvider),
+		strings.Repeat("-", wCompleted),
+	}
+	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3], parts[4]))
+
+	// rows
+	for i, r := range s {
+		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), r.Name, genres.Join(r.Genres), r.Provider, completed[i]))
+	}
+
+	return buf.String()
+}
+
+// formatWatchHistoryTable formats watch history events into a table string
+func formatWatchHistoryTable(events []data.WatchEvent) string {
+	if len(events) == 0 {
+		return "No episodes watched in this period.\n"
+	}
+
+	watched := make([]string, len(events))
+	for i, e := range events {
+		watched[i] = e.WatchedAt.Local().Format("2006-01-02 15:04")
+	}
+
+	// compute column widths
+	wWatched := len("Watched")
+	wName := len("Name")
+	wSeries := len("Series")
+	wEpisode := len("Episode")
+
+	for i, r := range events {
+		if l := len(watched[i]); l > wWatched {
+			wWatched = l
+		}
+		if l := len(r.Show); l > wName {
+			wName = l
+		}
+		if l := len(strconv.Itoa(r.Series)); l > wSeries {
+			wSeries = l
+		}
+		if l := len(strconv.Itoa(r.Episode)); l > wEpisode {
+			wEpisode = l
+		}
+	}
+
+	// build format string (left-aligned columns, two spaces between)
+	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
+		wWatched, wName, wSeries, wEpisode)
+
+	var buf strings.Builder
+
+	// header
+	buf.WriteString(fmt.Sprintf(format, "Watched", "Name", "Series", "Episode"))
+
+	// separator line
+	parts := []string{
+		strings.Repeat("-", wWatched),
+		strings.Repeat("-", wName),
+		strings.Repeat("-", wSeries),
+		strings.Repeat("-", wEpisode),
+	}
+	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3]))
+
+	// rows
+	for i, r := range events {
+		buf.WriteString(fmt.Sprintf(format, watched[i], r.Show, strconv.Itoa(r.Series), strconv.Itoa(r.Episode)))
+	}
+
+	return buf.String()
+}
+
+// formatRecommendations formats a ranked list of recommendations, with the reasons for each
+func formatRecommendations(recs []recommend.Recommendation) string {
+	if len(recs) == 0 {
+		return "Nothing to recommend.\n"
+	}
+
+	var buf strings.Builder
+	for i, r := range recs {
+		title := r.Name
+		if r.Series > 0 {
+			title = fmt.Sprintf("%s (series %d episode %d)", r.Name, r.Series, r.Episode)
+		}
+
+		buf.WriteString(fmt.Sprintf("%d. %s - %s, %s on %s\n", i+1, title, r.Kind, genres.Join(r.Genres), r.Provider))
+		for _, reason := range r.Reasons {
+			buf.WriteString(fmt.Sprintf("   - %s\n", reason))
+		}
+	}
+
+	return buf.String()
+}
+
+// formatPick formats a randomly picked show or film
+func formatPick(p recommend.Pick) string {
+	if p.Series > 0 {
+		return fmt.Sprintf("Watch %s (%s, %s on %s), carrying on from series %d episode %d.", p.Name, p.Kind, genres.Join(p.Genres), p.Provider, p.Series, p.Episode)
+	}
+	return fmt.Sprintf("Watch %s (%s, %s on %s).", p.Name, p.Kind, genres.Join(p.Genres), p.Provider)
+}
+
+// formatPlans formats each viewing plan with the time it takes and what to watch
+func formatPlans(plans []planner.Plan) string {
+	if len(plans) == 0 {
+		return "Nothing fits in that time. Shows and films need a runtime to be planned.\n"
+	}
+
+	var buf strings.Builder
+	for i, p := range plans {
+		buf.WriteString(fmt.Sprintf("%d. %d minutes, %d spare\n", i+1, p.Minutes, p.Spare))
+		for _, item := range p.Items {
+			if item.Kind == recommend.KindFilm {
+				buf.WriteString(fmt.Sprintf("   - %s (%d min)\n", item.Name, item.Minutes))
+				continue
+			}
+			buf.WriteString(fmt.Sprintf("   - %s, %d %s from series %d episode %d (%d min)\n",
+				item.Name, item.Episodes, plural(item.Episodes, "episode"), item.Series, item.Episode, item.Minutes))
+		}
+	}
+
+	return buf.String()
+}
+
+// formatStats formats the viewing statistics as a set of short sections
+func formatStats(st stats.Stats) string {
+	var buf strings.Builder
+
+	buf.WriteString(fmt.Sprintf("Episodes watched: %d\n", st.EpisodesWatched))
+	buf.WriteString(fmt.Sprintf("Films watched: %d\n", st.FilmsWatched))
+	buf.WriteString(fmt.Sprintf("Current streak: %d %s\n", st.CurrentStreak, plural(st.CurrentStreak, "day")))
+
+	buf.WriteString("\nEpisodes per week\n")
+	for _, w := range st.EpisodesPerWeek {
+		line := fmt.Sprintf("  %s  %3d  %s", w.WeekStart, w.Episodes, strings.Repeat("#", w.Episodes))
+		buf.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+
+	buf.WriteString("\nFavourite genres\n")
+	buf.WriteString(formatCounts(st.FavouriteGenres))
+
+	buf.WriteString("\nBusiest providers\n")
+	buf.WriteString(formatCounts(st.BusiestProviders))
+
+	buf.WriteString("\nShows completed per month\n")
+	if len(st.CompletedPerMonth) == 0 {
+		buf.WriteString("  None yet.\n")
+	}
+	for _, m := range st.CompletedPerMonth {
+		buf.WriteString(fmt.Sprintf("  %s  %d\n", m.Month, m.Shows))
+	}
+
+	return buf.String()
+}
+
+// formatGenreCounts formats genres as a numbered list, with how many unwatched shows,
+// shows being watched and unwatched films each has
+func formatGenreCounts(counts []shows.GenreCount) string {
+	rows := make([]browseCount, len(counts))
+	for i, c := range counts {
+		rows[i] = browseCount{c.Genre, c.Unwatched, c.Watching, c.Films}
+	}
+	return formatBrowseCounts(rows)
+}
+
+// formatProviderCounts formats providers as a numbered list, with how many unwatched shows,
+// shows being watched and unwatched films are on each
+func formatProviderCounts(counts []shows.ProviderCount) string {
+	rows := make([]browseCount, len(counts))
+	for i, c := range counts {
+		rows[i] = browseCount{c.Provider, c.Unwatched, c.Watching, c.Films}
+	}
+	return formatBrowseCounts(rows)
+}
+
+// browseCount is a genre or provider with its counts, as listed when browsing
+type browseCount struct {
+	name                       string
+	unwatched, watching, films int
+}
+
+// formatBrowseCounts formats genres or providers as a numbered list with their counts
+func formatBrowseCounts(counts []browseCount) string {
+	width := 0
+	for _, c := range counts {
+		width = max(width, len(c.name))
+	}
+
+	// right-align the numbers so the counts line up past 9 entries
+	wIndex := len(strconv.Itoa(len(counts)))
+
+	var buf strings.Builder
+	for i, c := range counts {
+		buf.WriteString(fmt.Sprintf("%*d. %-*s  %d unwatched, %d watching, %d %s\n",
+			wIndex, i+1, width, c.name, c.unwatched, c.watching, c.films, plural(c.films, "film")))
+	}
+	return buf.String()
+}
+
+// formatSubscriptionsTable formats subscriptions into a table string, with whether each is active at now
+func formatSubscriptionsTable(subs []data.Subscription, now time.Time) string {
+	if len(subs) == 0 {
+		return "No subscriptions.\n"
+	}
+
+	// compute column widths
+	wIndex := len("Index")
+	wProvider := len("Provider")
+	wStart := len("2006-01-02")
+	wEnd := len("2006-01-02")
+	wStatus := len("Inactive")
+
+	for _, sub := range subs {
+		if l := len(sub.Provider); l > wProvider {
+			wProvider = l
+		}
+	}
+
+	// build format string (left-aligned columns, two spaces between)
+	format := fmt.Sprintf("%%-%ds  %%-%ds  %%-%ds  %%-%ds  %%-%ds\n",
+		wIndex, wProvider, wStart, wEnd, wStatus)
+
+	var buf strings.Builder
+
+	// header
+	buf.WriteString(fmt.Sprintf(format, "Index", "Provider", "Start", "End", "Status"))
+
+	// separator line
+	parts := []string{
+		strings.Repeat("-", wIndex),
+		strings.Repeat("-", wProvider),
+		strings.Repeat("-", wStart),
+		strings.Repeat("-", wEnd),
+		strings.Repeat("-", wStatus),
+	}
+	buf.WriteString(fmt.Sprintf(format, parts[0], parts[1], parts[2], parts[3], parts[4]))
+
+	// rows
+	for i, sub := range subs {
+		status := "Inactive"
+		if subscriptions.IsActive(sub, now) {
+			status = "Active"
+		}
+		buf.WriteString(fmt.Sprintf(format, strconv.Itoa(i+1), sub.Provider, formatDate(sub.Start), formatDate(sub.End), status))
+	}
+
+	return buf.String()
+}
+
+// formatDate formats an optional time as a YYYY-MM-DD date in the local time zone, or "-" when unset
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// formatSubscriptionReport formats how much of the backlog each provider unlocks, most first,
+// marking the providers currently subscribed to
+func formatSubscriptionReport(report []subscriptions.Unlock) string {
+	if len(report) == 0 {
+		return "Nothing in the backlog.\n"
+	}
+
+	width := 0
+	for _, u := range report {
+		width = max(width, len(u.Provider))
+	}
+
+	var buf strings.Builder
+	for _, u := range report {
+		marker := " "
+		if u.Active {
+			marker = "*"
+		}
+		buf.WriteString(fmt.Sprintf("%s %-*s  %d unwatched, %d watching, %d %s\n",
+			marker, width, u.Provider, u.Unwatched, u.Watching, u.Films, plural(u.Films, "film")))
+	}
+	buf.WriteString("* subscribed now\n")
+
+	return buf.String()
+}
+
+// formatCounts formats named counts as aligned lines
+func formatCounts(counts []stats.Count) string {
+	if len(counts) == 0 {
+		return "  Nothing watched yet.\n"
+	}
+
+	width := 0
+	for _, c := range counts {
+		width = max(width, len(c.Name))
+	}
+
+	var buf strings.Builder
+	for _, c := range counts {
+		buf.WriteString(fmt.Sprintf("  %-*s  %d\n", width, c.Name, c.Count))
+	}
+	return buf.String()
+}
+
+// plural returns word, with an s added unless n is 1
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
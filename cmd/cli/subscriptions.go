@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"what-to-watch/data"
+	"what-to-watch/handlers"
+	"what-to-watch/history"
+)
+
+func manageSubscriptions(h *handlers.Handlers, reader *bufio.Reader) {
+	fmt.Println("What would you like to do?")
+	fmt.Println("1. View subscriptions")
+	fmt.Println("2. Add or update a subscription")
+	fmt.Println("3. Remove a subscription")
+	fmt.Println("4. Which provider unlocks the most?")
+	fmt.Print("Enter your choice (1-4): ")
+
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	switch input {
+	case "1":
+		viewSubscriptions(h)
+	case "2":
+		setSubscription(h, reader)
+	case "3":
+		removeSubscription(h, reader)
+	case "4":
+		viewSubscriptionReport(h)
+	default:
+		fmt.Println("Invalid input. Please enter a number from 1 to 4.")
+	}
+}
+
+func viewSubscriptions(h *handlers.Handlers) {
+	subs, err := h.GetSubscriptions()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatSubscriptionsTable(subs, time.Now()))
+}
+
+func setSubscription(h *handlers.Handlers, reader *bufio.Reader) {
+	fmt.Print("Provider: ")
+	provider, _ := reader.ReadString('\n')
+
+	start, err := promptDate(reader, "Starts on (YYYY-MM-DD, blank if already started)")
+	if err != nil {
+		fmt.Printf("Invalid input: %s\n", err)
+		return
+	}
+	end, err := promptDate(reader, "Ends on (YYYY-MM-DD, blank if not cancelled)")
+	if err != nil {
+		fmt.Printf("Invalid input: %s\n", err)
+		return
+	}
+
+	saved, err := h.SetSubscription(data.Subscription{Provider: strings.TrimSpace(provider), Start: start, End: end})
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Saved subscription to %s.\n", saved.Provider)
+}
+
+func removeSubscription(h *handlers.Handlers, reader *bufio.Reader) {
+	subs, err := h.GetSubscriptions()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	if len(subs) == 0 {
+		fmt.Println("No subscriptions.")
+		return
+	}
+
+	fmt.Println(formatSubscriptionsTable(subs, time.Now()))
+
+	fmt.Print("Enter the Index of the subscription to remove (0 to cancel): ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" || input == "0" {
+		fmt.Println("No changes made.")
+		return
+	}
+
+	idx, err := strconv.Atoi(input)
+	if err != nil || idx < 1 || idx > len(subs) {
+		fmt.Printf("Invalid input: %s\n", input)
+		return
+	}
+
+	removed, err := h.DeleteSubscription(subs[idx-1].Provider)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Removed subscription to %s.\n", removed.Provider)
+}
+
+func viewSubscriptionReport(h *handlers.Handlers) {
+	report, err := h.GetSubscriptionReport()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	fmt.Println(formatSubscriptionReport(report))
+}
+
+// promptDate prompts for an optional YYYY-MM-DD date in the local time zone,
+// returning nil when the user enters nothing
+func promptDate(reader *bufio.Reader, label string) (*time.Time, error) {
+	fmt.Printf("%s: ", label)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, nil
+	}
+
+	d, err := time.ParseInLocation(history.DateLayout, input, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a date in YYYY-MM-DD format", input)
+	}
+	return &d, nil
+}
+
+// askSubscribedOnly asks whether to leave out anything on a provider without an active
+// subscription. It does not ask, and reports false, when there are no subscriptions.
+func askSubscribedOnly(h *handlers.Handlers, reader *bufio.Reader) bool {
+	subs, err := h.GetSubscriptions()
+	if err != nil || len(subs) == 0 {
+		return false
+	}
+
+	fmt.Print("Only include providers you subscribe to? (y/N): ")
+	answer, _ := reader.ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(answer), "y")
+}
@@ -1,44 +1,62 @@
 package http
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"what-to-watch/data"
+	"what-to-watch/db"
+	"what-to-watch/films"
 	"what-to-watch/handlers"
+	"what-to-watch/history"
+	"what-to-watch/normalise"
+	"what-to-watch/planner"
+	"what-to-watch/recommend"
+	"what-to-watch/search"
+	"what-to-watch/shows"
+	"what-to-watch/stats"
+	"what-to-watch/subscriptions"
 )
 
 // Handler defines the interface for business logic functions
 type Handler interface {
 	GetCurrentlyWatchingShows() ([]data.Show, error)
-	MarkShowWatched(idx int) (bool, error)
+	GetCurrentlyWatchingShowsMatching(filter shows.Filter) ([]data.Show, error)
+	MarkShowWatched(id, count int) (bool, error)
+	MarkShowWatchedByIndex(idx, count int, filter shows.Filter) (bool, error)
 	GetAllFilms() ([]data.Film, error)
-	GetAvailableGenres() ([]string, error)
-	GetUnwatchedShowsByGenre(genre string) ([]data.Show, error)
-}
-
-// defaultHandler uses the handlers package functions
-type defaultHandler struct{}
-
-func (h *defaultHandler) GetCurrentlyWatchingShows() ([]data.Show, error) {
-	return handlers.GetCurrentlyWatchingShows()
-}
-
-func (h *defaultHandler) MarkShowWatched(idx int) (bool, error) {
-	return handlers.MarkShowWatched(idx)
-}
-
-func (h *defaultHandler) GetAllFilms() ([]data.Film, error) {
-	return handlers.GetAllFilms()
-}
-
-func (h *defaultHandler) GetAvailableGenres() ([]string, error) {
-	return handlers.GetAvailableGenres()
-}
-
-func (h *defaultHandler) GetUnwatchedShowsByGenre(genre string) ([]data.Show, error) {
-	return handlers.GetUnwatchedShowsByGenre(genre)
+	GetAvailableGenres() ([]shows.GenreCount, error)
+	GetUnwatchedShowsMatching(filter shows.Filter) ([]data.Show, error)
+	GetAvailableProviders() ([]shows.ProviderCount, error)
+	GetUnwatchedShows() ([]data.Show, error)
+	StartWatchingShow(id int) (data.Show, error)
+	GetCompletedShows() ([]data.Show, error)
+	GetWatchHistory(from, to time.Time) ([]data.WatchEvent, error)
+	UndoLastWatched() (data.WatchEvent, error)
+	SetShowProgress(id, series, episode int) (data.Show, error)
+	AddShow(show data.Show) (data.Show, error)
+	UpdateShow(id int, show data.Show) (data.Show, error)
+	DeleteShow(id int) (data.Show, error)
+	GetUnwatchedFilms() ([]data.Film, error)
+	GetFilms(filter films.Filter) ([]data.Film, error)
+	AddFilm(film data.Film) (data.Film, error)
+	UpdateFilm(id int, film data.Film) (data.Film, error)
+	DeleteFilm(id int) (data.Film, error)
+	SetFilmWatched(id int, watched bool) (data.Film, error)
+	GetRecommendations(prefs recommend.Preferences, limit int) ([]recommend.Recommendation, error)
+	RandomPick(filter recommend.Filter, rng *rand.Rand) (recommend.Pick, error)
+	PlanViewing(minutes, limit int) ([]planner.Plan, error)
+	GetStats() (stats.Stats, error)
+	Search(query string, limit int) ([]search.Result, error)
+	GetSubscriptions() ([]data.Subscription, error)
+	SetSubscription(sub data.Subscription) (data.Subscription, error)
+	DeleteSubscription(provider string) (data.Subscription, error)
+	GetSubscriptionReport() ([]subscriptions.Unlock, error)
 }
 
 // Server holds the HTTP server instance
@@ -47,11 +65,12 @@ type Server struct {
 	handler Handler
 }
 
-// NewServer creates a new HTTP server
-func NewServer(port int) *Server {
+// NewServer creates a new HTTP server backed by the given store, normalising genres and
+// providers with norm
+func NewServer(port int, store db.Store, norm *normalise.Normaliser) *Server {
 	return &Server{
 		port:    port,
-		handler: &defaultHandler{},
+		handler: handlers.NewWithNormaliser(store, norm),
 	}
 }
 
@@ -65,25 +84,101 @@ func NewServerWithHandler(port int, handler Handler) *Server {
 
 // Start begins listening for HTTP requests
 func (s *Server) Start() error {
-	http.HandleFunc("/shows", func(w http.ResponseWriter, r *http.Request) {
+	s.registerRoutes(http.DefaultServeMux)
+
+	addr := fmt.Sprintf(":%d", s.port)
+	fmt.Printf("HTTP server listening on port %d\n", s.port)
+	return http.ListenAndServe(addr, nil)
+}
+
+// registerRoutes adds every endpoint to the given mux
+func (s *Server) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/shows", func(w http.ResponseWriter, r *http.Request) {
 		s.handleGetShows(w, r)
 	})
-	http.HandleFunc("/shows/watch", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/shows/watch", func(w http.ResponseWriter, r *http.Request) {
 		s.handleMarkShowWatched(w, r)
 	})
-	http.HandleFunc("/films", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/shows/catalogue", func(w http.ResponseWriter, r *http.Request) {
+		s.handleAddShow(w, r)
+	})
+	mux.HandleFunc("/shows/catalogue/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.handleCatalogueShow(w, r)
+	})
+	// registered as {action} rather than progress so that it does not conflict with
+	// /shows/catalogue/{id}, which takes precedence as the more specific pattern
+	mux.HandleFunc("/shows/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("action") != "progress" {
+			http.NotFound(w, r)
+			return
+		}
+		s.handleSetShowProgress(w, r)
+	})
+	mux.HandleFunc("/shows/undo", func(w http.ResponseWriter, r *http.Request) {
+		s.handleUndoLastWatched(w, r)
+	})
+	mux.HandleFunc("/shows/unwatched", func(w http.ResponseWriter, r *http.Request) {
+		s.handleGetUnwatchedShows(w, r)
+	})
+	mux.HandleFunc("/shows/start", func(w http.ResponseWriter, r *http.Request) {
+		s.handleStartWatchingShow(w, r)
+	})
+	mux.HandleFunc("/shows/completed", func(w http.ResponseWriter, r *http.Request) {
+		s.handleGetCompletedShows(w, r)
+	})
+	mux.HandleFunc("/history", func(w http.ResponseWriter, r *http.Request) {
+		s.handleGetWatchHistory(w, r)
+	})
+	mux.HandleFunc("/films", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			s.handleAddFilm(w, r)
+			return
+		}
 		s.handleGetFilms(w, r)
 	})
-	http.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/films/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.handleFilm(w, r)
+	})
+	mux.HandleFunc("/films/{id}/watched", func(w http.ResponseWriter, r *http.Request) {
+		s.handleSetFilmWatched(w, r)
+	})
+	mux.HandleFunc("/recommendations", func(w http.ResponseWriter, r *http.Request) {
+		s.handleGetRecommendations(w, r)
+	})
+	mux.HandleFunc("/random", func(w http.ResponseWriter, r *http.Request) {
+		s.handleRandomPick(w, r)
+	})
+	mux.HandleFunc("/plan", func(w http.ResponseWriter, r *http.Request) {
+		s.handlePlanViewing(w, r)
+	})
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		s.handleGetStats(w, r)
+	})
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		s.handleSearch(w, r)
+	})
+	mux.HandleFunc("/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			s.handleSetSubscription(w, r)
+			return
+		}
+		s.handleGetSubscriptions(w, r)
+	})
+	mux.HandleFunc("/subscriptions/report", func(w http.ResponseWriter, r *http.Request) {
+		s.handleGetSubscriptionReport(w, r)
+	})
+	mux.HandleFunc("/subscriptions/{provider}", func(w http.ResponseWriter, r *http.Request) {
+		s.handleDeleteSubscription(w, r)
+	})
+	mux.HandleFunc("/providers", func(w http.ResponseWriter, r *http.Request) {
+		s.handleGetProviders(w, r)
+	})
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
 		s.handleGetGenres(w, r)
 	})
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		s.handleHealth(w, r)
 	})
-
-	addr := fmt.Sprintf(":%d", s.port)
-	fmt.Printf("HTTP server listening on port %d\n", s.port)
-	return http.ListenAndServe(addr, nil)
 }
 
 func (s *Server) handleGetShows(w http.ResponseWriter, r *http.Request) {
@@ -92,15 +187,37 @@ func (s *Server) handleGetShows(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	genre := r.URL.Query().Get("genre")
-	if genre != "" {
-		shows, err := s.handler.GetUnwatchedShowsByGenre(genre)
+	// filtering by genre or provider lists unwatched shows instead. Several genres may be given,
+	// matching shows with any of them unless match=all, and several providers. subscribed=true
+	// leaves out shows on providers without an active subscription from either list
+	genres, providers := queryList(r, "genre"), queryList(r, "provider")
+	subscribed := r.URL.Query().Get("subscribed") == "true"
+	if len(genres) > 0 || len(providers) > 0 {
+		matchAll, err := queryMatchAll(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		filter := shows.Filter{Genres: genres, MatchAll: matchAll, Providers: providers, SubscribedOnly: subscribed}
+		unwatched, err := s.handler.GetUnwatchedShowsMatching(filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, unwatched)
+		return
+	}
+
+	if subscribed {
+		watching, err := s.handler.GetCurrentlyWatchingShowsMatching(shows.Filter{SubscribedOnly: true})
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err)
 			return
 		}
 
-		writeJSON(w, http.StatusOK, shows)
+		writeJSON(w, http.StatusOK, watching)
 		return
 	}
 
@@ -119,25 +236,222 @@ func (s *Server) handleMarkShowWatched(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	idx := r.URL.Query().Get("index")
-	if idx == "" {
-		writeError(w, http.StatusBadRequest, fmt.Errorf("index query parameter is required"))
+	count, err := queryCount(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var isCompleted bool
+	if idx := r.URL.Query().Get("index"); idx != "" && r.URL.Query().Get("id") == "" {
+		// index is the 1-based position in the list returned by GET /shows, with subscribed=true
+		// when the list was narrowed to subscribed providers
+		showIdx, err := strconv.Atoi(idx)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("index must be a valid integer"))
+			return
+		}
+
+		filter := shows.Filter{SubscribedOnly: r.URL.Query().Get("subscribed") == "true"}
+		isCompleted, err = s.handler.MarkShowWatchedByIndex(showIdx, count, filter)
+		if err != nil {
+			writeHandlerError(w, err)
+			return
+		}
+	} else {
+		id, err := queryID(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		isCompleted, err = s.handler.MarkShowWatched(id, count)
+		if err != nil {
+			writeHandlerError(w, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, isCompleted)
+}
+
+// progressRequest is the body of PUT /shows/{id}/progress
+type progressRequest struct {
+	Series  int `json:"series"`
+	Episode int `json:"episode"`
+}
+
+func (s *Server) handleSetShowProgress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeMethodError(w, http.MethodPut)
+		return
+	}
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req progressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	show, err := s.handler.SetShowProgress(id, req.Series, req.Episode)
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, show)
+}
+
+func (s *Server) handleAddShow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodError(w, http.MethodPost)
+		return
+	}
+
+	var show data.Show
+	if err := json.NewDecoder(r.Body).Decode(&show); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
 		return
 	}
 
-	showIdx, err := strconv.Atoi(idx)
+	added, err := s.handler.AddShow(show)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, fmt.Errorf("index must be a valid integer"))
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, added)
+}
+
+func (s *Server) handleCatalogueShow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		writeMethodError(w, http.MethodPut+" or "+http.MethodDelete)
+		return
+	}
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		removed, err := s.handler.DeleteShow(id)
+		if err != nil {
+			writeHandlerError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, removed)
+		return
+	}
+
+	var show data.Show
+	if err := json.NewDecoder(r.Body).Decode(&show); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
 		return
 	}
 
-	isCompleted, err := s.handler.MarkShowWatched(showIdx)
+	updated, err := s.handler.UpdateShow(id, show)
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) handleUndoLastWatched(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodError(w, http.MethodPost)
+		return
+	}
+
+	event, err := s.handler.UndoLastWatched()
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, event)
+}
+
+func (s *Server) handleGetUnwatchedShows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	shows, err := s.handler.GetUnwatchedShows()
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, isCompleted)
+	writeJSON(w, http.StatusOK, shows)
+}
+
+func (s *Server) handleStartWatchingShow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodError(w, http.MethodPost)
+		return
+	}
+
+	id, err := queryID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	show, err := s.handler.StartWatchingShow(id)
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, show)
+}
+
+func (s *Server) handleGetCompletedShows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	shows, err := s.handler.GetCompletedShows()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, shows)
+}
+
+func (s *Server) handleGetWatchHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	from, to, err := history.ParseDateRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	events, err := s.handler.GetWatchHistory(from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, events)
 }
 
 func (s *Server) handleGetFilms(w http.ResponseWriter, r *http.Request) {
@@ -146,13 +460,218 @@ func (s *Server) handleGetFilms(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	films, err := s.handler.GetAllFilms()
+	// watched films are hidden unless all=true is given
+	all := r.URL.Query().Get("all") == "true"
+	getFilms := s.handler.GetUnwatchedFilms
+	if all {
+		getFilms = s.handler.GetAllFilms
+	}
+
+	// genre and provider may each be repeated or comma separated, with match=all requiring every genre,
+	// and subscribed=true leaves out films on providers without an active subscription
+	genres, providers := queryList(r, "genre"), queryList(r, "provider")
+	subscribed := r.URL.Query().Get("subscribed") == "true"
+	if len(genres) > 0 || len(providers) > 0 || subscribed {
+		matchAll, err := queryMatchAll(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		filter := films.Filter{Genres: genres, MatchAll: matchAll, Providers: providers, IncludeWatched: all, SubscribedOnly: subscribed}
+		getFilms = func() ([]data.Film, error) { return s.handler.GetFilms(filter) }
+	}
+
+	result, err := getFilms()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleAddFilm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodError(w, http.MethodPost)
+		return
+	}
+
+	var film data.Film
+	if err := json.NewDecoder(r.Body).Decode(&film); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	added, err := s.handler.AddFilm(film)
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, added)
+}
+
+func (s *Server) handleFilm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		writeMethodError(w, http.MethodPut+" or "+http.MethodDelete)
+		return
+	}
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		removed, err := s.handler.DeleteFilm(id)
+		if err != nil {
+			writeHandlerError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, removed)
+		return
+	}
+
+	var film data.Film
+	if err := json.NewDecoder(r.Body).Decode(&film); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	updated, err := s.handler.UpdateFilm(id, film)
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// handleSetFilmWatched marks a film as watched on POST and as unwatched on DELETE
+func (s *Server) handleSetFilmWatched(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		writeMethodError(w, http.MethodPost+" or "+http.MethodDelete)
+		return
+	}
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	film, err := s.handler.SetFilmWatched(id, r.Method == http.MethodPost)
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, film)
+}
+
+// defaultRecommendations is the number of recommendations returned when no limit is given
+const defaultRecommendations = 5
+
+func (s *Server) handleGetRecommendations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	limit, err := queryLimit(r, defaultRecommendations)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	prefs := recommend.Preferences{
+		Genres:         queryList(r, "genre"),
+		Providers:      queryList(r, "provider"),
+		SubscribedOnly: r.URL.Query().Get("subscribed") == "true",
+	}
+
+	recs, err := s.handler.GetRecommendations(prefs, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recs)
+}
+
+func (s *Server) handleRandomPick(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	filter, err := queryFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rng, err := querySeed(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	pick, err := s.handler.RandomPick(filter, rng)
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pick)
+}
+
+// defaultPlans is the number of plans returned when no limit is given
+const defaultPlans = 5
+
+func (s *Server) handlePlanViewing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	minutes, err := queryMinutes(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	limit, err := queryLimit(r, defaultPlans)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	plans, err := s.handler.PlanViewing(minutes, limit)
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plans)
+}
+
+func (s *Server) handleGetStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	st, err := s.handler.GetStats()
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, films)
+	writeJSON(w, http.StatusOK, st)
 }
 
 func (s *Server) handleGetGenres(w http.ResponseWriter, r *http.Request) {
@@ -170,6 +689,113 @@ func (s *Server) handleGetGenres(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, genres)
 }
 
+func (s *Server) handleGetProviders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	providers, err := s.handler.GetAvailableProviders()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, providers)
+}
+
+// defaultSearchResults is the number of search results returned when no limit is given
+const defaultSearchResults = 20
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("q query parameter is required"))
+		return
+	}
+
+	limit, err := queryLimit(r, defaultSearchResults)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	results, err := s.handler.Search(query, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) handleGetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet+" or "+http.MethodPost)
+		return
+	}
+
+	subs, err := s.handler.GetSubscriptions()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subs)
+}
+
+// handleSetSubscription adds a subscription, or replaces the one to the same provider
+func (s *Server) handleSetSubscription(w http.ResponseWriter, r *http.Request) {
+	var sub data.Subscription
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	saved, err := s.handler.SetSubscription(sub)
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, saved)
+}
+
+func (s *Server) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeMethodError(w, http.MethodDelete)
+		return
+	}
+
+	removed, err := s.handler.DeleteSubscription(r.PathValue("provider"))
+	if err != nil {
+		writeHandlerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, removed)
+}
+
+func (s *Server) handleGetSubscriptionReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodError(w, http.MethodGet)
+		return
+	}
+
+	report, err := s.handler.GetSubscriptionReport()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeMethodError(w, http.MethodGet)
@@ -5,51 +5,201 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
 	"what-to-watch/data"
+	"what-to-watch/db"
+	"what-to-watch/films"
+	"what-to-watch/history"
+	"what-to-watch/normalise"
+	"what-to-watch/planner"
+	"what-to-watch/recommend"
+	"what-to-watch/search"
+	"what-to-watch/shows"
+	"what-to-watch/stats"
+	"what-to-watch/subscriptions"
 )
 
 // mockHandler implements the Handler interface for testing
 type mockHandler struct {
-	getShowsFunc            func() ([]data.Show, error)
-	markShowWatchedFunc     func(idx int) (bool, error)
-	getFilmsFunc            func() ([]data.Film, error)
-	getGenresFunc           func() ([]string, error)
-	getUnwatchedByGenreFunc func(genre string) ([]data.Show, error)
+	getShowsFunc               func() ([]data.Show, error)
+	getShowsMatchingFunc       func(filter shows.Filter) ([]data.Show, error)
+	markShowWatchedFunc        func(id, count int) (bool, error)
+	markShowWatchedByIndexFunc func(idx, count int, filter shows.Filter) (bool, error)
+	getFilmsFunc               func() ([]data.Film, error)
+	getGenresFunc              func() ([]shows.GenreCount, error)
+	getUnwatchedMatchingFunc   func(filter shows.Filter) ([]data.Show, error)
+	getProvidersFunc           func() ([]shows.ProviderCount, error)
+	getUnwatchedShowsFunc      func() ([]data.Show, error)
+	startWatchingShowFunc      func(id int) (data.Show, error)
+	getCompletedShowsFunc      func() ([]data.Show, error)
+	getWatchHistoryFunc        func(from, to time.Time) ([]data.WatchEvent, error)
+	undoLastWatchedFunc        func() (data.WatchEvent, error)
+	setShowProgressFunc        func(id, series, episode int) (data.Show, error)
+	addShowFunc                func(show data.Show) (data.Show, error)
+	updateShowFunc             func(id int, show data.Show) (data.Show, error)
+	deleteShowFunc             func(id int) (data.Show, error)
+	getUnwatchedFilmsFunc      func() ([]data.Film, error)
+	getFilteredFilmsFunc       func(filter films.Filter) ([]data.Film, error)
+	addFilmFunc                func(film data.Film) (data.Film, error)
+	updateFilmFunc             func(id int, film data.Film) (data.Film, error)
+	deleteFilmFunc             func(id int) (data.Film, error)
+	setFilmWatchedFunc         func(id int, watched bool) (data.Film, error)
+	getRecommendationsFunc     func(prefs recommend.Preferences, limit int) ([]recommend.Recommendation, error)
+	randomPickFunc             func(filter recommend.Filter, rng *rand.Rand) (recommend.Pick, error)
+	planViewingFunc            func(minutes, limit int) ([]planner.Plan, error)
+	getStatsFunc               func() (stats.Stats, error)
+	searchFunc                 func(query string, limit int) ([]search.Result, error)
+	getSubscriptionsFunc       func() ([]data.Subscription, error)
+	setSubscriptionFunc        func(sub data.Subscription) (data.Subscription, error)
+	deleteSubscriptionFunc     func(provider string) (data.Subscription, error)
+	getSubscriptionReportFunc  func() ([]subscriptions.Unlock, error)
 }
 
 func (m *mockHandler) GetCurrentlyWatchingShows() ([]data.Show, error) {
 	return m.getShowsFunc()
 }
 
-func (m *mockHandler) MarkShowWatched(idx int) (bool, error) {
-	return m.markShowWatchedFunc(idx)
+func (m *mockHandler) GetCurrentlyWatchingShowsMatching(filter shows.Filter) ([]data.Show, error) {
+	return m.getShowsMatchingFunc(filter)
+}
+
+func (m *mockHandler) MarkShowWatched(id, count int) (bool, error) {
+	return m.markShowWatchedFunc(id, count)
+}
+
+func (m *mockHandler) MarkShowWatchedByIndex(idx, count int, filter shows.Filter) (bool, error) {
+	return m.markShowWatchedByIndexFunc(idx, count, filter)
 }
 
 func (m *mockHandler) GetAllFilms() ([]data.Film, error) {
 	return m.getFilmsFunc()
 }
 
-func (m *mockHandler) GetAvailableGenres() ([]string, error) {
+func (m *mockHandler) GetAvailableGenres() ([]shows.GenreCount, error) {
 	return m.getGenresFunc()
 }
 
-func (m *mockHandler) GetUnwatchedShowsByGenre(genre string) ([]data.Show, error) {
-	return m.getUnwatchedByGenreFunc(genre)
+func (m *mockHandler) GetUnwatchedShowsMatching(filter shows.Filter) ([]data.Show, error) {
+	return m.getUnwatchedMatchingFunc(filter)
+}
+
+func (m *mockHandler) GetAvailableProviders() ([]shows.ProviderCount, error) {
+	return m.getProvidersFunc()
+}
+
+func (m *mockHandler) GetUnwatchedShows() ([]data.Show, error) {
+	return m.getUnwatchedShowsFunc()
+}
+
+func (m *mockHandler) StartWatchingShow(id int) (data.Show, error) {
+	return m.startWatchingShowFunc(id)
+}
+
+func (m *mockHandler) GetCompletedShows() ([]data.Show, error) {
+	return m.getCompletedShowsFunc()
+}
+
+func (m *mockHandler) GetWatchHistory(from, to time.Time) ([]data.WatchEvent, error) {
+	return m.getWatchHistoryFunc(from, to)
+}
+
+func (m *mockHandler) UndoLastWatched() (data.WatchEvent, error) {
+	return m.undoLastWatchedFunc()
+}
+
+func (m *mockHandler) SetShowProgress(id, series, episode int) (data.Show, error) {
+	return m.setShowProgressFunc(id, series, episode)
+}
+
+func (m *mockHandler) AddShow(show data.Show) (data.Show, error) {
+	return m.addShowFunc(show)
+}
+
+func (m *mockHandler) UpdateShow(id int, show data.Show) (data.Show, error) {
+	return m.updateShowFunc(id, show)
+}
+
+func (m *mockHandler) DeleteShow(id int) (data.Show, error) {
+	return m.deleteShowFunc(id)
+}
+
+func (m *mockHandler) GetUnwatchedFilms() ([]data.Film, error) {
+	return m.getUnwatchedFilmsFunc()
+}
+
+func (m *mockHandler) GetFilms(filter films.Filter) ([]data.Film, error) {
+	return m.getFilteredFilmsFunc(filter)
+}
+
+func (m *mockHandler) AddFilm(film data.Film) (data.Film, error) {
+	return m.addFilmFunc(film)
+}
+
+func (m *mockHandler) UpdateFilm(id int, film data.Film) (data.Film, error) {
+	return m.updateFilmFunc(id, film)
+}
+
+func (m *mockHandler) DeleteFilm(id int) (data.Film, error) {
+	return m.deleteFilmFunc(id)
+}
+
+func (m *mockHandler) SetFilmWatched(id int, watched bool) (data.Film, error) {
+	return m.setFilmWatchedFunc(id, watched)
+}
+
+func (m *mockHandler) GetRecommendations(prefs recommend.Preferences, limit int) ([]recommend.Recommendation, error) {
+	return m.getRecommendationsFunc(prefs, limit)
+}
+
+func (m *mockHandler) RandomPick(filter recommend.Filter, rng *rand.Rand) (recommend.Pick, error) {
+	return m.randomPickFunc(filter, rng)
+}
+
+func (m *mockHandler) PlanViewing(minutes, limit int) ([]planner.Plan, error) {
+	return m.planViewingFunc(minutes, limit)
+}
+
+func (m *mockHandler) GetStats() (stats.Stats, error) {
+	return m.getStatsFunc()
+}
+
+func (m *mockHandler) Search(query string, limit int) ([]search.Result, error) {
+	return m.searchFunc(query, limit)
+}
+
+func (m *mockHandler) GetSubscriptions() ([]data.Subscription, error) {
+	return m.getSubscriptionsFunc()
+}
+
+func (m *mockHandler) SetSubscription(sub data.Subscription) (data.Subscription, error) {
+	return m.setSubscriptionFunc(sub)
+}
+
+func (m *mockHandler) DeleteSubscription(provider string) (data.Subscription, error) {
+	return m.deleteSubscriptionFunc(provider)
+}
+
+func (m *mockHandler) GetSubscriptionReport() ([]subscriptions.Unlock, error) {
+	return m.getSubscriptionReportFunc()
 }
 
 func TestHandleGetShows(t *testing.T) {
 	tests := []struct {
-		name           string
-		method         string
-		genreParam     string
-		mockShows      []data.Show
-		mockErr        error
-		expectedStatus int
-		expectShowLen  int
+		name                   string
+		method                 string
+		query                  string
+		mockShows              []data.Show
+		mockErr                error
+		expectedStatus         int
+		expectShowLen          int
+		expectedFilter         *shows.Filter
+		expectedWatchingFilter *shows.Filter
 	}{
 		{
 			name:   "successful get shows",
@@ -57,13 +207,13 @@ func TestHandleGetShows(t *testing.T) {
 			mockShows: []data.Show{
 				{
 					Name:     "Breaking Bad",
-					Genre:    "Drama",
+					Genres:   []string{"Drama"},
 					Provider: "Netflix",
 					Episodes: []int{1, 2, 3},
 				},
 				{
 					Name:     "The Crown",
-					Genre:    "Drama",
+					Genres:   []string{"Drama"},
 					Provider: "Netflix",
 					Episodes: []int{1, 2},
 				},
@@ -72,6 +222,19 @@ func TestHandleGetShows(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectShowLen:  2,
 		},
+		{
+			name:   "progress is included",
+			method: http.MethodGet,
+			mockShows: []data.Show{
+				{
+					Name:     "Breaking Bad",
+					Episodes: []int{7, 13},
+					Progress: &data.Progress{Watched: 9, Remaining: 11, Total: 20, PercentComplete: 45, MinutesRemaining: 517, HoursRemaining: 8.6},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectShowLen:  1,
+		},
 		{
 			name:           "empty shows list",
 			method:         http.MethodGet,
@@ -105,19 +268,19 @@ func TestHandleGetShows(t *testing.T) {
 			expectShowLen:  0,
 		},
 		{
-			name:       "successful get shows by genre",
-			method:     http.MethodGet,
-			genreParam: "Drama",
+			name:   "successful get shows by genre",
+			method: http.MethodGet,
+			query:  "?genre=Drama",
 			mockShows: []data.Show{
 				{
 					Name:     "Breaking Bad",
-					Genre:    "Drama",
+					Genres:   []string{"Drama"},
 					Provider: "Netflix",
 					Episodes: []int{1, 2, 3},
 				},
 				{
 					Name:     "The Crown",
-					Genre:    "Drama",
+					Genres:   []string{"Drama"},
 					Provider: "Netflix",
 					Episodes: []int{1, 2},
 				},
@@ -125,15 +288,90 @@ func TestHandleGetShows(t *testing.T) {
 			mockErr:        nil,
 			expectedStatus: http.StatusOK,
 			expectShowLen:  2,
+			expectedFilter: &shows.Filter{Genres: []string{"Drama"}},
+		},
+		{
+			name:   "several genres match any by default",
+			method: http.MethodGet,
+			query:  "?genre=Drama,Comedy",
+			mockShows: []data.Show{
+				{Name: "Breaking Bad", Genres: []string{"Drama"}},
+			},
+			expectedStatus: http.StatusOK,
+			expectShowLen:  1,
+			expectedFilter: &shows.Filter{Genres: []string{"Drama", "Comedy"}},
+		},
+		{
+			name:   "several genres match all",
+			method: http.MethodGet,
+			query:  "?genre=Drama&genre=Crime&match=all",
+			mockShows: []data.Show{
+				{Name: "Breaking Bad", Genres: []string{"Drama", "Crime"}},
+			},
+			expectedStatus: http.StatusOK,
+			expectShowLen:  1,
+			expectedFilter: &shows.Filter{Genres: []string{"Drama", "Crime"}, MatchAll: true},
+		},
+		{
+			name:           "invalid match",
+			method:         http.MethodGet,
+			query:          "?genre=Drama&match=some",
+			expectedStatus: http.StatusBadRequest,
+			expectShowLen:  0,
 		},
 		{
 			name:           "empty shows for genre",
 			method:         http.MethodGet,
-			genreParam:     "Horror",
+			query:          "?genre=Horror",
 			mockShows:      []data.Show{},
 			mockErr:        nil,
 			expectedStatus: http.StatusOK,
 			expectShowLen:  0,
+			expectedFilter: &shows.Filter{Genres: []string{"Horror"}},
+		},
+		{
+			name:   "filter by provider",
+			method: http.MethodGet,
+			query:  "?provider=Netflix,BBC%20iPlayer",
+			mockShows: []data.Show{
+				{Name: "Breaking Bad", Provider: "Netflix"},
+			},
+			expectedStatus: http.StatusOK,
+			expectShowLen:  1,
+			expectedFilter: &shows.Filter{Providers: []string{"Netflix", "BBC iPlayer"}},
+		},
+		{
+			name:   "filter by genre and provider",
+			method: http.MethodGet,
+			query:  "?genre=drama&provider=Netflix",
+			mockShows: []data.Show{
+				{Name: "Breaking Bad", Genres: []string{"drama"}, Provider: "Netflix"},
+			},
+			expectedStatus: http.StatusOK,
+			expectShowLen:  1,
+			expectedFilter: &shows.Filter{Genres: []string{"drama"}, Providers: []string{"Netflix"}},
+		},
+		{
+			name:   "watching on subscribed providers only",
+			method: http.MethodGet,
+			query:  "?subscribed=true",
+			mockShows: []data.Show{
+				{Name: "Breaking Bad", Provider: "Netflix", Progress: &data.Progress{Watched: 1, Remaining: 9, Total: 10, PercentComplete: 10}},
+			},
+			expectedStatus:         http.StatusOK,
+			expectShowLen:          1,
+			expectedWatchingFilter: &shows.Filter{SubscribedOnly: true},
+		},
+		{
+			name:   "unwatched by genre on subscribed providers only",
+			method: http.MethodGet,
+			query:  "?genre=drama&subscribed=true",
+			mockShows: []data.Show{
+				{Name: "Breaking Bad", Genres: []string{"drama"}, Provider: "Netflix"},
+			},
+			expectedStatus: http.StatusOK,
+			expectShowLen:  1,
+			expectedFilter: &shows.Filter{Genres: []string{"drama"}, SubscribedOnly: true},
 		},
 	}
 
@@ -143,17 +381,28 @@ func TestHandleGetShows(t *testing.T) {
 				getShowsFunc: func() ([]data.Show, error) {
 					return tt.mockShows, tt.mockErr
 				},
-				getUnwatchedByGenreFunc: func(genre string) ([]data.Show, error) {
+				getUnwatchedMatchingFunc: func(filter shows.Filter) ([]data.Show, error) {
+					if tt.expectedFilter == nil {
+						t.Fatalf("unexpected call with filter %+v", filter)
+					}
+					if !reflect.DeepEqual(filter, *tt.expectedFilter) {
+						t.Errorf("expected filter %+v, got %+v", *tt.expectedFilter, filter)
+					}
+					return tt.mockShows, tt.mockErr
+				},
+				getShowsMatchingFunc: func(filter shows.Filter) ([]data.Show, error) {
+					if tt.expectedWatchingFilter == nil {
+						t.Fatalf("unexpected call with filter %+v", filter)
+					}
+					if !reflect.DeepEqual(filter, *tt.expectedWatchingFilter) {
+						t.Errorf("expected filter %+v, got %+v", *tt.expectedWatchingFilter, filter)
+					}
 					return tt.mockShows, tt.mockErr
 				},
 			}
 
 			server := NewServerWithHandler(8080, mock)
-			url := "/shows"
-			if tt.genreParam != "" {
-				url = url + fmt.Sprintf("?genre=%s", tt.genreParam)
-			}
-			req := httptest.NewRequest(tt.method, url, nil)
+			req := httptest.NewRequest(tt.method, "/shows"+tt.query, nil)
 			w := httptest.NewRecorder()
 
 			server.handleGetShows(w, req)
@@ -172,6 +421,11 @@ func TestHandleGetShows(t *testing.T) {
 			if len(shows) != tt.expectShowLen {
 				t.Errorf("expected %d shows, got %d", tt.expectShowLen, len(shows))
 			}
+			for i, show := range shows {
+				if !reflect.DeepEqual(show.Progress, tt.mockShows[i].Progress) {
+					t.Errorf("expected progress %+v, got %+v", tt.mockShows[i].Progress, show.Progress)
+				}
+			}
 		})
 	}
 }
@@ -180,16 +434,19 @@ func TestHandleMarkShowWatched(t *testing.T) {
 	tests := []struct {
 		name           string
 		method         string
+		idParam        string
 		indexParam     string
+		countParam     string
 		mockCompleted  bool
 		mockErr        error
 		expectedStatus int
 		expectBody     bool
+		expectedCount  int
 	}{
 		{
 			name:           "successful mark show watched",
 			method:         http.MethodPost,
-			indexParam:     "0",
+			idParam:        "0",
 			mockCompleted:  false,
 			mockErr:        nil,
 			expectedStatus: http.StatusOK,
@@ -198,32 +455,32 @@ func TestHandleMarkShowWatched(t *testing.T) {
 		{
 			name:           "mark show as series complete",
 			method:         http.MethodPost,
-			indexParam:     "1",
+			idParam:        "1",
 			mockCompleted:  true,
 			mockErr:        nil,
 			expectedStatus: http.StatusOK,
 			expectBody:     true,
 		},
 		{
-			name:           "missing index parameter",
+			name:           "missing id parameter",
 			method:         http.MethodPost,
-			indexParam:     "",
+			idParam:        "",
 			mockCompleted:  false,
 			mockErr:        nil,
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:           "invalid index parameter (non-integer)",
+			name:           "invalid id parameter (non-integer)",
 			method:         http.MethodPost,
-			indexParam:     "invalid",
+			idParam:        "invalid",
 			mockCompleted:  false,
 			mockErr:        nil,
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:           "invalid index parameter (negative)",
+			name:           "invalid id parameter (negative)",
 			method:         http.MethodPost,
-			indexParam:     "-1",
+			idParam:        "-1",
 			mockCompleted:  false,
 			mockErr:        nil,
 			expectedStatus: http.StatusOK,
@@ -231,7 +488,7 @@ func TestHandleMarkShowWatched(t *testing.T) {
 		{
 			name:           "invalid method GET",
 			method:         http.MethodGet,
-			indexParam:     "0",
+			idParam:        "0",
 			mockCompleted:  false,
 			mockErr:        nil,
 			expectedStatus: http.StatusMethodNotAllowed,
@@ -239,26 +496,117 @@ func TestHandleMarkShowWatched(t *testing.T) {
 		{
 			name:           "handler error",
 			method:         http.MethodPost,
-			indexParam:     "0",
+			idParam:        "0",
 			mockCompleted:  false,
 			mockErr:        fmt.Errorf("failed to update show"),
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name:           "mark show watched by index",
+			method:         http.MethodPost,
+			indexParam:     "2",
+			mockCompleted:  true,
+			mockErr:        nil,
+			expectedStatus: http.StatusOK,
+			expectBody:     true,
+		},
+		{
+			name:           "invalid index parameter (non-integer)",
+			method:         http.MethodPost,
+			indexParam:     "second",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "index out of range",
+			method:         http.MethodPost,
+			indexParam:     "9",
+			mockErr:        fmt.Errorf("error resolving show: %w", shows.ErrShowNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "index zero",
+			method:         http.MethodPost,
+			indexParam:     "0",
+			mockErr:        fmt.Errorf("error resolving show: %w", shows.ErrShowNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "show not found",
+			method:         http.MethodPost,
+			idParam:        "99",
+			mockCompleted:  false,
+			mockErr:        fmt.Errorf("error updating show: %w", shows.ErrShowNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "mark several episodes watched",
+			method:         http.MethodPost,
+			idParam:        "80",
+			countParam:     "4",
+			mockCompleted:  false,
+			expectedStatus: http.StatusOK,
+			expectBody:     false,
+			expectedCount:  4,
+		},
+		{
+			name:           "mark several episodes watched by index",
+			method:         http.MethodPost,
+			indexParam:     "1",
+			countParam:     "4",
+			mockCompleted:  true,
+			expectedStatus: http.StatusOK,
+			expectBody:     true,
+			expectedCount:  4,
+		},
+		{
+			name:           "invalid count parameter (non-integer)",
+			method:         http.MethodPost,
+			idParam:        "80",
+			countParam:     "four",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid count parameter (zero)",
+			method:         http.MethodPost,
+			idParam:        "80",
+			countParam:     "0",
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			expectedCount := tt.expectedCount
+			if expectedCount == 0 {
+				expectedCount = 1
+			}
+			checkCount := func(count int) {
+				if count != expectedCount {
+					t.Errorf("expected count %d, got %d", expectedCount, count)
+				}
+			}
+
 			mock := &mockHandler{
-				markShowWatchedFunc: func(idx int) (bool, error) {
+				markShowWatchedFunc: func(id, count int) (bool, error) {
+					checkCount(count)
+					return tt.mockCompleted, tt.mockErr
+				},
+				markShowWatchedByIndexFunc: func(idx, count int, filter shows.Filter) (bool, error) {
+					checkCount(count)
 					return tt.mockCompleted, tt.mockErr
 				},
 			}
 
 			server := NewServerWithHandler(8080, mock)
 			url := "/shows/watch"
-			if tt.indexParam != "" {
+			if tt.idParam != "" {
+				url += "?id=" + tt.idParam
+			} else if tt.indexParam != "" {
 				url += "?index=" + tt.indexParam
 			}
+			if tt.countParam != "" {
+				url += "&count=" + tt.countParam
+			}
 
 			req := httptest.NewRequest(tt.method, url, nil)
 			w := httptest.NewRecorder()
@@ -283,170 +631,2153 @@ func TestHandleMarkShowWatched(t *testing.T) {
 	}
 }
 
-func TestHandleGetFilms(t *testing.T) {
+func TestMarkShowWatchedByIndexSkipsFinishedShows(t *testing.T) {
+	series, episode := 1, 4
+	otherSeries, otherEpisode := 2, 1
+
+	// Show A has finished (no progress) but is still in the currently watching list,
+	// so index 1 in GET /shows is Show B and index 2 is Show C
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10}},
+		{ID: 2, Name: "Show B", Episodes: []int{10}, CurrentSeries: &series, CurrentEpisode: &episode},
+		{ID: 3, Name: "Show C", Episodes: []int{10, 10}, CurrentSeries: &otherSeries, CurrentEpisode: &otherEpisode},
+	}
+	server := NewServer(8080, store, normalise.Default())
+
+	req := httptest.NewRequest(http.MethodGet, "/shows", nil)
+	w := httptest.NewRecorder()
+	server.handleGetShows(w, req)
+
+	var listed []data.Show
+	if err := json.NewDecoder(w.Body).Decode(&listed); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(listed) != 2 || listed[1].Name != "Show C" {
+		t.Fatalf("expected Show C at index 2, got %+v", listed)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/shows/watch?index=2", nil)
+	w = httptest.NewRecorder()
+	server.handleMarkShowWatched(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	current, err := store.ReadCurrentShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range current {
+		switch s.Name {
+		case "Show B":
+			if *s.CurrentEpisode != 4 {
+				t.Errorf("expected Show B to stay on episode 4, got %d", *s.CurrentEpisode)
+			}
+		case "Show C":
+			if *s.CurrentEpisode != 2 {
+				t.Errorf("expected Show C to advance to episode 2, got %d", *s.CurrentEpisode)
+			}
+		}
+	}
+}
+
+func TestMarkShowWatchedByIndexInSubscribedList(t *testing.T) {
+	series, episode, otherEpisode := 1, 1, 1
+
+	// Show A is on a provider without a subscription, so index 1 in
+	// GET /shows?subscribed=true is Show B
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Provider: "Disney+", Episodes: []int{10}, CurrentSeries: &series, CurrentEpisode: &episode},
+		{ID: 2, Name: "Show B", Provider: "Netflix", Episodes: []int{10}, CurrentSeries: &series, CurrentEpisode: &otherEpisode},
+	}
+	store.Subscriptions = []data.Subscription{{Provider: "Netflix"}}
+	server := NewServer(8080, store, normalise.Default())
+
+	req := httptest.NewRequest(http.MethodGet, "/shows?subscribed=true", nil)
+	w := httptest.NewRecorder()
+	server.handleGetShows(w, req)
+
+	var listed []data.Show
+	if err := json.NewDecoder(w.Body).Decode(&listed); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Name != "Show B" {
+		t.Fatalf("expected only Show B, got %+v", listed)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/shows/watch?index=1&subscribed=true", nil)
+	w = httptest.NewRecorder()
+	server.handleMarkShowWatched(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	current, err := store.ReadCurrentShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range current {
+		switch s.Name {
+		case "Show A":
+			if *s.CurrentEpisode != 1 {
+				t.Errorf("expected Show A to stay on episode 1, got %d", *s.CurrentEpisode)
+			}
+		case "Show B":
+			if *s.CurrentEpisode != 2 {
+				t.Errorf("expected Show B to advance to episode 2, got %d", *s.CurrentEpisode)
+			}
+		}
+	}
+}
+
+func TestHandleSetShowProgress(t *testing.T) {
 	tests := []struct {
-		name           string
-		method         string
-		mockFilms      []data.Film
-		mockErr        error
-		expectedStatus int
-		expectFilmLen  int
+		name            string
+		method          string
+		idParam         string
+		body            string
+		mockErr         error
+		expectedStatus  int
+		expectedSeries  int
+		expectedEpisode int
 	}{
 		{
-			name:   "successful get films",
-			method: http.MethodGet,
-			mockFilms: []data.Film{
-				{
-					Name:     "Inception",
-					Genre:    "Sci-Fi",
-					Provider: "Netflix",
-				},
-				{
-					Name:     "The Matrix",
-					Genre:    "Sci-Fi",
-					Provider: "Prime Video",
-				},
-			},
-			mockErr:        nil,
-			expectedStatus: http.StatusOK,
-			expectFilmLen:  2,
+			name:            "successful set progress",
+			method:          http.MethodPut,
+			idParam:         "80",
+			body:            `{"series": 3, "episode": 5}`,
+			expectedStatus:  http.StatusOK,
+			expectedSeries:  3,
+			expectedEpisode: 5,
 		},
 		{
-			name:           "empty films list",
-			method:         http.MethodGet,
-			mockFilms:      []data.Film{},
-			mockErr:        nil,
-			expectedStatus: http.StatusOK,
-			expectFilmLen:  0,
+			name:           "invalid id parameter (non-integer)",
+			method:         http.MethodPut,
+			idParam:        "invalid",
+			body:           `{"series": 3, "episode": 5}`,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:           "handler error",
-			method:         http.MethodGet,
-			mockFilms:      nil,
-			mockErr:        fmt.Errorf("database error"),
-			expectedStatus: http.StatusInternalServerError,
-			expectFilmLen:  0,
+			name:           "invalid body",
+			method:         http.MethodPut,
+			idParam:        "80",
+			body:           `not json`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "out of range position",
+			method:          http.MethodPut,
+			idParam:         "80",
+			body:            `{"series": 9, "episode": 1}`,
+			mockErr:         fmt.Errorf("SetShowProgress: %w", shows.ErrInvalidProgress),
+			expectedStatus:  http.StatusBadRequest,
+			expectedSeries:  9,
+			expectedEpisode: 1,
+		},
+		{
+			name:            "show not found",
+			method:          http.MethodPut,
+			idParam:         "99",
+			body:            `{"series": 1, "episode": 1}`,
+			mockErr:         fmt.Errorf("SetShowProgress: %w", shows.ErrShowNotFound),
+			expectedStatus:  http.StatusNotFound,
+			expectedSeries:  1,
+			expectedEpisode: 1,
 		},
 		{
 			name:           "invalid method POST",
 			method:         http.MethodPost,
-			mockFilms:      []data.Film{},
-			mockErr:        nil,
+			idParam:        "80",
+			body:           `{"series": 3, "episode": 5}`,
 			expectedStatus: http.StatusMethodNotAllowed,
-			expectFilmLen:  0,
 		},
-		{
-			name:           "invalid method PUT",
-			method:         http.MethodPut,
-			mockFilms:      []data.Film{},
-			mockErr:        nil,
-			expectedStatus: http.StatusMethodNotAllowed,
-			expectFilmLen:  0,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				setShowProgressFunc: func(id, series, episode int) (data.Show, error) {
+					if series != tt.expectedSeries || episode != tt.expectedEpisode {
+						t.Errorf("expected S%dE%d, got S%dE%d", tt.expectedSeries, tt.expectedEpisode, series, episode)
+					}
+					return data.Show{ID: id, CurrentSeries: &series, CurrentEpisode: &episode}, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/shows/"+tt.idParam+"/progress", bytes.NewBufferString(tt.body))
+			req.SetPathValue("id", tt.idParam)
+			w := httptest.NewRecorder()
+
+			server.handleSetShowProgress(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if w.Code == http.StatusOK {
+				var show data.Show
+				if err := json.NewDecoder(w.Body).Decode(&show); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if show.CurrentSeries == nil || *show.CurrentSeries != tt.expectedSeries {
+					t.Errorf("expected series %d, got %v", tt.expectedSeries, show.CurrentSeries)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	var called string
+	mock := &mockHandler{
+		setShowProgressFunc: func(id, series, episode int) (data.Show, error) {
+			called = fmt.Sprintf("progress %d", id)
+			return data.Show{ID: id}, nil
+		},
+		updateShowFunc: func(id int, show data.Show) (data.Show, error) {
+			called = fmt.Sprintf("update %d", id)
+			return show, nil
+		},
+		deleteShowFunc: func(id int) (data.Show, error) {
+			called = fmt.Sprintf("delete %d", id)
+			return data.Show{ID: id}, nil
+		},
+		addFilmFunc: func(film data.Film) (data.Film, error) {
+			called = "add film"
+			return film, nil
+		},
+		setFilmWatchedFunc: func(id int, watched bool) (data.Film, error) {
+			called = fmt.Sprintf("film %d watched %v", id, watched)
+			return data.Film{ID: id}, nil
+		},
+		setSubscriptionFunc: func(sub data.Subscription) (data.Subscription, error) {
+			called = "subscribe " + sub.Provider
+			return sub, nil
+		},
+		deleteSubscriptionFunc: func(provider string) (data.Subscription, error) {
+			called = "unsubscribe " + provider
+			return data.Subscription{Provider: provider}, nil
+		},
+		getSubscriptionReportFunc: func() ([]subscriptions.Unlock, error) {
+			called = "subscription report"
+			return nil, nil
+		},
+	}
+
+	// registering every route on a fresh mux panics if any patterns conflict
+	mux := http.NewServeMux()
+	NewServerWithHandler(8080, mock).registerRoutes(mux)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+		expectedCall   string
+	}{
+		{
+			name:           "progress",
+			method:         http.MethodPut,
+			url:            "/shows/80/progress",
+			body:           `{"series": 1, "episode": 2}`,
+			expectedStatus: http.StatusOK,
+			expectedCall:   "progress 80",
+		},
+		{
+			name:           "update catalogue show",
+			method:         http.MethodPut,
+			url:            "/shows/catalogue/3",
+			body:           `{"name": "Show A", "genre": "drama", "provider": "Netflix", "episodes": [6]}`,
+			expectedStatus: http.StatusOK,
+			expectedCall:   "update 3",
+		},
+		{
+			name:           "delete catalogue show",
+			method:         http.MethodDelete,
+			url:            "/shows/catalogue/3",
+			expectedStatus: http.StatusOK,
+			expectedCall:   "delete 3",
+		},
+		{
+			name:           "add film",
+			method:         http.MethodPost,
+			url:            "/films",
+			body:           `{"name": "Heat", "genre": "crime", "provider": "Netflix"}`,
+			expectedStatus: http.StatusCreated,
+			expectedCall:   "add film",
+		},
+		{
+			name:           "unmark film watched",
+			method:         http.MethodDelete,
+			url:            "/films/7/watched",
+			expectedStatus: http.StatusOK,
+			expectedCall:   "film 7 watched false",
+		},
+		{
+			name:           "add subscription",
+			method:         http.MethodPost,
+			url:            "/subscriptions",
+			body:           `{"provider": "Netflix"}`,
+			expectedStatus: http.StatusOK,
+			expectedCall:   "subscribe Netflix",
+		},
+		{
+			name:           "delete subscription",
+			method:         http.MethodDelete,
+			url:            "/subscriptions/Disney+",
+			expectedStatus: http.StatusOK,
+			expectedCall:   "unsubscribe Disney+",
+		},
+		{
+			name:           "subscription report",
+			method:         http.MethodGet,
+			url:            "/subscriptions/report",
+			expectedStatus: http.StatusOK,
+			expectedCall:   "subscription report",
+		},
+		{
+			name:           "unknown show action",
+			method:         http.MethodPut,
+			url:            "/shows/80/rating",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = ""
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if called != tt.expectedCall {
+				t.Errorf("expected call %q, got %q", tt.expectedCall, called)
+			}
+		})
+	}
+}
+
+func TestHandleAddShow(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		mockErr        error
+		expectedStatus int
+		expectID       int
+	}{
+		{
+			name:           "successful add show",
+			method:         http.MethodPost,
+			body:           `{"name": "Severance", "genre": "thriller", "provider": "Apple TV+", "episodes": [9, 10]}`,
+			expectedStatus: http.StatusCreated,
+			expectID:       87,
+		},
+		{
+			name:           "invalid body",
+			method:         http.MethodPost,
+			body:           `{"name": `,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "validation error",
+			method:         http.MethodPost,
+			body:           `{"name": "Severance"}`,
+			mockErr:        fmt.Errorf("AddShow: %w", shows.ErrInvalidShow),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodPost,
+			body:           `{"name": "Severance", "genre": "thriller", "provider": "Apple TV+", "episodes": [9]}`,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid method GET",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				addShowFunc: func(show data.Show) (data.Show, error) {
+					show.ID = tt.expectID
+					return show, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/shows/catalogue", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			server.handleAddShow(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if w.Code == http.StatusCreated {
+				var show data.Show
+				if err := json.NewDecoder(w.Body).Decode(&show); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if show.ID != tt.expectID {
+					t.Errorf("expected id %d, got %d", tt.expectID, show.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleCatalogueShow(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		idParam        string
+		body           string
+		mockErr        error
+		expectedStatus int
+	}{
+		{
+			name:           "successful update",
+			method:         http.MethodPut,
+			idParam:        "3",
+			body:           `{"name": "Severance", "genre": "thriller", "provider": "Apple TV+", "episodes": [9, 10]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "update with invalid body",
+			method:         http.MethodPut,
+			idParam:        "3",
+			body:           `[]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "update validation error",
+			method:         http.MethodPut,
+			idParam:        "3",
+			body:           `{"name": "Severance", "episodes": [0]}`,
+			mockErr:        fmt.Errorf("UpdateShow: %w", shows.ErrInvalidShow),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "update unknown show",
+			method:         http.MethodPut,
+			idParam:        "99",
+			body:           `{"name": "Severance", "genre": "thriller", "provider": "Apple TV+", "episodes": [9]}`,
+			mockErr:        fmt.Errorf("UpdateShow: %w", shows.ErrShowNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "successful delete",
+			method:         http.MethodDelete,
+			idParam:        "3",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "delete unknown show",
+			method:         http.MethodDelete,
+			idParam:        "99",
+			mockErr:        fmt.Errorf("DeleteShow: %w", shows.ErrShowNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id parameter (non-integer)",
+			method:         http.MethodDelete,
+			idParam:        "invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			idParam:        "3",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				updateShowFunc: func(id int, show data.Show) (data.Show, error) {
+					show.ID = id
+					return show, tt.mockErr
+				},
+				deleteShowFunc: func(id int) (data.Show, error) {
+					return data.Show{ID: id}, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/shows/catalogue/"+tt.idParam, bytes.NewBufferString(tt.body))
+			req.SetPathValue("id", tt.idParam)
+			w := httptest.NewRecorder()
+
+			server.handleCatalogueShow(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleUndoLastWatched(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		mockEvent      data.WatchEvent
+		mockErr        error
+		expectedStatus int
+		expectShow     string
+	}{
+		{
+			name:           "successful undo",
+			method:         http.MethodPost,
+			mockEvent:      data.WatchEvent{ShowID: 80, Show: "Breaking Bad", Series: 1, Episode: 2},
+			expectedStatus: http.StatusOK,
+			expectShow:     "Breaking Bad",
+		},
+		{
+			name:           "nothing to undo",
+			method:         http.MethodPost,
+			mockErr:        fmt.Errorf("UndoLastWatched: %w", history.ErrNothingToUndo),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "show no longer exists",
+			method:         http.MethodPost,
+			mockErr:        fmt.Errorf("UndoLastWatched: %w", shows.ErrShowNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodPost,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid method GET",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				undoLastWatchedFunc: func() (data.WatchEvent, error) {
+					return tt.mockEvent, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/shows/undo", nil)
+			w := httptest.NewRecorder()
+
+			server.handleUndoLastWatched(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if w.Code == http.StatusOK {
+				var event data.WatchEvent
+				if err := json.NewDecoder(w.Body).Decode(&event); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if event.Show != tt.expectShow {
+					t.Errorf("expected show %q, got %q", tt.expectShow, event.Show)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGetUnwatchedShows(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		mockShows      []data.Show
+		mockErr        error
+		expectedStatus int
+		expectShowLen  int
+	}{
+		{
+			name:   "successful get unwatched shows",
+			method: http.MethodGet,
+			mockShows: []data.Show{
+				{Name: "Suits", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{12, 16}},
+			},
+			mockErr:        nil,
+			expectedStatus: http.StatusOK,
+			expectShowLen:  1,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockShows:      nil,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectShowLen:  0,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			mockShows:      []data.Show{},
+			mockErr:        nil,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectShowLen:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				getUnwatchedShowsFunc: func() ([]data.Show, error) {
+					return tt.mockShows, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/shows/unwatched", nil)
+			w := httptest.NewRecorder()
+
+			server.handleGetUnwatchedShows(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			body, _ := io.ReadAll(w.Body)
+			var shows []data.Show
+			if err := json.Unmarshal(body, &shows); err != nil {
+				if tt.expectShowLen > 0 {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+			}
+			if len(shows) != tt.expectShowLen {
+				t.Errorf("expected %d shows, got %d", tt.expectShowLen, len(shows))
+			}
+		})
+	}
+}
+
+func TestHandleStartWatchingShow(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		idParam        string
+		mockShow       data.Show
+		mockErr        error
+		expectedStatus int
+		expectName     string
+	}{
+		{
+			name:           "successful start watching show",
+			method:         http.MethodPost,
+			idParam:        "1",
+			mockShow:       data.Show{Name: "Suits", Episodes: []int{12}},
+			mockErr:        nil,
+			expectedStatus: http.StatusOK,
+			expectName:     "Suits",
+		},
+		{
+			name:           "missing id parameter",
+			method:         http.MethodPost,
+			idParam:        "",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid id parameter (non-integer)",
+			method:         http.MethodPost,
+			idParam:        "invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid method GET",
+			method:         http.MethodGet,
+			idParam:        "1",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodPost,
+			idParam:        "1",
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "show not found",
+			method:         http.MethodPost,
+			idParam:        "99",
+			mockErr:        fmt.Errorf("StartWatchingShow: %w", shows.ErrShowNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "show already started",
+			method:         http.MethodPost,
+			idParam:        "1",
+			mockErr:        fmt.Errorf("StartWatchingShow: %w", shows.ErrAlreadyStarted),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "show has no episodes",
+			method:         http.MethodPost,
+			idParam:        "1",
+			mockErr:        fmt.Errorf("StartWatchingShow: %w", shows.ErrInvalidShow),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				startWatchingShowFunc: func(id int) (data.Show, error) {
+					return tt.mockShow, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			url := "/shows/start"
+			if tt.idParam != "" {
+				url += "?id=" + tt.idParam
+			}
+
+			req := httptest.NewRequest(tt.method, url, nil)
+			w := httptest.NewRecorder()
+
+			server.handleStartWatchingShow(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if w.Code == http.StatusOK {
+				var show data.Show
+				if err := json.NewDecoder(w.Body).Decode(&show); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if show.Name != tt.expectName {
+					t.Errorf("expected show %q, got %q", tt.expectName, show.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGetCompletedShows(t *testing.T) {
+	completedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		mockShows      []data.Show
+		mockErr        error
+		expectedStatus int
+		expectShowLen  int
+	}{
+		{
+			name:   "successful get completed shows",
+			method: http.MethodGet,
+			mockShows: []data.Show{
+				{Name: "Breaking Bad", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{7, 13}, CompletedAt: &completedAt},
+			},
+			mockErr:        nil,
+			expectedStatus: http.StatusOK,
+			expectShowLen:  1,
+		},
+		{
+			name:           "no completed shows",
+			method:         http.MethodGet,
+			mockShows:      nil,
+			mockErr:        nil,
+			expectedStatus: http.StatusOK,
+			expectShowLen:  0,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockShows:      nil,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectShowLen:  0,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			mockShows:      nil,
+			mockErr:        nil,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectShowLen:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				getCompletedShowsFunc: func() ([]data.Show, error) {
+					return tt.mockShows, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/shows/completed", nil)
+			w := httptest.NewRecorder()
+
+			server.handleGetCompletedShows(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			body, _ := io.ReadAll(w.Body)
+			var shows []data.Show
+			if err := json.Unmarshal(body, &shows); err != nil {
+				if tt.expectShowLen > 0 {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+			}
+			if len(shows) != tt.expectShowLen {
+				t.Errorf("expected %d shows, got %d", tt.expectShowLen, len(shows))
+			}
+			if tt.expectShowLen > 0 && (shows[0].CompletedAt == nil || !shows[0].CompletedAt.Equal(completedAt)) {
+				t.Errorf("expected completedAt %v, got %v", completedAt, shows[0].CompletedAt)
+			}
+		})
+	}
+}
+
+func TestHandleGetWatchHistory(t *testing.T) {
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		query          string
+		mockEvents     []data.WatchEvent
+		mockErr        error
+		expectedStatus int
+		expectedFrom   time.Time
+		expectedTo     time.Time
+		expectEventLen int
+	}{
+		{
+			name:   "successful get full history",
+			method: http.MethodGet,
+			query:  "",
+			mockEvents: []data.WatchEvent{
+				{ShowID: 80, Show: "Breaking Bad", Series: 1, Episode: 2, WatchedAt: watchedAt},
+			},
+			expectedStatus: http.StatusOK,
+			expectEventLen: 1,
+		},
+		{
+			name:           "date range is inclusive of the to date",
+			method:         http.MethodGet,
+			query:          "?from=2025-11-01&to=2025-11-30",
+			mockEvents:     nil,
+			expectedStatus: http.StatusOK,
+			expectedFrom:   time.Date(2025, 11, 1, 0, 0, 0, 0, time.Local),
+			expectedTo:     time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local),
+			expectEventLen: 0,
+		},
+		{
+			name:           "invalid from date",
+			method:         http.MethodGet,
+			query:          "?from=01/11/2025",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "from after to",
+			method:         http.MethodGet,
+			query:          "?from=2025-11-02&to=2025-11-01",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				getWatchHistoryFunc: func(from, to time.Time) ([]data.WatchEvent, error) {
+					if !from.Equal(tt.expectedFrom) || !to.Equal(tt.expectedTo) {
+						t.Errorf("expected range %v to %v, got %v to %v", tt.expectedFrom, tt.expectedTo, from, to)
+					}
+					return tt.mockEvents, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/history"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			server.handleGetWatchHistory(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			body, _ := io.ReadAll(w.Body)
+			var events []data.WatchEvent
+			if err := json.Unmarshal(body, &events); err != nil {
+				if tt.expectEventLen > 0 {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+			}
+			if len(events) != tt.expectEventLen {
+				t.Errorf("expected %d events, got %d", tt.expectEventLen, len(events))
+			}
+		})
+	}
+}
+
+func TestHandleGetFilms(t *testing.T) {
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		query          string
+		mockFilms      []data.Film
+		mockErr        error
+		expectedStatus int
+		expectFilmLen  int
+		expectedFilter *films.Filter
+	}{
+		{
+			name:   "successful get films",
+			method: http.MethodGet,
+			mockFilms: []data.Film{
+				{
+					Name:     "Inception",
+					Genres:   []string{"Sci-Fi"},
+					Provider: "Netflix",
+				},
+				{
+					Name:     "The Matrix",
+					Genres:   []string{"Sci-Fi"},
+					Provider: "Prime Video",
+				},
+			},
+			mockErr:        nil,
+			expectedStatus: http.StatusOK,
+			expectFilmLen:  2,
+		},
+		{
+			name:   "watched films are hidden by default",
+			method: http.MethodGet,
+			mockFilms: []data.Film{
+				{Name: "Inception", Genres: []string{"Sci-Fi"}, Provider: "Netflix", WatchedAt: &watchedAt},
+				{Name: "The Matrix", Genres: []string{"Sci-Fi"}, Provider: "Prime Video"},
+			},
+			expectedStatus: http.StatusOK,
+			expectFilmLen:  1,
+		},
+		{
+			name:   "all includes watched films",
+			method: http.MethodGet,
+			query:  "?all=true",
+			mockFilms: []data.Film{
+				{Name: "Inception", Genres: []string{"Sci-Fi"}, Provider: "Netflix", WatchedAt: &watchedAt},
+				{Name: "The Matrix", Genres: []string{"Sci-Fi"}, Provider: "Prime Video"},
+			},
+			expectedStatus: http.StatusOK,
+			expectFilmLen:  2,
+		},
+		{
+			name:   "filter by genre and provider",
+			method: http.MethodGet,
+			query:  "?genre=sci-fi,drama&provider=Netflix",
+			mockFilms: []data.Film{
+				{Name: "Inception", Genres: []string{"Sci-Fi"}, Provider: "Netflix"},
+				{Name: "The Matrix", Genres: []string{"Sci-Fi"}, Provider: "Prime Video"},
+			},
+			expectedStatus: http.StatusOK,
+			expectFilmLen:  1,
+			expectedFilter: &films.Filter{Genres: []string{"sci-fi", "drama"}, Providers: []string{"Netflix"}},
+		},
+		{
+			name:   "filter by all genres including watched films",
+			method: http.MethodGet,
+			query:  "?genre=sci-fi&genre=thriller&match=all&all=true",
+			mockFilms: []data.Film{
+				{Name: "Inception", Genres: []string{"Sci-Fi", "Thriller"}, Provider: "Netflix", WatchedAt: &watchedAt},
+				{Name: "The Matrix", Genres: []string{"Sci-Fi"}, Provider: "Prime Video"},
+			},
+			expectedStatus: http.StatusOK,
+			expectFilmLen:  1,
+			expectedFilter: &films.Filter{Genres: []string{"sci-fi", "thriller"}, MatchAll: true, IncludeWatched: true},
+		},
+		{
+			name:   "subscribed providers only",
+			method: http.MethodGet,
+			query:  "?genre=sci-fi&subscribed=true",
+			mockFilms: []data.Film{
+				{Name: "Inception", Genres: []string{"Sci-Fi"}, Provider: "Netflix"},
+			},
+			expectedStatus: http.StatusOK,
+			expectFilmLen:  1,
+			expectedFilter: &films.Filter{Genres: []string{"sci-fi"}, SubscribedOnly: true},
+		},
+		{
+			name:           "invalid match",
+			method:         http.MethodGet,
+			query:          "?genre=drama&match=most",
+			expectedStatus: http.StatusBadRequest,
+			expectFilmLen:  0,
+		},
+		{
+			name:           "empty films list",
+			method:         http.MethodGet,
+			mockFilms:      []data.Film{},
+			mockErr:        nil,
+			expectedStatus: http.StatusOK,
+			expectFilmLen:  0,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockFilms:      nil,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectFilmLen:  0,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			mockFilms:      []data.Film{},
+			mockErr:        nil,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectFilmLen:  0,
+		},
+		{
+			name:           "invalid method PUT",
+			method:         http.MethodPut,
+			mockFilms:      []data.Film{},
+			mockErr:        nil,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectFilmLen:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				getFilmsFunc: func() ([]data.Film, error) {
+					return tt.mockFilms, tt.mockErr
+				},
+				getUnwatchedFilmsFunc: func() ([]data.Film, error) {
+					var unwatched []data.Film
+					for _, f := range tt.mockFilms {
+						if f.WatchedAt == nil {
+							unwatched = append(unwatched, f)
+						}
+					}
+					return unwatched, tt.mockErr
+				},
+				getFilteredFilmsFunc: func(filter films.Filter) ([]data.Film, error) {
+					if tt.expectedFilter == nil {
+						t.Fatalf("unexpected call with filter %+v", filter)
+					}
+					if !reflect.DeepEqual(filter, *tt.expectedFilter) {
+						t.Errorf("expected filter %+v, got %+v", *tt.expectedFilter, filter)
+					}
+					return films.FilterFilms(tt.mockFilms, filter), tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/films"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			server.handleGetFilms(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			body, _ := io.ReadAll(w.Body)
+			var films []data.Film
+			if err := json.Unmarshal(body, &films); err != nil {
+				if tt.expectFilmLen > 0 {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+			}
+			if len(films) != tt.expectFilmLen {
+				t.Errorf("expected %d films, got %d", tt.expectFilmLen, len(films))
+			}
+		})
+	}
+}
+
+func TestHandleAddFilm(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		mockErr        error
+		expectedStatus int
+	}{
+		{
+			name:           "successful add film",
+			method:         http.MethodPost,
+			body:           `{"name": "Heat", "genre": "crime", "provider": "Netflix"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid body",
+			method:         http.MethodPost,
+			body:           `{"name": `,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "validation error",
+			method:         http.MethodPost,
+			body:           `{"name": "Heat"}`,
+			mockErr:        fmt.Errorf("AddFilm: %w", films.ErrInvalidFilm),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid method GET",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				addFilmFunc: func(film data.Film) (data.Film, error) {
+					film.ID = 21
+					return film, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/films", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			server.handleAddFilm(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleFilm(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		idParam        string
+		body           string
+		mockErr        error
+		expectedStatus int
+	}{
+		{
+			name:           "successful update",
+			method:         http.MethodPut,
+			idParam:        "3",
+			body:           `{"name": "Heat", "genre": "crime", "provider": "Netflix"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "update with invalid body",
+			method:         http.MethodPut,
+			idParam:        "3",
+			body:           `[]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "update validation error",
+			method:         http.MethodPut,
+			idParam:        "3",
+			body:           `{"name": ""}`,
+			mockErr:        fmt.Errorf("UpdateFilm: %w", films.ErrInvalidFilm),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "successful delete",
+			method:         http.MethodDelete,
+			idParam:        "3",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "delete unknown film",
+			method:         http.MethodDelete,
+			idParam:        "99",
+			mockErr:        fmt.Errorf("DeleteFilm: %w", films.ErrFilmNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id parameter (non-integer)",
+			method:         http.MethodPut,
+			idParam:        "invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid method GET",
+			method:         http.MethodGet,
+			idParam:        "3",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				updateFilmFunc: func(id int, film data.Film) (data.Film, error) {
+					film.ID = id
+					return film, tt.mockErr
+				},
+				deleteFilmFunc: func(id int) (data.Film, error) {
+					return data.Film{ID: id}, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/films/"+tt.idParam, bytes.NewBufferString(tt.body))
+			req.SetPathValue("id", tt.idParam)
+			w := httptest.NewRecorder()
+
+			server.handleFilm(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleSetFilmWatched(t *testing.T) {
+	tests := []struct {
+		name            string
+		method          string
+		idParam         string
+		mockErr         error
+		expectedStatus  int
+		expectedWatched bool
+	}{
+		{
+			name:            "mark watched",
+			method:          http.MethodPost,
+			idParam:         "3",
+			expectedStatus:  http.StatusOK,
+			expectedWatched: true,
+		},
+		{
+			name:            "mark unwatched",
+			method:          http.MethodDelete,
+			idParam:         "3",
+			expectedStatus:  http.StatusOK,
+			expectedWatched: false,
+		},
+		{
+			name:            "unknown film",
+			method:          http.MethodPost,
+			idParam:         "99",
+			mockErr:         fmt.Errorf("SetFilmWatched: %w", films.ErrFilmNotFound),
+			expectedStatus:  http.StatusNotFound,
+			expectedWatched: true,
+		},
+		{
+			name:           "invalid id parameter (non-integer)",
+			method:         http.MethodPost,
+			idParam:        "invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid method PUT",
+			method:         http.MethodPut,
+			idParam:        "3",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				setFilmWatchedFunc: func(id int, watched bool) (data.Film, error) {
+					if watched != tt.expectedWatched {
+						t.Errorf("expected watched %v, got %v", tt.expectedWatched, watched)
+					}
+					return data.Film{ID: id}, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/films/"+tt.idParam+"/watched", nil)
+			req.SetPathValue("id", tt.idParam)
+			w := httptest.NewRecorder()
+
+			server.handleSetFilmWatched(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleGetRecommendations(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		query          string
+		mockErr        error
+		expectedStatus int
+		expectedPrefs  recommend.Preferences
+		expectedLimit  int
+	}{
+		{
+			name:           "defaults",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedLimit:  defaultRecommendations,
+		},
+		{
+			name:           "repeated and comma separated preferences",
+			method:         http.MethodGet,
+			query:          "?genre=Drama&genre=Comedy,%20Sci-Fi&provider=Netflix&limit=3",
+			expectedStatus: http.StatusOK,
+			expectedPrefs: recommend.Preferences{
+				Genres:    []string{"Drama", "Comedy", "Sci-Fi"},
+				Providers: []string{"Netflix"},
+			},
+			expectedLimit: 3,
+		},
+		{
+			name:           "subscribed providers only",
+			method:         http.MethodGet,
+			query:          "?subscribed=true",
+			expectedStatus: http.StatusOK,
+			expectedPrefs:  recommend.Preferences{SubscribedOnly: true},
+			expectedLimit:  defaultRecommendations,
+		},
+		{
+			name:           "invalid limit",
+			method:         http.MethodGet,
+			query:          "?limit=abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "zero limit",
+			method:         http.MethodGet,
+			query:          "?limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedLimit:  defaultRecommendations,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				getRecommendationsFunc: func(prefs recommend.Preferences, limit int) ([]recommend.Recommendation, error) {
+					if !reflect.DeepEqual(prefs, tt.expectedPrefs) {
+						t.Errorf("expected preferences %+v, got %+v", tt.expectedPrefs, prefs)
+					}
+					if limit != tt.expectedLimit {
+						t.Errorf("expected limit %d, got %d", tt.expectedLimit, limit)
+					}
+					return []recommend.Recommendation{{Kind: recommend.KindShow, ID: 1, Name: "Show A"}}, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/recommendations"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			server.handleGetRecommendations(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var recs []recommend.Recommendation
+				if err := json.NewDecoder(w.Body).Decode(&recs); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(recs) != 1 {
+					t.Errorf("expected 1 recommendation, got %d", len(recs))
+				}
+			}
+		})
+	}
+}
+
+func TestHandleRandomPick(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		query          string
+		mockErr        error
+		expectedStatus int
+		expectedFilter recommend.Filter
+		expectSeeded   bool
+	}{
+		{
+			name:           "no filter",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "every filter with a seed",
+			method:         http.MethodGet,
+			query:          "?genre=Drama&provider=Netflix&kind=show&inProgress=true&seed=42",
+			expectedStatus: http.StatusOK,
+			expectedFilter: recommend.Filter{Genre: "Drama", Provider: "Netflix", Kind: recommend.KindShow, InProgressOnly: true},
+			expectSeeded:   true,
+		},
+		{
+			name:           "invalid kind",
+			method:         http.MethodGet,
+			query:          "?kind=book",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid seed",
+			method:         http.MethodGet,
+			query:          "?seed=-1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "nothing matches",
+			method:         http.MethodGet,
+			mockErr:        recommend.ErrNoMatches,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				randomPickFunc: func(filter recommend.Filter, rng *rand.Rand) (recommend.Pick, error) {
+					if filter != tt.expectedFilter {
+						t.Errorf("expected filter %+v, got %+v", tt.expectedFilter, filter)
+					}
+					if (rng != nil) != tt.expectSeeded {
+						t.Errorf("expected seeded %v, got rng %v", tt.expectSeeded, rng)
+					}
+					return recommend.Pick{Kind: recommend.KindShow, ID: 1, Name: "Show A"}, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/random"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			server.handleRandomPick(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var pick recommend.Pick
+				if err := json.NewDecoder(w.Body).Decode(&pick); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if pick.Name != "Show A" {
+					t.Errorf("expected Show A, got %+v", pick)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlePlanViewing(t *testing.T) {
+	tests := []struct {
+		name            string
+		method          string
+		query           string
+		mockErr         error
+		expectedStatus  int
+		expectedMinutes int
+		expectedLimit   int
+	}{
+		{
+			name:            "default limit",
+			method:          http.MethodGet,
+			query:           "?minutes=90",
+			expectedStatus:  http.StatusOK,
+			expectedMinutes: 90,
+			expectedLimit:   defaultPlans,
+		},
+		{
+			name:            "with limit",
+			method:          http.MethodGet,
+			query:           "?minutes=45&limit=2",
+			expectedStatus:  http.StatusOK,
+			expectedMinutes: 45,
+			expectedLimit:   2,
+		},
+		{
+			name:           "missing minutes",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid minutes",
+			method:         http.MethodGet,
+			query:          "?minutes=-30",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid limit",
+			method:         http.MethodGet,
+			query:          "?minutes=90&limit=none",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "handler error",
+			method:          http.MethodGet,
+			query:           "?minutes=90",
+			mockErr:         fmt.Errorf("database error"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMinutes: 90,
+			expectedLimit:   defaultPlans,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			query:          "?minutes=90",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				planViewingFunc: func(minutes, limit int) ([]planner.Plan, error) {
+					if minutes != tt.expectedMinutes || limit != tt.expectedLimit {
+						t.Errorf("expected %d minutes and limit %d, got %d and %d", tt.expectedMinutes, tt.expectedLimit, minutes, limit)
+					}
+					return []planner.Plan{{Items: []planner.Item{{Kind: recommend.KindFilm, ID: 1, Name: "Film A", Minutes: 80}}, Minutes: 80, Spare: 10}}, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/plan"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			server.handlePlanViewing(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var plans []planner.Plan
+				if err := json.NewDecoder(w.Body).Decode(&plans); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(plans) != 1 || plans[0].Spare != 10 {
+					t.Errorf("unexpected plans %+v", plans)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGetStats(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		mockStats      stats.Stats
+		mockErr        error
+		expectedStatus int
+	}{
+		{
+			name:   "successful get stats",
+			method: http.MethodGet,
+			mockStats: stats.Stats{
+				EpisodesWatched: 12,
+				FavouriteGenres: []stats.Count{{Name: "Drama", Count: 12}},
+				CurrentStreak:   3,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				getStatsFunc: func() (stats.Stats, error) {
+					return tt.mockStats, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/stats", nil)
+			w := httptest.NewRecorder()
+
+			server.handleGetStats(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var st stats.Stats
+				if err := json.NewDecoder(w.Body).Decode(&st); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if !reflect.DeepEqual(st, tt.mockStats) {
+					t.Errorf("expected %+v, got %+v", tt.mockStats, st)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGetGenres(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		mockGenres     []shows.GenreCount
+		mockErr        error
+		expectedStatus int
+		expectGenreLen int
+	}{
+		{
+			name:   "successful get genres",
+			method: http.MethodGet,
+			mockGenres: []shows.GenreCount{
+				{Genre: "comedy", Unwatched: 3, Watching: 1, Films: 2},
+				{Genre: "drama", Unwatched: 1},
+				{Genre: "science fiction", Films: 1},
+			},
+			mockErr:        nil,
+			expectedStatus: http.StatusOK,
+			expectGenreLen: 3,
+		},
+		{
+			name:           "empty genres list",
+			method:         http.MethodGet,
+			mockGenres:     []shows.GenreCount{},
+			mockErr:        nil,
+			expectedStatus: http.StatusOK,
+			expectGenreLen: 0,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockGenres:     nil,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectGenreLen: 0,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			mockGenres:     []shows.GenreCount{},
+			mockErr:        nil,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectGenreLen: 0,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &mockHandler{
-				getFilmsFunc: func() ([]data.Film, error) {
-					return tt.mockFilms, tt.mockErr
+				getGenresFunc: func() ([]shows.GenreCount, error) {
+					return tt.mockGenres, tt.mockErr
 				},
 			}
 
 			server := NewServerWithHandler(8080, mock)
-			req := httptest.NewRequest(tt.method, "/films", nil)
+			req := httptest.NewRequest(tt.method, "/genres", nil)
 			w := httptest.NewRecorder()
 
-			server.handleGetFilms(w, req)
+			server.handleGetGenres(w, req)
 
 			if w.Code != tt.expectedStatus {
 				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
 			}
 
 			body, _ := io.ReadAll(w.Body)
-			var films []data.Film
-			if err := json.Unmarshal(body, &films); err != nil {
-				if tt.expectFilmLen > 0 {
+			var genres []shows.GenreCount
+			if err := json.Unmarshal(body, &genres); err != nil {
+				if tt.expectGenreLen > 0 {
 					t.Fatalf("failed to unmarshal response: %v", err)
 				}
 			}
-			if len(films) != tt.expectFilmLen {
-				t.Errorf("expected %d films, got %d", tt.expectFilmLen, len(films))
+			if len(genres) != tt.expectGenreLen {
+				t.Errorf("expected %d genres, got %d", tt.expectGenreLen, len(genres))
+			}
+			if tt.expectGenreLen > 0 && !reflect.DeepEqual(genres, tt.mockGenres) {
+				t.Errorf("expected %+v, got %+v", tt.mockGenres, genres)
 			}
 		})
 	}
 }
 
-func TestHandleGetGenres(t *testing.T) {
+func TestHandleGetProviders(t *testing.T) {
+	tests := []struct {
+		name              string
+		method            string
+		mockProviders     []shows.ProviderCount
+		mockErr           error
+		expectedStatus    int
+		expectProviderLen int
+	}{
+		{
+			name:   "successful get providers",
+			method: http.MethodGet,
+			mockProviders: []shows.ProviderCount{
+				{Provider: "BBC iPlayer", Unwatched: 21, Films: 1},
+				{Provider: "Netflix", Unwatched: 23, Watching: 3, Films: 10},
+			},
+			mockErr:           nil,
+			expectedStatus:    http.StatusOK,
+			expectProviderLen: 2,
+		},
+		{
+			name:              "empty providers list",
+			method:            http.MethodGet,
+			mockProviders:     []shows.ProviderCount{},
+			mockErr:           nil,
+			expectedStatus:    http.StatusOK,
+			expectProviderLen: 0,
+		},
+		{
+			name:              "handler error",
+			method:            http.MethodGet,
+			mockProviders:     nil,
+			mockErr:           fmt.Errorf("database error"),
+			expectedStatus:    http.StatusInternalServerError,
+			expectProviderLen: 0,
+		},
+		{
+			name:              "invalid method POST",
+			method:            http.MethodPost,
+			mockProviders:     []shows.ProviderCount{},
+			mockErr:           nil,
+			expectedStatus:    http.StatusMethodNotAllowed,
+			expectProviderLen: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				getProvidersFunc: func() ([]shows.ProviderCount, error) {
+					return tt.mockProviders, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/providers", nil)
+			w := httptest.NewRecorder()
+
+			server.handleGetProviders(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			body, _ := io.ReadAll(w.Body)
+			var providers []shows.ProviderCount
+			if err := json.Unmarshal(body, &providers); err != nil {
+				if tt.expectProviderLen > 0 {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+			}
+			if len(providers) != tt.expectProviderLen {
+				t.Errorf("expected %d providers, got %d", tt.expectProviderLen, len(providers))
+			}
+			if tt.expectProviderLen > 0 && !reflect.DeepEqual(providers, tt.mockProviders) {
+				t.Errorf("expected %+v, got %+v", tt.mockProviders, providers)
+			}
+		})
+	}
+}
+
+func TestHandleSearch(t *testing.T) {
 	tests := []struct {
 		name           string
 		method         string
-		mockGenres     []string
+		query          string
 		mockErr        error
 		expectedStatus int
-		expectGenreLen int
+		expectedQuery  string
+		expectedLimit  int
 	}{
 		{
-			name:           "successful get genres",
+			name:           "default limit",
 			method:         http.MethodGet,
-			mockGenres:     []string{"Drama", "Comedy", "Sci-Fi"},
-			mockErr:        nil,
+			query:          "?q=the%20crwon",
 			expectedStatus: http.StatusOK,
-			expectGenreLen: 3,
+			expectedQuery:  "the crwon",
+			expectedLimit:  defaultSearchResults,
 		},
 		{
-			name:           "empty genres list",
+			name:           "with a limit",
 			method:         http.MethodGet,
-			mockGenres:     []string{},
-			mockErr:        nil,
+			query:          "?q=drama&limit=3",
 			expectedStatus: http.StatusOK,
-			expectGenreLen: 0,
+			expectedQuery:  "drama",
+			expectedLimit:  3,
+		},
+		{
+			name:           "missing query",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "blank query",
+			method:         http.MethodGet,
+			query:          "?q=%20%20",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid limit",
+			method:         http.MethodGet,
+			query:          "?q=drama&limit=0",
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name:           "handler error",
 			method:         http.MethodGet,
-			mockGenres:     nil,
+			query:          "?q=drama",
 			mockErr:        fmt.Errorf("database error"),
 			expectedStatus: http.StatusInternalServerError,
-			expectGenreLen: 0,
+			expectedQuery:  "drama",
+			expectedLimit:  defaultSearchResults,
 		},
 		{
 			name:           "invalid method POST",
 			method:         http.MethodPost,
-			mockGenres:     []string{},
-			mockErr:        nil,
+			query:          "?q=drama",
 			expectedStatus: http.StatusMethodNotAllowed,
-			expectGenreLen: 0,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &mockHandler{
-				getGenresFunc: func() ([]string, error) {
-					return tt.mockGenres, tt.mockErr
+				searchFunc: func(query string, limit int) ([]search.Result, error) {
+					if query != tt.expectedQuery {
+						t.Errorf("expected query %q, got %q", tt.expectedQuery, query)
+					}
+					if limit != tt.expectedLimit {
+						t.Errorf("expected limit %d, got %d", tt.expectedLimit, limit)
+					}
+					return []search.Result{{Kind: search.KindShow, ID: 2, Name: "The Crown", Match: search.FieldName, Score: 0.7}}, tt.mockErr
 				},
 			}
 
 			server := NewServerWithHandler(8080, mock)
-			req := httptest.NewRequest(tt.method, "/genres", nil)
+			req := httptest.NewRequest(tt.method, "/search"+tt.query, nil)
 			w := httptest.NewRecorder()
 
-			server.handleGetGenres(w, req)
+			server.handleSearch(w, req)
 
 			if w.Code != tt.expectedStatus {
 				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
 			}
 
-			body, _ := io.ReadAll(w.Body)
-			var genres []string
-			if err := json.Unmarshal(body, &genres); err != nil {
-				if tt.expectGenreLen > 0 {
-					t.Fatalf("failed to unmarshal response: %v", err)
+			if tt.expectedStatus == http.StatusOK {
+				var results []search.Result
+				if err := json.NewDecoder(w.Body).Decode(&results); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(results) != 1 {
+					t.Errorf("expected 1 result, got %d", len(results))
 				}
 			}
-			if len(genres) != tt.expectGenreLen {
-				t.Errorf("expected %d genres, got %d", tt.expectGenreLen, len(genres))
+		})
+	}
+}
+
+func TestHandleGetSubscriptions(t *testing.T) {
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		mockSubs       []data.Subscription
+		mockErr        error
+		expectedStatus int
+	}{
+		{
+			name:           "successful get subscriptions",
+			method:         http.MethodGet,
+			mockSubs:       []data.Subscription{{Provider: "Netflix"}, {Provider: "Disney+", End: &end}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid method PUT",
+			method:         http.MethodPut,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				getSubscriptionsFunc: func() ([]data.Subscription, error) {
+					return tt.mockSubs, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/subscriptions", nil)
+			w := httptest.NewRecorder()
+
+			server.handleGetSubscriptions(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var subs []data.Subscription
+				if err := json.NewDecoder(w.Body).Decode(&subs); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if !reflect.DeepEqual(subs, tt.mockSubs) {
+					t.Errorf("expected %+v, got %+v", tt.mockSubs, subs)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSetSubscription(t *testing.T) {
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockErr        error
+		expectedStatus int
+		expectedSub    data.Subscription
+	}{
+		{
+			name:           "provider only",
+			body:           `{"provider": "Netflix"}`,
+			expectedStatus: http.StatusOK,
+			expectedSub:    data.Subscription{Provider: "Netflix"},
+		},
+		{
+			name:           "with a start date",
+			body:           `{"provider": "Disney+", "start": "2025-11-01T00:00:00Z"}`,
+			expectedStatus: http.StatusOK,
+			expectedSub:    data.Subscription{Provider: "Disney+", Start: &start},
+		},
+		{
+			name:           "invalid body",
+			body:           `{"provider": `,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "validation error",
+			body:           `{"provider": ""}`,
+			mockErr:        fmt.Errorf("SetSubscription: %w", subscriptions.ErrInvalidSubscription),
+			expectedStatus: http.StatusBadRequest,
+			expectedSub:    data.Subscription{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				setSubscriptionFunc: func(sub data.Subscription) (data.Subscription, error) {
+					if !reflect.DeepEqual(sub, tt.expectedSub) {
+						t.Errorf("expected subscription %+v, got %+v", tt.expectedSub, sub)
+					}
+					return sub, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(http.MethodPost, "/subscriptions", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			server.handleSetSubscription(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleDeleteSubscription(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		provider       string
+		mockErr        error
+		expectedStatus int
+	}{
+		{
+			name:           "successful delete",
+			method:         http.MethodDelete,
+			provider:       "Netflix",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown provider",
+			method:         http.MethodDelete,
+			provider:       "NOW",
+			mockErr:        fmt.Errorf("DeleteSubscription: %w", subscriptions.ErrSubscriptionNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid method GET",
+			method:         http.MethodGet,
+			provider:       "Netflix",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				deleteSubscriptionFunc: func(provider string) (data.Subscription, error) {
+					if provider != tt.provider {
+						t.Errorf("expected provider %q, got %q", tt.provider, provider)
+					}
+					return data.Subscription{Provider: provider}, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/subscriptions/"+tt.provider, nil)
+			req.SetPathValue("provider", tt.provider)
+			w := httptest.NewRecorder()
+
+			server.handleDeleteSubscription(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleGetSubscriptionReport(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		mockReport     []subscriptions.Unlock
+		mockErr        error
+		expectedStatus int
+	}{
+		{
+			name:   "successful report",
+			method: http.MethodGet,
+			mockReport: []subscriptions.Unlock{
+				{Provider: "Disney+", Unwatched: 12, Films: 3, Total: 15},
+				{Provider: "Netflix", Active: true, Unwatched: 4, Watching: 1, Total: 5},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "handler error",
+			method:         http.MethodGet,
+			mockErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid method POST",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHandler{
+				getSubscriptionReportFunc: func() ([]subscriptions.Unlock, error) {
+					return tt.mockReport, tt.mockErr
+				},
+			}
+
+			server := NewServerWithHandler(8080, mock)
+			req := httptest.NewRequest(tt.method, "/subscriptions/report", nil)
+			w := httptest.NewRecorder()
+
+			server.handleGetSubscriptionReport(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var report []subscriptions.Unlock
+				if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if !reflect.DeepEqual(report, tt.mockReport) {
+					t.Errorf("expected %+v, got %+v", tt.mockReport, report)
+				}
 			}
 		})
 	}
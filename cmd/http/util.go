@@ -2,8 +2,19 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"what-to-watch/films"
+	"what-to-watch/history"
+	"what-to-watch/planner"
+	"what-to-watch/recommend"
+	"what-to-watch/shows"
+	"what-to-watch/subscriptions"
 )
 
 func writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -20,3 +31,149 @@ func writeError(w http.ResponseWriter, statusCode int, err error) {
 func writeMethodError(w http.ResponseWriter, allowedMethod string) {
 	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("only %s is allowed", allowedMethod))
 }
+
+// writeHandlerError writes an error returned by the handlers, mapping known
+// error kinds to the matching status code
+func writeHandlerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, shows.ErrShowNotFound), errors.Is(err, films.ErrFilmNotFound), errors.Is(err, recommend.ErrNoMatches),
+		errors.Is(err, subscriptions.ErrSubscriptionNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, shows.ErrInvalidProgress), errors.Is(err, shows.ErrInvalidShow), errors.Is(err, films.ErrInvalidFilm),
+		errors.Is(err, planner.ErrInvalidBudget), errors.Is(err, subscriptions.ErrInvalidSubscription):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, history.ErrNothingToUndo), errors.Is(err, shows.ErrAlreadyStarted):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// queryID parses the required id query parameter
+func queryID(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("id")
+	if raw == "" {
+		return 0, fmt.Errorf("id query parameter is required")
+	}
+
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("id must be a valid integer")
+	}
+
+	return id, nil
+}
+
+// queryCount parses the optional count query parameter, defaulting to 1
+func queryCount(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("count")
+	if raw == "" {
+		return 1, nil
+	}
+
+	count, err := strconv.Atoi(raw)
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("count must be a positive integer")
+	}
+
+	return count, nil
+}
+
+// queryMinutes parses the required minutes query parameter
+func queryMinutes(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("minutes")
+	if raw == "" {
+		return 0, fmt.Errorf("minutes query parameter is required")
+	}
+
+	minutes, err := strconv.Atoi(raw)
+	if err != nil || minutes < 1 {
+		return 0, fmt.Errorf("minutes must be a positive integer")
+	}
+
+	return minutes, nil
+}
+
+// pathID parses the required id path value
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, fmt.Errorf("id must be a valid integer")
+	}
+
+	return id, nil
+}
+
+// queryList returns every value of a query parameter that may be repeated or comma separated,
+// e.g. ?genre=drama&genre=comedy or ?genre=drama,comedy
+func queryList(r *http.Request, name string) []string {
+	var values []string
+	for _, raw := range r.URL.Query()[name] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// queryMatchAll parses the optional match query parameter, which is "any" (the default) or "all"
+func queryMatchAll(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("match") {
+	case "", "any":
+		return false, nil
+	case "all":
+		return true, nil
+	default:
+		return false, fmt.Errorf("match must be \"any\" or \"all\"")
+	}
+}
+
+// queryLimit parses the optional limit query parameter, returning def when it is absent
+func queryLimit(r *http.Request, def int) (int, error) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return def, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+
+	return limit, nil
+}
+
+// queryFilter parses the optional genre, provider, kind and inProgress query parameters of a random pick
+func queryFilter(r *http.Request) (recommend.Filter, error) {
+	q := r.URL.Query()
+	filter := recommend.Filter{
+		Genre:          q.Get("genre"),
+		Provider:       q.Get("provider"),
+		Kind:           recommend.Kind(q.Get("kind")),
+		InProgressOnly: q.Get("inProgress") == "true",
+	}
+
+	if filter.Kind != "" && filter.Kind != recommend.KindShow && filter.Kind != recommend.KindFilm {
+		return recommend.Filter{}, fmt.Errorf("kind must be %q or %q", recommend.KindShow, recommend.KindFilm)
+	}
+
+	return filter, nil
+}
+
+// querySeed parses the optional seed query parameter into a seeded random source,
+// returning nil when no seed is given
+func querySeed(r *http.Request) (*rand.Rand, error) {
+	raw := r.URL.Query().Get("seed")
+	if raw == "" {
+		return nil, nil
+	}
+
+	seed, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("seed must be a non-negative integer")
+	}
+
+	return rand.New(rand.NewPCG(seed, seed)), nil
+}
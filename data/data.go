@@ -1,20 +1,127 @@
 package data
 
+import (
+	"encoding/json"
+	"time"
+
+	"what-to-watch/genres"
+)
+
 type Show struct {
-	Name     string `json:"name"`
-	Genre    string `json:"genre"`
-	Episodes []int  `json:"episodes"`
-	Provider string `json:"provider"`
+	// ID is unique across the catalogue, currently watching and completed shows
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Genres   []string `json:"genres"`
+	Episodes []int    `json:"episodes"`
+	Provider string   `json:"provider"`
+	// Runtime is the typical length of an episode in minutes, or 0 if unknown
+	Runtime int `json:"runtime,omitempty"`
+	// SeriesRuntimes optionally overrides Runtime for each series, in the same order as Episodes.
+	// A missing or 0 entry falls back to Runtime
+	SeriesRuntimes []int `json:"seriesRuntimes,omitempty"`
 	// CurrentSeries is only set if the user is currently watching this show
 	CurrentSeries *int   `json:"currentSeries,omitempty"`
 	Series        string `json:"-"`
 	// CurrentEpisode is only set if the user is currently watching this show
 	CurrentEpisode *int   `json:"currentEpisode,omitempty"`
 	Episode        string `json:"-"`
+	// CompletedAt is only set once the user has finished watching this show
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	// Progress is only set on shows returned as currently watching, and is never stored
+	Progress *Progress `json:"progress,omitempty"`
+}
+
+// UnmarshalJSON decodes a show, also accepting the single "genre" string written before shows
+// could have several genres. A comma separated genre string becomes one genre per value.
+func (s *Show) UnmarshalJSON(raw []byte) error {
+	type plain Show
+	aux := struct {
+		*plain
+		Genre string `json:"genre"`
+	}{plain: (*plain)(s)}
+
+	if err := json.Unmarshal(raw, &aux); err != nil {
+		return err
+	}
+	if len(s.Genres) == 0 {
+		s.Genres = genres.Split(aux.Genre)
+	}
+
+	return nil
+}
+
+// Progress describes how far through a show the user is.
+type Progress struct {
+	Watched         int     `json:"watched"`
+	Remaining       int     `json:"remaining"`
+	Total           int     `json:"total"`
+	PercentComplete float64 `json:"percentComplete"`
+	// MinutesRemaining and HoursRemaining are only set when every remaining episode has a runtime
+	MinutesRemaining int     `json:"minutesRemaining,omitempty"`
+	HoursRemaining   float64 `json:"hoursRemaining,omitempty"`
 }
 
 type Film struct {
-	Name     string `json:"name"`
-	Genre    string `json:"genre"`
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Genres   []string `json:"genres"`
+	Provider string   `json:"provider"`
+	// Runtime is the length of the film in minutes, or 0 if unknown
+	Runtime int `json:"runtime,omitempty"`
+	// WatchedAt is only set once the user has watched this film
+	WatchedAt *time.Time `json:"watchedAt,omitempty"`
+}
+
+// UnmarshalJSON decodes a film, also accepting the single "genre" string written before films
+// could have several genres. A comma separated genre string becomes one genre per value.
+func (f *Film) UnmarshalJSON(raw []byte) error {
+	type plain Film
+	aux := struct {
+		*plain
+		Genre string `json:"genre"`
+	}{plain: (*plain)(f)}
+
+	if err := json.Unmarshal(raw, &aux); err != nil {
+		return err
+	}
+	if len(f.Genres) == 0 {
+		f.Genres = genres.Split(aux.Genre)
+	}
+
+	return nil
+}
+
+// EventKind distinguishes the entries in the watch history.
+type EventKind string
+
+const (
+	// EventWatched records an episode being marked as watched. It is empty so that history
+	// recorded before there were other kinds of event reads back unchanged.
+	EventWatched EventKind = ""
+	// EventJump records a show being moved straight to another episode. The series and episode
+	// are where the show was before the jump, so undoing it puts the show back there.
+	EventJump EventKind = "jump"
+	// EventUndo records the latest event not already undone being undone. The show, series
+	// and episode are those of the event undone.
+	EventUndo EventKind = "undo"
+)
+
+// WatchEvent records a single episode of a show being marked as watched, or another change
+// to the watch history given by Kind. The history is only ever appended to.
+type WatchEvent struct {
+	ShowID    int       `json:"showId"`
+	Show      string    `json:"show"`
+	Series    int       `json:"series"`
+	Episode   int       `json:"episode"`
+	WatchedAt time.Time `json:"watchedAt"`
+	Kind      EventKind `json:"kind,omitempty"`
+}
+
+// Subscription records a streaming provider the user currently pays for.
+type Subscription struct {
 	Provider string `json:"provider"`
+	// Start is only set if the subscription begins at a known time; it is active from then on
+	Start *time.Time `json:"start,omitempty"`
+	// End is only set if the subscription has been cancelled; it is no longer active from then on
+	End *time.Time `json:"end,omitempty"`
 }
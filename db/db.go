@@ -1,133 +1,162 @@
 package db
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"runtime"
 
 	"what-to-watch/data"
 )
 
-// ReadShows reads the shows from the shows.json file and returns a slice of Show structs.
-func ReadShows() ([]data.Show, error) {
-	raw, err := readFile("shows.json")
-	if err != nil {
-		return nil, fmt.Errorf("ReadShows: error reading file \n err=%w", err)
-	}
+// Store defines the persistence operations used by the handlers.
+// Implementations must be safe to use from multiple goroutines.
+type Store interface {
+	// ReadShows returns the catalogue of shows.
+	ReadShows() ([]data.Show, error)
+	// WriteShows replaces the catalogue of shows.
+	WriteShows(shows []data.Show) error
+	// ReadCurrentShows returns the shows currently being watched.
+	ReadCurrentShows() ([]data.Show, error)
+	// WriteCurrentShows replaces the shows currently being watched.
+	WriteCurrentShows(shows []data.Show) error
+	// ReadCompletedShows returns the shows that have been watched to the end.
+	ReadCompletedShows() ([]data.Show, error)
+	// WriteCompletedShows replaces the shows that have been watched to the end.
+	WriteCompletedShows(shows []data.Show) error
+	// LastShowID returns the highest show ID ever stored in any list, including shows since deleted.
+	LastShowID() (int, error)
+	// ReadFilms returns the films.
+	ReadFilms() ([]data.Film, error)
+	// WriteFilms replaces the films.
+	WriteFilms(films []data.Film) error
+	// LastFilmID returns the highest film ID ever stored, including films since deleted.
+	LastFilmID() (int, error)
+	// ReadWatchHistory returns every recorded watch event, oldest first.
+	ReadWatchHistory() ([]data.WatchEvent, error)
+	// AppendWatchEvent adds an event to the end of the watch history.
+	AppendWatchEvent(event data.WatchEvent) error
+	// ReadSubscriptions returns the streaming subscriptions.
+	ReadSubscriptions() ([]data.Subscription, error)
+	// WriteSubscriptions replaces the streaming subscriptions.
+	WriteSubscriptions(subs []data.Subscription) error
+}
 
-	var shows []data.Show
-	if err := json.Unmarshal(raw, &shows); err != nil {
-		return nil, err
-	}
+// DataDirEnv is the environment variable that overrides the default data directory.
+const DataDirEnv = "WHAT_TO_WATCH_DATA"
 
-	return shows, nil
-}
+// dataFiles lists the JSON files seeded with an empty list on first run.
+var dataFiles = []string{"shows.json", "currentShows.json", "completedShows.json", "films.json", "subscriptions.json"}
 
-// ReadCurrentShows reads the shows from the currentShows.json file and returns a slice of Show structs.
-func ReadCurrentShows() ([]data.Show, error) {
-	raw, err := readFile("currentShows.json")
-	if err != nil {
-		return nil, fmt.Errorf("ReadCurrentShows: error reading file \n err=%w", err)
+// ResolveDataDir determines the directory holding the data files.
+// An explicit dir (from the -data-dir flag) takes precedence, followed by the
+// WHAT_TO_WATCH_DATA environment variable, then $XDG_DATA_HOME/what-to-watch
+// and finally ~/.local/share/what-to-watch.
+func ResolveDataDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
 	}
 
-	var shows []data.Show
-	if err := json.Unmarshal(raw, &shows); err != nil {
-		return nil, err
+	if env := os.Getenv(DataDirEnv); env != "" {
+		return env, nil
 	}
 
-	return shows, nil
-}
-
-// ReadFilms reads the films from the films.json file and returns a slice of Film structs.
-func ReadFilms() ([]data.Film, error) {
-	raw, err := readFile("films.json")
-	if err != nil {
-		return nil, fmt.Errorf("ReadFilms: error reading file \n err=%w", err)
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "what-to-watch"), nil
 	}
 
-	var films []data.Film
-	if err := json.Unmarshal(raw, &films); err != nil {
-		return nil, err
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("ResolveDataDir: could not determine home directory \n err=%w", err)
 	}
 
-	return films, nil
+	return filepath.Join(home, ".local", "share", "what-to-watch"), nil
 }
 
-// WriteCurrentShows writes the provided shows slice to the currentShows.json file.
-// It writes to a temporary file in the same directory and renames it
-// to avoid corrupting the file on failure.
-func WriteCurrentShows(shows []data.Show) error {
-	raw, err := json.MarshalIndent(shows, "", "  ")
-	if err != nil {
-		return err
+// InitDataDir creates the data directory if needed and seeds any missing
+// data files with an empty list so a first run starts from a clean slate.
+// Existing files are never modified.
+func InitDataDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("InitDataDir: error creating directory \n err=%w dir=%s", err, dir)
 	}
 
-	fullPath := getFullPath("currentShows.json")
-	if fullPath == "" {
-		return fmt.Errorf("WriteCurrentShows: could not determine full path to currentShows.json")
-	}
-
-	// create temp file in same directory to ensure atomic rename
-	dir := filepath.Dir(fullPath)
-	tmpFile, err := os.CreateTemp(dir, "currentShows-*.json.tmp")
-	if err != nil {
-		return fmt.Errorf("WriteCurrentShows: error creating temp file \n err=%w fullPath=%s", err, fullPath)
-	}
-	tmpPath := tmpFile.Name()
-	defer os.Remove(tmpPath)
-
-	// write to temp file
-	if _, err := tmpFile.Write(raw); err != nil {
-		tmpFile.Close()
-		return fmt.Errorf("WriteCurrentShows: error writing temp file \n err=%w fullPath=%s tmpPath=%s", err, fullPath, tmpPath)
-	}
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("WriteCurrentShows: error closing temp file \n err=%w fullPath=%s tmpPath=%s", err, fullPath, tmpPath)
-	}
+	for _, name := range dataFiles {
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("InitDataDir: error creating file \n err=%w path=%s", err, path)
+		}
 
-	// rename temp file to final file
-	if err := os.Rename(tmpPath, fullPath); err != nil {
-		return fmt.Errorf("WriteCurrentShows: error renaming temp file \n err=%w fullPath=%s tmpPath=%s", err, fullPath, tmpPath)
+		if _, err := f.WriteString("[]\n"); err != nil {
+			f.Close()
+			return fmt.Errorf("InitDataDir: error seeding file \n err=%w path=%s", err, path)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("InitDataDir: error closing file \n err=%w path=%s", err, path)
+		}
 	}
 
 	return nil
 }
 
-// getFullPath attempts to determine the full path to the given file.
-func getFullPath(path string) (fullPath string) {
-	// Try to get path relative to executable first (for built binaries)
-	exePath, err := os.Executable()
-	if err == nil {
-		exeDir := filepath.Dir(exePath)
-		candidatePath := filepath.Join(exeDir, path)
-		if _, err := os.Stat(candidatePath); err == nil {
-			fullPath = candidatePath
+// assignShowIDs gives every show without an ID the next free ID above last, the highest
+// show ID ever stored, keeping IDs unique across all of the given lists. The lists are
+// updated in place. It reports whether any ID was assigned.
+func assignShowIDs(last int, lists ...[]data.Show) bool {
+	next := max(last, maxShowID(lists...)) + 1
+
+	assigned := false
+	for _, list := range lists {
+		for i := range list {
+			if list[i].ID == 0 {
+				list[i].ID = next
+				next++
+				assigned = true
+			}
 		}
 	}
 
-	// Fall back to source directory (for go run during development)
-	if fullPath == "" {
-		_, currentFile, _, _ := runtime.Caller(0)
-		sourceDir := filepath.Dir(currentFile)
-		fullPath = filepath.Join(sourceDir, path)
+	return assigned
+}
+
+// assignFilmIDs gives every film without an ID the next free ID above last, the highest
+// film ID ever stored. The films are updated in place. It reports whether any ID was assigned.
+func assignFilmIDs(last int, films []data.Film) bool {
+	next := max(last, maxFilmID(films)) + 1
+
+	assigned := false
+	for i := range films {
+		if films[i].ID == 0 {
+			films[i].ID = next
+			next++
+			assigned = true
+		}
 	}
 
-	return
+	return assigned
 }
 
-// readFile reads the contents of the file at the given path.
-func readFile(path string) ([]byte, error) {
-	fullPath := getFullPath(path)
-	if fullPath == "" {
-		return nil, fmt.Errorf("readFile: could not determine full path to %s", path)
+// maxShowID returns the highest ID of the shows in the given lists, or 0 if there are none.
+func maxShowID(lists ...[]data.Show) int {
+	highest := 0
+	for _, list := range lists {
+		for _, s := range list {
+			highest = max(highest, s.ID)
+		}
 	}
+	return highest
+}
 
-	raw, err := os.ReadFile(fullPath)
-	if err != nil {
-		return nil, fmt.Errorf("ReadFilms: error reading file \n err=%w path=%s fullPath=%s", err, path, fullPath)
+// maxFilmID returns the highest ID of the given films, or 0 if there are none.
+func maxFilmID(films []data.Film) int {
+	highest := 0
+	for _, f := range films {
+		highest = max(highest, f.ID)
 	}
-
-	return raw, nil
+	return highest
 }
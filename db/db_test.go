@@ -0,0 +1,95 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveDataDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		env      string
+		xdg      string
+		home     string
+		expected string
+	}{
+		{
+			name:     "flag takes precedence",
+			flag:     "/flag",
+			env:      "/env",
+			xdg:      "/xdg",
+			home:     "/home/user",
+			expected: "/flag",
+		},
+		{
+			name:     "environment variable",
+			env:      "/env",
+			xdg:      "/xdg",
+			home:     "/home/user",
+			expected: "/env",
+		},
+		{
+			name:     "XDG data home",
+			xdg:      "/xdg",
+			home:     "/home/user",
+			expected: filepath.Join("/xdg", "what-to-watch"),
+		},
+		{
+			name:     "home directory default",
+			home:     "/home/user",
+			expected: filepath.Join("/home/user", ".local", "share", "what-to-watch"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DataDirEnv, tt.env)
+			t.Setenv("XDG_DATA_HOME", tt.xdg)
+			t.Setenv("HOME", tt.home)
+
+			result, err := ResolveDataDir(tt.flag)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestInitDataDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "what-to-watch")
+
+	if err := InitDataDir(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	store := NewJSONStore(dir)
+	shows, err := store.ReadShows()
+	if err != nil {
+		t.Fatalf("unexpected error reading seeded shows: %v", err)
+	}
+	if len(shows) != 0 {
+		t.Errorf("expected no shows, got %+v", shows)
+	}
+
+	// existing files must not be overwritten
+	custom := []byte(`[{"name": "Film A", "genre": "war", "provider": "Netflix"}]`)
+	if err := os.WriteFile(filepath.Join(dir, "films.json"), custom, 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := InitDataDir(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	films, err := store.ReadFilms()
+	if err != nil {
+		t.Fatalf("unexpected error reading films: %v", err)
+	}
+	if len(films) != 1 {
+		t.Errorf("expected existing films.json to be kept, got %+v", films)
+	}
+}
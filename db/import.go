@@ -0,0 +1,77 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ImportJSON copies every show list, the films, the watch history and the subscriptions from the JSON files
+// in dir into the SQLite store, replacing whatever the SQLite store held before. It is intended as a
+// one-shot migration from the JSON files to SQLite.
+func ImportJSON(dst *SQLiteStore, dir string) error {
+	src := NewJSONStore(dir)
+
+	catalogue, err := src.ReadShows()
+	if err != nil {
+		return fmt.Errorf("ImportJSON: %w", err)
+	}
+	current, err := src.ReadCurrentShows()
+	if err != nil {
+		return fmt.Errorf("ImportJSON: %w", err)
+	}
+	completed, err := src.ReadCompletedShows()
+	if err != nil {
+		return fmt.Errorf("ImportJSON: %w", err)
+	}
+	films, err := src.ReadFilms()
+	if err != nil {
+		return fmt.Errorf("ImportJSON: %w", err)
+	}
+	lastShowID, err := src.LastShowID()
+	if err != nil {
+		return fmt.Errorf("ImportJSON: %w", err)
+	}
+	lastFilmID, err := src.LastFilmID()
+	if err != nil {
+		return fmt.Errorf("ImportJSON: %w", err)
+	}
+	history, err := src.ReadWatchHistory()
+	if err != nil {
+		return fmt.Errorf("ImportJSON: %w", err)
+	}
+	subs, err := src.ReadSubscriptions()
+	if err != nil {
+		return fmt.Errorf("ImportJSON: %w", err)
+	}
+
+	// everything is replaced in a single transaction so a failed import leaves the store as it was
+	err = dst.inTx(func(tx *sql.Tx) error {
+		if err := replaceShows(tx, listCatalogue, catalogue); err != nil {
+			return err
+		}
+		if err := replaceShows(tx, listCurrent, current); err != nil {
+			return err
+		}
+		if err := replaceShows(tx, listCompleted, completed); err != nil {
+			return err
+		}
+		if err := raiseLastID(tx, lastIDShows, lastShowID); err != nil {
+			return err
+		}
+		if err := replaceFilms(tx, films); err != nil {
+			return err
+		}
+		if err := raiseLastID(tx, lastIDFilms, lastFilmID); err != nil {
+			return err
+		}
+		if err := replaceWatchHistory(tx, history); err != nil {
+			return err
+		}
+		return replaceSubscriptions(tx, subs)
+	})
+	if err != nil {
+		return fmt.Errorf("ImportJSON: %w", err)
+	}
+
+	return nil
+}
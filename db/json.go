@@ -0,0 +1,434 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"what-to-watch/data"
+)
+
+var _ Store = (*JSONStore)(nil)
+
+// JSONStore is a Store backed by JSON files in a single directory.
+type JSONStore struct {
+	dir string
+	mu  sync.Mutex
+
+	// idsMu guards idsAssigned, which records that legacy data without IDs has been migrated
+	idsMu       sync.Mutex
+	idsAssigned bool
+
+	// lastIDsMu guards updates to lastIDs.json
+	lastIDsMu sync.Mutex
+}
+
+// lastIDs is the content of lastIDs.json: the highest ID ever stored of each kind,
+// so that the ID of something deleted is never handed out again.
+type lastIDs struct {
+	Shows int `json:"shows"`
+	Films int `json:"films"`
+}
+
+// NewJSONStore creates a JSONStore that reads and writes files in dir.
+func NewJSONStore(dir string) *JSONStore {
+	return &JSONStore{dir: dir}
+}
+
+// ReadShows reads the shows from the shows.json file and returns a slice of Show structs.
+func (s *JSONStore) ReadShows() ([]data.Show, error) {
+	if err := s.ensureIDs(); err != nil {
+		return nil, fmt.Errorf("ReadShows: %w", err)
+	}
+
+	var shows []data.Show
+	if err := s.readFile("shows.json", &shows); err != nil {
+		return nil, fmt.Errorf("ReadShows: error reading file \n err=%w", err)
+	}
+
+	return shows, nil
+}
+
+// WriteShows writes the provided shows slice to the shows.json file.
+func (s *JSONStore) WriteShows(shows []data.Show) error {
+	if err := s.raiseLastIDs(lastIDs{Shows: maxShowID(shows)}); err != nil {
+		return fmt.Errorf("WriteShows: %w", err)
+	}
+
+	if err := s.writeFile("shows.json", shows); err != nil {
+		return fmt.Errorf("WriteShows: %w", err)
+	}
+
+	return nil
+}
+
+// ReadCurrentShows reads the shows from the currentShows.json file and returns a slice of Show structs.
+func (s *JSONStore) ReadCurrentShows() ([]data.Show, error) {
+	if err := s.ensureIDs(); err != nil {
+		return nil, fmt.Errorf("ReadCurrentShows: %w", err)
+	}
+
+	var shows []data.Show
+	if err := s.readFile("currentShows.json", &shows); err != nil {
+		return nil, fmt.Errorf("ReadCurrentShows: error reading file \n err=%w", err)
+	}
+
+	return shows, nil
+}
+
+// WriteCurrentShows writes the provided shows slice to the currentShows.json file.
+func (s *JSONStore) WriteCurrentShows(shows []data.Show) error {
+	if err := s.raiseLastIDs(lastIDs{Shows: maxShowID(shows)}); err != nil {
+		return fmt.Errorf("WriteCurrentShows: %w", err)
+	}
+
+	if err := s.writeFile("currentShows.json", shows); err != nil {
+		return fmt.Errorf("WriteCurrentShows: %w", err)
+	}
+
+	return nil
+}
+
+// ReadCompletedShows reads the shows from the completedShows.json file and returns a slice of Show structs.
+// A missing file is treated as no completed shows.
+func (s *JSONStore) ReadCompletedShows() ([]data.Show, error) {
+	if err := s.ensureIDs(); err != nil {
+		return nil, fmt.Errorf("ReadCompletedShows: %w", err)
+	}
+
+	var shows []data.Show
+	err := s.readFile("completedShows.json", &shows)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ReadCompletedShows: error reading file \n err=%w", err)
+	}
+
+	return shows, nil
+}
+
+// WriteCompletedShows writes the provided shows slice to the completedShows.json file.
+func (s *JSONStore) WriteCompletedShows(shows []data.Show) error {
+	if err := s.raiseLastIDs(lastIDs{Shows: maxShowID(shows)}); err != nil {
+		return fmt.Errorf("WriteCompletedShows: %w", err)
+	}
+
+	if err := s.writeFile("completedShows.json", shows); err != nil {
+		return fmt.Errorf("WriteCompletedShows: %w", err)
+	}
+
+	return nil
+}
+
+// ReadFilms reads the films from the films.json file and returns a slice of Film structs.
+func (s *JSONStore) ReadFilms() ([]data.Film, error) {
+	if err := s.ensureIDs(); err != nil {
+		return nil, fmt.Errorf("ReadFilms: %w", err)
+	}
+
+	var films []data.Film
+	if err := s.readFile("films.json", &films); err != nil {
+		return nil, fmt.Errorf("ReadFilms: error reading file \n err=%w", err)
+	}
+
+	return films, nil
+}
+
+// WriteFilms writes the provided films slice to the films.json file.
+func (s *JSONStore) WriteFilms(films []data.Film) error {
+	// recorded first, so a failed write can leave a gap in the IDs but never a reused one
+	if err := s.raiseLastIDs(lastIDs{Films: maxFilmID(films)}); err != nil {
+		return fmt.Errorf("WriteFilms: %w", err)
+	}
+
+	if err := s.writeFile("films.json", films); err != nil {
+		return fmt.Errorf("WriteFilms: %w", err)
+	}
+
+	return nil
+}
+
+// LastShowID returns the highest show ID ever stored in any list, from the lastIDs.json file.
+func (s *JSONStore) LastShowID() (int, error) {
+	if err := s.ensureIDs(); err != nil {
+		return 0, fmt.Errorf("LastShowID: %w", err)
+	}
+
+	ids, err := s.readLastIDs()
+	if err != nil {
+		return 0, fmt.Errorf("LastShowID: %w", err)
+	}
+
+	return ids.Shows, nil
+}
+
+// LastFilmID returns the highest film ID ever stored, from the lastIDs.json file.
+func (s *JSONStore) LastFilmID() (int, error) {
+	if err := s.ensureIDs(); err != nil {
+		return 0, fmt.Errorf("LastFilmID: %w", err)
+	}
+
+	ids, err := s.readLastIDs()
+	if err != nil {
+		return 0, fmt.Errorf("LastFilmID: %w", err)
+	}
+
+	return ids.Films, nil
+}
+
+// ReadWatchHistory reads the watch events from the history.jsonl file, which holds one JSON object per line.
+// A missing file is treated as an empty history.
+func (s *JSONStore) ReadWatchHistory() ([]data.WatchEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fullPath := filepath.Join(s.dir, "history.jsonl")
+	f, err := os.Open(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ReadWatchHistory: error opening file \n err=%w fullPath=%s", err, fullPath)
+	}
+	defer f.Close()
+
+	var events []data.WatchEvent
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var event data.WatchEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("ReadWatchHistory: error decoding line \n err=%w fullPath=%s line=%d", err, fullPath, line)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ReadWatchHistory: error reading file \n err=%w fullPath=%s", err, fullPath)
+	}
+
+	return events, nil
+}
+
+// AppendWatchEvent appends the event as a single line to the history.jsonl file,
+// creating the file if needed. Existing lines are never rewritten.
+func (s *JSONStore) AppendWatchEvent(event data.WatchEvent) error {
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("AppendWatchEvent: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fullPath := filepath.Join(s.dir, "history.jsonl")
+	f, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("AppendWatchEvent: error opening file \n err=%w fullPath=%s", err, fullPath)
+	}
+
+	if _, err := f.Write(append(raw, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("AppendWatchEvent: error writing file \n err=%w fullPath=%s", err, fullPath)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("AppendWatchEvent: error closing file \n err=%w fullPath=%s", err, fullPath)
+	}
+
+	return nil
+}
+
+// ReadSubscriptions reads the subscriptions from the subscriptions.json file.
+// A missing file is treated as no subscriptions.
+func (s *JSONStore) ReadSubscriptions() ([]data.Subscription, error) {
+	var subs []data.Subscription
+	err := s.readFile("subscriptions.json", &subs)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ReadSubscriptions: error reading file \n err=%w", err)
+	}
+
+	return subs, nil
+}
+
+// WriteSubscriptions writes the provided subscriptions to the subscriptions.json file.
+func (s *JSONStore) WriteSubscriptions(subs []data.Subscription) error {
+	if err := s.writeFile("subscriptions.json", subs); err != nil {
+		return fmt.Errorf("WriteSubscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// ensureIDs assigns IDs to any shows and films stored without one and writes
+// the affected files back, so legacy data gets stable IDs the first time it is loaded.
+func (s *JSONStore) ensureIDs() error {
+	s.idsMu.Lock()
+	defer s.idsMu.Unlock()
+
+	if s.idsAssigned {
+		return nil
+	}
+
+	ids, err := s.readLastIDs()
+	if err != nil {
+		return fmt.Errorf("ensureIDs: %w", err)
+	}
+
+	showFiles := []string{"shows.json", "currentShows.json", "completedShows.json"}
+	showLists := make([][]data.Show, len(showFiles))
+	for i, name := range showFiles {
+		err := s.readFile(name, &showLists[i])
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("ensureIDs: %w", err)
+		}
+	}
+
+	var films []data.Film
+	err = s.readFile("films.json", &films)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("ensureIDs: %w", err)
+	}
+
+	showsAssigned := assignShowIDs(ids.Shows, showLists...)
+	filmsAssigned := assignFilmIDs(ids.Films, films)
+
+	// data written before lastIDs.json existed still counts towards it
+	if err := s.raiseLastIDs(lastIDs{Shows: maxShowID(showLists...), Films: maxFilmID(films)}); err != nil {
+		return fmt.Errorf("ensureIDs: %w", err)
+	}
+
+	if showsAssigned {
+		for i, name := range showFiles {
+			if showLists[i] == nil {
+				continue
+			}
+			if err := s.writeFile(name, showLists[i]); err != nil {
+				return fmt.Errorf("ensureIDs: %w", err)
+			}
+		}
+	}
+	if filmsAssigned {
+		if err := s.writeFile("films.json", films); err != nil {
+			return fmt.Errorf("ensureIDs: %w", err)
+		}
+	}
+
+	s.idsAssigned = true
+	return nil
+}
+
+// readLastIDs reads the lastIDs.json file. A missing file is treated as no IDs stored yet.
+func (s *JSONStore) readLastIDs() (lastIDs, error) {
+	var ids lastIDs
+	err := s.readFile("lastIDs.json", &ids)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return lastIDs{}, fmt.Errorf("readLastIDs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// raiseLastIDs records the IDs in seen in the lastIDs.json file, keeping whichever of
+// each kind is higher.
+func (s *JSONStore) raiseLastIDs(seen lastIDs) error {
+	s.lastIDsMu.Lock()
+	defer s.lastIDsMu.Unlock()
+
+	ids, err := s.readLastIDs()
+	if err != nil {
+		return fmt.Errorf("raiseLastIDs: %w", err)
+	}
+	if seen.Shows <= ids.Shows && seen.Films <= ids.Films {
+		return nil
+	}
+
+	ids.Shows = max(ids.Shows, seen.Shows)
+	ids.Films = max(ids.Films, seen.Films)
+	if err := s.writeFile("lastIDs.json", ids); err != nil {
+		return fmt.Errorf("raiseLastIDs: %w", err)
+	}
+
+	return nil
+}
+
+// readFile reads the file at the given path within the store directory and unmarshals it into v.
+func (s *JSONStore) readFile(path string, v any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fullPath := filepath.Join(s.dir, path)
+	raw, err := os.ReadFile(fullPath)
+	if err != nil {
+		return fmt.Errorf("readFile: error reading file \n err=%w path=%s fullPath=%s", err, path, fullPath)
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("readFile: error decoding file \n err=%w path=%s fullPath=%s", err, path, fullPath)
+	}
+
+	return nil
+}
+
+// writeFile marshals v as indented JSON and atomically replaces the file at the given
+// path within the store directory. It writes to a temporary file in the same directory
+// and renames it so a failed write never leaves a partially written file behind.
+func (s *JSONStore) writeFile(path string, v any) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return s.replaceFile(path, raw)
+}
+
+// replaceFile atomically replaces the contents of the file at path with raw by writing
+// to a temp file in the same directory and renaming it over the original.
+func (s *JSONStore) replaceFile(path string, raw []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fullPath := filepath.Join(s.dir, path)
+
+	// create temp file in same directory to ensure atomic rename
+	dir := filepath.Dir(fullPath)
+	ext := filepath.Ext(path)
+	pattern := strings.TrimSuffix(filepath.Base(path), ext) + "-*" + ext + ".tmp"
+	tmpFile, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return fmt.Errorf("replaceFile: error creating temp file \n err=%w fullPath=%s", err, fullPath)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	// write to temp file
+	if _, err := tmpFile.Write(raw); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("replaceFile: error writing temp file \n err=%w fullPath=%s tmpPath=%s", err, fullPath, tmpPath)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("replaceFile: error closing temp file \n err=%w fullPath=%s tmpPath=%s", err, fullPath, tmpPath)
+	}
+
+	// rename temp file to final file
+	if err := os.Rename(tmpPath, fullPath); err != nil {
+		return fmt.Errorf("replaceFile: error renaming temp file \n err=%w fullPath=%s tmpPath=%s", err, fullPath, tmpPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package db
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+)
+
+func TestJSONStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := NewJSONStore(dir)
+
+	series, episode := 2, 3
+	shows := []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"drama"}, Episodes: []int{8, 9}, Provider: "Netflix", CurrentSeries: &series, CurrentEpisode: &episode},
+	}
+
+	if err := store.WriteCurrentShows(shows); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+
+	result, err := store.ReadCurrentShows()
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+
+	if !reflect.DeepEqual(result, shows) {
+		t.Errorf("expected %+v, got %+v", shows, result)
+	}
+
+	// the temp file used for the atomic write should not be left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error listing dir: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"currentShows.json", "lastIDs.json"}) {
+		t.Errorf("expected only currentShows.json and lastIDs.json in %s, got %v", dir, names)
+	}
+}
+
+func TestJSONStoreAssignsLegacyIDs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"shows.json":        `[{"name": "Show A"}, {"id": 7, "name": "Show B"}]`,
+		"currentShows.json": `[{"name": "Show C", "currentSeries": 1, "currentEpisode": 1}]`,
+		"films.json":        `[{"name": "Film A"}, {"name": "Film B"}]`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	store := NewJSONStore(dir)
+	catalogue, err := store.ReadShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current, err := store.ReadCurrentShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	films, err := store.ReadFilms()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	showIDs := []int{catalogue[0].ID, catalogue[1].ID, current[0].ID}
+	if !reflect.DeepEqual(showIDs, []int{8, 7, 9}) {
+		t.Errorf("expected show ids [8 7 9], got %v", showIDs)
+	}
+	filmIDs := []int{films[0].ID, films[1].ID}
+	if !reflect.DeepEqual(filmIDs, []int{1, 2}) {
+		t.Errorf("expected film ids [1 2], got %v", filmIDs)
+	}
+
+	// the IDs must be persisted so they survive a restart
+	reopened, err := NewJSONStore(dir).ReadShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(reopened, catalogue) {
+		t.Errorf("expected persisted %+v, got %+v", catalogue, reopened)
+	}
+}
+
+func TestJSONStoreKeepsLastFilmID(t *testing.T) {
+	dir := t.TempDir()
+
+	// films.json from before lastIDs.json existed
+	if err := os.WriteFile(filepath.Join(dir, "films.json"), []byte(`[{"id": 1, "name": "Film A"}, {"id": 5, "name": "Film B"}]`), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	store := NewJSONStore(dir)
+	if _, err := store.ReadFilms(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// deleting the film with the highest ID must not free its ID
+	if err := store.WriteFilms([]data.Film{{ID: 1, Name: "Film A"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	last, err := NewJSONStore(dir).LastFilmID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last != 5 {
+		t.Errorf("expected last film ID 5, got %d", last)
+	}
+}
+
+func TestJSONStoreKeepsLastShowID(t *testing.T) {
+	dir := t.TempDir()
+
+	// show lists from before lastIDs.json existed
+	files := map[string]string{
+		"shows.json":        `[{"id": 1, "name": "Show A"}, {"id": 6, "name": "Show B"}]`,
+		"currentShows.json": `[{"id": 4, "name": "Show C", "currentSeries": 1, "currentEpisode": 1}]`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	store := NewJSONStore(dir)
+	if _, err := store.ReadShows(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// deleting the show with the highest ID must not free its ID
+	if err := store.WriteShows([]data.Show{{ID: 1, Name: "Show A"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	last, err := NewJSONStore(dir).LastShowID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last != 6 {
+		t.Errorf("expected last show ID 6, got %d", last)
+	}
+}
+
+func TestJSONStoreReadsLegacyGenre(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"shows.json": `[{"id": 1, "name": "Show A", "genre": "Drama, Crime"}, {"id": 2, "name": "Show B", "genres": ["Comedy"]}]`,
+		"films.json": `[{"id": 1, "name": "Film A", "genre": "War"}]`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	store := NewJSONStore(dir)
+	shows, err := store.ReadShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	films, err := store.ReadFilms()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	genres := [][]string{shows[0].Genres, shows[1].Genres, films[0].Genres}
+	expected := [][]string{{"Drama", "Crime"}, {"Comedy"}, {"War"}}
+	if !reflect.DeepEqual(genres, expected) {
+		t.Errorf("expected genres %v, got %v", expected, genres)
+	}
+}
+
+func TestJSONStoreMissingFiles(t *testing.T) {
+	store := NewJSONStore(t.TempDir())
+
+	if _, err := store.ReadShows(); err == nil {
+		t.Errorf("expected error reading missing shows.json")
+	}
+
+	completed, err := store.ReadCompletedShows()
+	if err != nil {
+		t.Errorf("expected missing completedShows.json to be treated as empty, got %v", err)
+	}
+	if len(completed) != 0 {
+		t.Errorf("expected no completed shows, got %+v", completed)
+	}
+}
+
+func TestJSONStoreWatchHistory(t *testing.T) {
+	dir := t.TempDir()
+	store := NewJSONStore(dir)
+
+	history, err := store.ReadWatchHistory()
+	if err != nil {
+		t.Fatalf("expected missing history.jsonl to be treated as empty, got %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("expected no history, got %+v", history)
+	}
+
+	events := []data.WatchEvent{
+		{ShowID: 1, Show: "Show A", Series: 1, Episode: 1, WatchedAt: time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)},
+		{ShowID: 2, Show: "Show B", Series: 3, Episode: 7, WatchedAt: time.Date(2025, 11, 2, 21, 0, 0, 0, time.UTC)},
+		{ShowID: 2, Show: "Show B", Series: 3, Episode: 7, WatchedAt: time.Date(2025, 11, 2, 21, 5, 0, 0, time.UTC), Kind: data.EventUndo},
+	}
+	for _, e := range events {
+		if err := store.AppendWatchEvent(e); err != nil {
+			t.Fatalf("unexpected error appending: %v", err)
+		}
+	}
+
+	result, err := store.ReadWatchHistory()
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+	if !reflect.DeepEqual(result, events) {
+		t.Errorf("expected %+v, got %+v", events, result)
+	}
+
+	// each event is stored on its own line
+	raw, err := os.ReadFile(filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines := bytes.Count(raw, []byte("\n")); lines != len(events) {
+		t.Errorf("expected %d lines, got %d", len(events), lines)
+	}
+
+}
+
+func TestJSONStoreSubscriptions(t *testing.T) {
+	store := NewJSONStore(t.TempDir())
+
+	subs, err := store.ReadSubscriptions()
+	if err != nil {
+		t.Fatalf("expected missing subscriptions.json to be treated as empty, got %v", err)
+	}
+	if len(subs) != 0 {
+		t.Errorf("expected no subscriptions, got %+v", subs)
+	}
+
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	subs = []data.Subscription{
+		{Provider: "Netflix"},
+		{Provider: "Disney+", Start: &start, End: &end},
+	}
+	if err := store.WriteSubscriptions(subs); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+
+	result, err := store.ReadSubscriptions()
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+	if !reflect.DeepEqual(result, subs) {
+		t.Errorf("expected %+v, got %+v", subs, result)
+	}
+}
+
+func TestJSONStoreReadsRepositoryData(t *testing.T) {
+	if _, err := os.Stat("shows.json"); err != nil {
+		t.Skip("repository data files not available")
+	}
+
+	store := NewJSONStore(".")
+
+	if _, err := store.ReadShows(); err != nil {
+		t.Errorf("unexpected error reading shows: %v", err)
+	}
+	if _, err := store.ReadCurrentShows(); err != nil {
+		t.Errorf("unexpected error reading current shows: %v", err)
+	}
+	if _, err := store.ReadFilms(); err != nil {
+		t.Errorf("unexpected error reading films: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"slices"
+	"sync"
+
+	"what-to-watch/data"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store that keeps everything in memory.
+// It is intended for tests; the exported fields may be set directly before use.
+// Shows and films without an ID are assigned one when read, as they are by the other stores.
+type MemoryStore struct {
+	Shows          []data.Show
+	CurrentShows   []data.Show
+	CompletedShows []data.Show
+	Films          []data.Film
+	WatchHistory   []data.WatchEvent
+	Subscriptions  []data.Subscription
+
+	mu         sync.Mutex
+	lastShowID int
+	lastFilmID int
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// ReadShows returns a copy of the catalogue of shows.
+func (m *MemoryStore) ReadShows() ([]data.Show, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ensureShowIDs()
+	return slices.Clone(m.Shows), nil
+}
+
+// WriteShows replaces the catalogue of shows.
+func (m *MemoryStore) WriteShows(shows []data.Show) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastShowID = max(m.lastShowID, maxShowID(m.Shows, shows))
+	m.Shows = slices.Clone(shows)
+	return nil
+}
+
+// ReadCurrentShows returns a copy of the shows currently being watched.
+func (m *MemoryStore) ReadCurrentShows() ([]data.Show, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ensureShowIDs()
+	return slices.Clone(m.CurrentShows), nil
+}
+
+// WriteCurrentShows replaces the shows currently being watched.
+func (m *MemoryStore) WriteCurrentShows(shows []data.Show) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastShowID = max(m.lastShowID, maxShowID(m.CurrentShows, shows))
+	m.CurrentShows = slices.Clone(shows)
+	return nil
+}
+
+// ReadCompletedShows returns a copy of the shows that have been watched to the end.
+func (m *MemoryStore) ReadCompletedShows() ([]data.Show, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ensureShowIDs()
+	return slices.Clone(m.CompletedShows), nil
+}
+
+// WriteCompletedShows replaces the shows that have been watched to the end.
+func (m *MemoryStore) WriteCompletedShows(shows []data.Show) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastShowID = max(m.lastShowID, maxShowID(m.CompletedShows, shows))
+	m.CompletedShows = slices.Clone(shows)
+	return nil
+}
+
+// LastShowID returns the highest show ID ever stored in any list, including shows since deleted.
+func (m *MemoryStore) LastShowID() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ensureShowIDs()
+	return m.lastShowID, nil
+}
+
+// ReadFilms returns a copy of the films.
+func (m *MemoryStore) ReadFilms() ([]data.Film, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	assignFilmIDs(m.lastFilmID, m.Films)
+	m.lastFilmID = max(m.lastFilmID, maxFilmID(m.Films))
+	return slices.Clone(m.Films), nil
+}
+
+// WriteFilms replaces the films.
+func (m *MemoryStore) WriteFilms(films []data.Film) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastFilmID = max(m.lastFilmID, maxFilmID(m.Films), maxFilmID(films))
+	m.Films = slices.Clone(films)
+	return nil
+}
+
+// LastFilmID returns the highest film ID ever stored, including films since deleted.
+func (m *MemoryStore) LastFilmID() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastFilmID = max(m.lastFilmID, maxFilmID(m.Films))
+	return m.lastFilmID, nil
+}
+
+// ReadWatchHistory returns a copy of the watch history.
+func (m *MemoryStore) ReadWatchHistory() ([]data.WatchEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.WatchHistory), nil
+}
+
+// AppendWatchEvent adds an event to the end of the watch history.
+func (m *MemoryStore) AppendWatchEvent(event data.WatchEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.WatchHistory = append(m.WatchHistory, event)
+	return nil
+}
+
+// ReadSubscriptions returns a copy of the streaming subscriptions.
+func (m *MemoryStore) ReadSubscriptions() ([]data.Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.Subscriptions), nil
+}
+
+// WriteSubscriptions replaces the streaming subscriptions.
+func (m *MemoryStore) WriteSubscriptions(subs []data.Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Subscriptions = slices.Clone(subs)
+	return nil
+}
+
+// ensureShowIDs gives every show without an ID one, as the other stores do when reading,
+// and raises lastShowID to cover shows set directly on the exported fields.
+// m.mu must be held.
+func (m *MemoryStore) ensureShowIDs() {
+	assignShowIDs(m.lastShowID, m.Shows, m.CurrentShows, m.CompletedShows)
+	m.lastShowID = max(m.lastShowID, maxShowID(m.Shows, m.CurrentShows, m.CompletedShows))
+}
@@ -0,0 +1,789 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"what-to-watch/data"
+	"what-to-watch/genres"
+)
+
+var _ Store = (*SQLiteStore)(nil)
+
+// Show lists stored in the shows table.
+const (
+	listCatalogue = "catalogue"
+	listCurrent   = "current"
+	listCompleted = "completed"
+)
+
+// Kinds of ID whose highest value is kept in the last_ids table.
+const (
+	lastIDShows = "shows"
+	lastIDFilms = "films"
+)
+
+// migrations holds the schema changes applied in order on startup.
+// The index of the last applied migration plus one is kept in PRAGMA user_version,
+// so new migrations must only ever be appended.
+var migrations = []string{
+	`CREATE TABLE shows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		list TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		genre TEXT NOT NULL,
+		provider TEXT NOT NULL,
+		episodes TEXT NOT NULL,
+		current_series INTEGER,
+		current_episode INTEGER,
+		completed_at TEXT
+	);
+	CREATE INDEX shows_list_position ON shows (list, position);
+	CREATE TABLE films (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		position INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		genre TEXT NOT NULL,
+		provider TEXT NOT NULL
+	);`,
+	// stable IDs: the row IDs change on every write, so the data IDs get their own
+	// columns, seeded from the existing row IDs which are already unique
+	`ALTER TABLE shows ADD COLUMN show_id INTEGER NOT NULL DEFAULT 0;
+	UPDATE shows SET show_id = id;
+	ALTER TABLE films ADD COLUMN film_id INTEGER NOT NULL DEFAULT 0;
+	UPDATE films SET film_id = id;`,
+	`CREATE TABLE watch_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		show_id INTEGER NOT NULL,
+		show TEXT NOT NULL,
+		series INTEGER NOT NULL,
+		episode INTEGER NOT NULL,
+		watched_at TEXT NOT NULL
+	);
+	CREATE INDEX watch_history_watched_at ON watch_history (watched_at);`,
+	`ALTER TABLE films ADD COLUMN watched_at TEXT;`,
+	`ALTER TABLE shows ADD COLUMN runtime INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE shows ADD COLUMN series_runtimes TEXT;
+	ALTER TABLE films ADD COLUMN runtime INTEGER NOT NULL DEFAULT 0;`,
+	// genres holds a JSON array; the genre column is kept as a comma separated copy for older readers
+	`ALTER TABLE shows ADD COLUMN genres TEXT NOT NULL DEFAULT '[]';
+	UPDATE shows SET genres = json_array(genre) WHERE genre != '';
+	ALTER TABLE films ADD COLUMN genres TEXT NOT NULL DEFAULT '[]';
+	UPDATE films SET genres = json_array(genre) WHERE genre != '';`,
+	`CREATE TABLE subscriptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		position INTEGER NOT NULL,
+		provider TEXT NOT NULL,
+		start_at TEXT,
+		end_at TEXT
+	);`,
+	`ALTER TABLE watch_history ADD COLUMN kind TEXT NOT NULL DEFAULT '';`,
+	// the highest ID ever stored of each kind, so the ID of something deleted is never reused
+	`CREATE TABLE last_ids (
+		kind TEXT PRIMARY KEY,
+		id INTEGER NOT NULL
+	);`,
+}
+
+// migrationSteps holds Go code run after the migration at the same index, within the same
+// transaction, for changes that are awkward to express in SQL.
+var migrationSteps = map[int]func(tx *sql.Tx) error{
+	5: splitLegacyGenres,
+}
+
+// SQLiteStore is a Store backed by a SQLite database file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore opens (creating if needed) the SQLite database at path
+// and applies any outstanding schema migrations.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	sqlDB, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("OpenSQLiteStore: error opening database \n err=%w path=%s", err, path)
+	}
+
+	// a single connection serialises writes and avoids SQLITE_BUSY between our own goroutines
+	sqlDB.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: sqlDB}
+	if err := s.migrate(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("OpenSQLiteStore: error migrating database \n err=%w path=%s", err, path)
+	}
+
+	return s, nil
+}
+
+// Close closes the underlying database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// ReadShows returns the catalogue of shows.
+func (s *SQLiteStore) ReadShows() ([]data.Show, error) {
+	if err := s.ensureIDs(); err != nil {
+		return nil, fmt.Errorf("ReadShows: %w", err)
+	}
+
+	shows, err := s.readShows(listCatalogue)
+	if err != nil {
+		return nil, fmt.Errorf("ReadShows: %w", err)
+	}
+
+	return shows, nil
+}
+
+// WriteShows replaces the catalogue of shows.
+func (s *SQLiteStore) WriteShows(shows []data.Show) error {
+	if err := s.writeShows(listCatalogue, shows); err != nil {
+		return fmt.Errorf("WriteShows: %w", err)
+	}
+
+	return nil
+}
+
+// ReadCurrentShows returns the shows currently being watched.
+func (s *SQLiteStore) ReadCurrentShows() ([]data.Show, error) {
+	if err := s.ensureIDs(); err != nil {
+		return nil, fmt.Errorf("ReadCurrentShows: %w", err)
+	}
+
+	shows, err := s.readShows(listCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("ReadCurrentShows: %w", err)
+	}
+
+	return shows, nil
+}
+
+// WriteCurrentShows replaces the shows currently being watched.
+func (s *SQLiteStore) WriteCurrentShows(shows []data.Show) error {
+	if err := s.writeShows(listCurrent, shows); err != nil {
+		return fmt.Errorf("WriteCurrentShows: %w", err)
+	}
+
+	return nil
+}
+
+// ReadCompletedShows returns the shows that have been watched to the end.
+func (s *SQLiteStore) ReadCompletedShows() ([]data.Show, error) {
+	if err := s.ensureIDs(); err != nil {
+		return nil, fmt.Errorf("ReadCompletedShows: %w", err)
+	}
+
+	shows, err := s.readShows(listCompleted)
+	if err != nil {
+		return nil, fmt.Errorf("ReadCompletedShows: %w", err)
+	}
+
+	return shows, nil
+}
+
+// WriteCompletedShows replaces the shows that have been watched to the end.
+func (s *SQLiteStore) WriteCompletedShows(shows []data.Show) error {
+	if err := s.writeShows(listCompleted, shows); err != nil {
+		return fmt.Errorf("WriteCompletedShows: %w", err)
+	}
+
+	return nil
+}
+
+// ReadFilms returns the films.
+func (s *SQLiteStore) ReadFilms() ([]data.Film, error) {
+	if err := s.ensureIDs(); err != nil {
+		return nil, fmt.Errorf("ReadFilms: %w", err)
+	}
+
+	rows, err := s.db.Query(`SELECT film_id, name, genres, provider, runtime, watched_at FROM films ORDER BY position`)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFilms: error querying films \n err=%w", err)
+	}
+	defer rows.Close()
+
+	var films []data.Film
+	for rows.Next() {
+		var (
+			f         data.Film
+			genreList string
+			watchedAt sql.NullString
+		)
+		if err := rows.Scan(&f.ID, &f.Name, &genreList, &f.Provider, &f.Runtime, &watchedAt); err != nil {
+			return nil, fmt.Errorf("ReadFilms: error scanning film \n err=%w", err)
+		}
+
+		if err := json.Unmarshal([]byte(genreList), &f.Genres); err != nil {
+			return nil, fmt.Errorf("ReadFilms: error decoding genres \n err=%w name=%s", err, f.Name)
+		}
+
+		if watchedAt.Valid {
+			t, err := time.Parse(time.RFC3339Nano, watchedAt.String)
+			if err != nil {
+				return nil, fmt.Errorf("ReadFilms: error parsing watch time \n err=%w name=%s", err, f.Name)
+			}
+			f.WatchedAt = &t
+		}
+
+		films = append(films, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ReadFilms: error reading films \n err=%w", err)
+	}
+
+	return films, nil
+}
+
+// WriteFilms replaces the films.
+func (s *SQLiteStore) WriteFilms(films []data.Film) error {
+	if err := s.inTx(func(tx *sql.Tx) error { return replaceFilms(tx, films) }); err != nil {
+		return fmt.Errorf("WriteFilms: %w", err)
+	}
+
+	return nil
+}
+
+// LastShowID returns the highest show ID ever stored in any list, including shows since deleted.
+func (s *SQLiteStore) LastShowID() (int, error) {
+	last, err := lastID(s.db, lastIDShows)
+	if err != nil {
+		return 0, fmt.Errorf("LastShowID: %w", err)
+	}
+
+	return last, nil
+}
+
+// LastFilmID returns the highest film ID ever stored, including films since deleted.
+func (s *SQLiteStore) LastFilmID() (int, error) {
+	last, err := lastID(s.db, lastIDFilms)
+	if err != nil {
+		return 0, fmt.Errorf("LastFilmID: %w", err)
+	}
+
+	return last, nil
+}
+
+// ReadWatchHistory returns every recorded watch event, oldest first.
+func (s *SQLiteStore) ReadWatchHistory() ([]data.WatchEvent, error) {
+	rows, err := s.db.Query(`SELECT show_id, show, series, episode, watched_at, kind FROM watch_history ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ReadWatchHistory: error querying history \n err=%w", err)
+	}
+	defer rows.Close()
+
+	var events []data.WatchEvent
+	for rows.Next() {
+		var (
+			e         data.WatchEvent
+			watchedAt string
+		)
+		if err := rows.Scan(&e.ShowID, &e.Show, &e.Series, &e.Episode, &watchedAt, &e.Kind); err != nil {
+			return nil, fmt.Errorf("ReadWatchHistory: error scanning event \n err=%w", err)
+		}
+
+		e.WatchedAt, err = time.Parse(time.RFC3339Nano, watchedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ReadWatchHistory: error parsing watch time \n err=%w show=%s", err, e.Show)
+		}
+
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ReadWatchHistory: error reading history \n err=%w", err)
+	}
+
+	return events, nil
+}
+
+// AppendWatchEvent adds an event to the end of the watch history.
+func (s *SQLiteStore) AppendWatchEvent(event data.WatchEvent) error {
+	if _, err := s.db.Exec(`INSERT INTO watch_history (show_id, show, series, episode, watched_at, kind) VALUES (?, ?, ?, ?, ?, ?)`,
+		event.ShowID, event.Show, event.Series, event.Episode, event.WatchedAt.Format(time.RFC3339Nano), event.Kind); err != nil {
+		return fmt.Errorf("AppendWatchEvent: error inserting event \n err=%w show=%s", err, event.Show)
+	}
+
+	return nil
+}
+
+// ReadSubscriptions returns the streaming subscriptions.
+func (s *SQLiteStore) ReadSubscriptions() ([]data.Subscription, error) {
+	rows, err := s.db.Query(`SELECT provider, start_at, end_at FROM subscriptions ORDER BY position`)
+	if err != nil {
+		return nil, fmt.Errorf("ReadSubscriptions: error querying subscriptions \n err=%w", err)
+	}
+	defer rows.Close()
+
+	var subs []data.Subscription
+	for rows.Next() {
+		var (
+			sub   data.Subscription
+			start sql.NullString
+			end   sql.NullString
+		)
+		if err := rows.Scan(&sub.Provider, &start, &end); err != nil {
+			return nil, fmt.Errorf("ReadSubscriptions: error scanning subscription \n err=%w", err)
+		}
+
+		if sub.Start, err = nullTime(start); err != nil {
+			return nil, fmt.Errorf("ReadSubscriptions: error parsing start time \n err=%w provider=%s", err, sub.Provider)
+		}
+		if sub.End, err = nullTime(end); err != nil {
+			return nil, fmt.Errorf("ReadSubscriptions: error parsing end time \n err=%w provider=%s", err, sub.Provider)
+		}
+
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ReadSubscriptions: error reading subscriptions \n err=%w", err)
+	}
+
+	return subs, nil
+}
+
+// WriteSubscriptions replaces the streaming subscriptions.
+func (s *SQLiteStore) WriteSubscriptions(subs []data.Subscription) error {
+	if err := s.inTx(func(tx *sql.Tx) error { return replaceSubscriptions(tx, subs) }); err != nil {
+		return fmt.Errorf("WriteSubscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// migrate applies every migration newer than the database's user_version.
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("migrate: error reading schema version \n err=%w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("migrate: error starting transaction \n err=%w version=%d", err, i+1)
+		}
+
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrate: error applying migration \n err=%w version=%d", err, i+1)
+		}
+
+		if step, ok := migrationSteps[i]; ok {
+			if err := step(tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migrate: error applying migration step \n err=%w version=%d", err, i+1)
+			}
+		}
+
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrate: error setting schema version \n err=%w version=%d", err, i+1)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migrate: error committing migration \n err=%w version=%d", err, i+1)
+		}
+	}
+
+	return nil
+}
+
+// splitLegacyGenres splits the comma separated genre column of shows and films that have at most
+// one genre into the genres list, as data.Show and data.Film do when reading legacy JSON, so a
+// legacy genre such as "comedy, drama" becomes two genres.
+func splitLegacyGenres(tx *sql.Tx) error {
+	for _, table := range []string{"shows", "films"} {
+		rows, err := tx.Query(`SELECT id, genre FROM ` + table + ` WHERE genre != '' AND json_array_length(genres) <= 1`)
+		if err != nil {
+			return fmt.Errorf("splitLegacyGenres: error querying genres \n err=%w table=%s", err, table)
+		}
+
+		split := map[int64]string{}
+		for rows.Next() {
+			var (
+				id    int64
+				genre string
+			)
+			if err := rows.Scan(&id, &genre); err != nil {
+				rows.Close()
+				return fmt.Errorf("splitLegacyGenres: error scanning genre \n err=%w table=%s", err, table)
+			}
+
+			raw, err := json.Marshal(genres.Split(genre))
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("splitLegacyGenres: error encoding genres \n err=%w table=%s genre=%s", err, table, genre)
+			}
+			split[id] = string(raw)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return fmt.Errorf("splitLegacyGenres: error reading genres \n err=%w table=%s", err, table)
+		}
+		rows.Close()
+
+		for id, genreList := range split {
+			if _, err := tx.Exec(`UPDATE `+table+` SET genres = ? WHERE id = ?`, genreList, id); err != nil {
+				return fmt.Errorf("splitLegacyGenres: error updating genres \n err=%w table=%s id=%d", err, table, id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// readShows returns the shows in the given list, in the order they were written.
+func (s *SQLiteStore) readShows(list string) ([]data.Show, error) {
+	rows, err := s.db.Query(`SELECT show_id, name, genres, provider, episodes, runtime, series_runtimes, current_series, current_episode, completed_at
+		FROM shows WHERE list = ? ORDER BY position`, list)
+	if err != nil {
+		return nil, fmt.Errorf("readShows: error querying shows \n err=%w list=%s", err, list)
+	}
+	defer rows.Close()
+
+	var shows []data.Show
+	for rows.Next() {
+		var (
+			sh             data.Show
+			genreList      string
+			episodes       string
+			seriesRuntimes sql.NullString
+			currentSeries  sql.NullInt64
+			currentEpisode sql.NullInt64
+			completedAt    sql.NullString
+		)
+		if err := rows.Scan(&sh.ID, &sh.Name, &genreList, &sh.Provider, &episodes, &sh.Runtime, &seriesRuntimes, &currentSeries, &currentEpisode, &completedAt); err != nil {
+			return nil, fmt.Errorf("readShows: error scanning show \n err=%w list=%s", err, list)
+		}
+
+		if err := json.Unmarshal([]byte(genreList), &sh.Genres); err != nil {
+			return nil, fmt.Errorf("readShows: error decoding genres \n err=%w list=%s name=%s", err, list, sh.Name)
+		}
+		if err := json.Unmarshal([]byte(episodes), &sh.Episodes); err != nil {
+			return nil, fmt.Errorf("readShows: error decoding episodes \n err=%w list=%s name=%s", err, list, sh.Name)
+		}
+		if seriesRuntimes.Valid {
+			if err := json.Unmarshal([]byte(seriesRuntimes.String), &sh.SeriesRuntimes); err != nil {
+				return nil, fmt.Errorf("readShows: error decoding series runtimes \n err=%w list=%s name=%s", err, list, sh.Name)
+			}
+		}
+		if currentSeries.Valid {
+			v := int(currentSeries.Int64)
+			sh.CurrentSeries = &v
+		}
+		if currentEpisode.Valid {
+			v := int(currentEpisode.Int64)
+			sh.CurrentEpisode = &v
+		}
+		if completedAt.Valid {
+			t, err := time.Parse(time.RFC3339Nano, completedAt.String)
+			if err != nil {
+				return nil, fmt.Errorf("readShows: error parsing completion time \n err=%w list=%s name=%s", err, list, sh.Name)
+			}
+			sh.CompletedAt = &t
+		}
+
+		shows = append(shows, sh)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("readShows: error reading shows \n err=%w list=%s", err, list)
+	}
+
+	return shows, nil
+}
+
+// writeShows replaces the shows in the given list within a single transaction.
+func (s *SQLiteStore) writeShows(list string, shows []data.Show) error {
+	if err := s.inTx(func(tx *sql.Tx) error { return replaceShows(tx, list, shows) }); err != nil {
+		return fmt.Errorf("writeShows: %w", err)
+	}
+
+	return nil
+}
+
+// ensureIDs gives every show and film stored without an ID the next free one, as the JSON
+// and memory stores do, so rows written without an ID still read back with a stable ID.
+func (s *SQLiteStore) ensureIDs() error {
+	var missing bool
+	if err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM shows WHERE show_id = 0) OR EXISTS (SELECT 1 FROM films WHERE film_id = 0)`).Scan(&missing); err != nil {
+		return fmt.Errorf("ensureIDs: error checking for missing IDs \n err=%w", err)
+	}
+	if !missing {
+		return nil
+	}
+
+	err := s.inTx(func(tx *sql.Tx) error {
+		rowIDs, ids, err := queryIDs(tx, `SELECT id, show_id FROM shows ORDER BY id`)
+		if err != nil {
+			return err
+		}
+		shows := make([]data.Show, len(ids))
+		for i, id := range ids {
+			shows[i].ID = id
+		}
+		last, err := lastID(tx, lastIDShows)
+		if err != nil {
+			return err
+		}
+		assignShowIDs(last, shows)
+		for i := range shows {
+			if ids[i] != 0 {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE shows SET show_id = ? WHERE id = ?`, shows[i].ID, rowIDs[i]); err != nil {
+				return fmt.Errorf("error assigning show ID \n err=%w id=%d", err, shows[i].ID)
+			}
+		}
+
+		rowIDs, ids, err = queryIDs(tx, `SELECT id, film_id FROM films ORDER BY id`)
+		if err != nil {
+			return err
+		}
+		films := make([]data.Film, len(ids))
+		for i, id := range ids {
+			films[i].ID = id
+		}
+		last, err = lastID(tx, lastIDFilms)
+		if err != nil {
+			return err
+		}
+		assignFilmIDs(last, films)
+		for i := range films {
+			if ids[i] != 0 {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE films SET film_id = ? WHERE id = ?`, films[i].ID, rowIDs[i]); err != nil {
+				return fmt.Errorf("error assigning film ID \n err=%w id=%d", err, films[i].ID)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ensureIDs: %w", err)
+	}
+
+	return nil
+}
+
+// inTx runs fn within a single transaction, which is only committed if fn succeeds.
+func (s *SQLiteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("inTx: error starting transaction \n err=%w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("inTx: error committing transaction \n err=%w", err)
+	}
+
+	return nil
+}
+
+// queryIDs returns the row ID and the data ID of every row returned by query, in order.
+func queryIDs(tx *sql.Tx, query string) ([]int64, []int, error) {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("queryIDs: error querying IDs \n err=%w", err)
+	}
+	defer rows.Close()
+
+	var (
+		rowIDs []int64
+		ids    []int
+	)
+	for rows.Next() {
+		var (
+			rowID int64
+			id    int
+		)
+		if err := rows.Scan(&rowID, &id); err != nil {
+			return nil, nil, fmt.Errorf("queryIDs: error scanning IDs \n err=%w", err)
+		}
+		rowIDs = append(rowIDs, rowID)
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("queryIDs: error reading IDs \n err=%w", err)
+	}
+
+	return rowIDs, ids, nil
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// lastID returns the highest ID of the given kind ever stored, or 0 if none has been.
+func lastID(q rowQuerier, kind string) (int, error) {
+	var last int
+	err := q.QueryRow(`SELECT id FROM last_ids WHERE kind = ?`, kind).Scan(&last)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("lastID: error reading last ID \n err=%w kind=%s", err, kind)
+	}
+
+	return last, nil
+}
+
+// raiseLastID records id as the highest ID of the given kind within tx, unless a higher
+// one is already recorded.
+func raiseLastID(tx *sql.Tx, kind string, id int) error {
+	if _, err := tx.Exec(`INSERT INTO last_ids (kind, id) VALUES (?, ?)
+		ON CONFLICT (kind) DO UPDATE SET id = MAX(id, excluded.id)`, kind, id); err != nil {
+		return fmt.Errorf("raiseLastID: error updating last ID \n err=%w kind=%s id=%d", err, kind, id)
+	}
+
+	return nil
+}
+
+// replaceShows replaces the shows in the given list within tx, keeping the highest show ID
+// of both the old shows in any list and the new shows in last_ids.
+func replaceShows(tx *sql.Tx, list string, shows []data.Show) error {
+	var last int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(show_id), 0) FROM shows`).Scan(&last); err != nil {
+		return fmt.Errorf("replaceShows: error reading highest show ID \n err=%w list=%s", err, list)
+	}
+	if err := raiseLastID(tx, lastIDShows, max(last, maxShowID(shows))); err != nil {
+		return fmt.Errorf("replaceShows: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM shows WHERE list = ?`, list); err != nil {
+		return fmt.Errorf("replaceShows: error clearing shows \n err=%w list=%s", err, list)
+	}
+
+	for i, sh := range shows {
+		genreList, err := json.Marshal(sh.Genres)
+		if err != nil {
+			return fmt.Errorf("replaceShows: error encoding genres \n err=%w list=%s name=%s", err, list, sh.Name)
+		}
+
+		episodes, err := json.Marshal(sh.Episodes)
+		if err != nil {
+			return fmt.Errorf("replaceShows: error encoding episodes \n err=%w list=%s name=%s", err, list, sh.Name)
+		}
+
+		var seriesRuntimes sql.NullString
+		if len(sh.SeriesRuntimes) > 0 {
+			raw, err := json.Marshal(sh.SeriesRuntimes)
+			if err != nil {
+				return fmt.Errorf("replaceShows: error encoding series runtimes \n err=%w list=%s name=%s", err, list, sh.Name)
+			}
+			seriesRuntimes = sql.NullString{String: string(raw), Valid: true}
+		}
+
+		if _, err := tx.Exec(`INSERT INTO shows
+			(list, position, show_id, name, genre, genres, provider, episodes, runtime, series_runtimes, current_series, current_episode, completed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			list, i, sh.ID, sh.Name, genres.Join(sh.Genres), string(genreList), sh.Provider, string(episodes), sh.Runtime, seriesRuntimes,
+			nullInt(sh.CurrentSeries), nullInt(sh.CurrentEpisode), timeString(sh.CompletedAt)); err != nil {
+			return fmt.Errorf("replaceShows: error inserting show \n err=%w list=%s name=%s", err, list, sh.Name)
+		}
+	}
+
+	return nil
+}
+
+// replaceFilms replaces the films within tx, keeping the highest film ID of both the
+// old and the new films in last_ids.
+func replaceFilms(tx *sql.Tx, films []data.Film) error {
+	var last int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(film_id), 0) FROM films`).Scan(&last); err != nil {
+		return fmt.Errorf("replaceFilms: error reading highest film ID \n err=%w", err)
+	}
+	if err := raiseLastID(tx, lastIDFilms, max(last, maxFilmID(films))); err != nil {
+		return fmt.Errorf("replaceFilms: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM films`); err != nil {
+		return fmt.Errorf("replaceFilms: error clearing films \n err=%w", err)
+	}
+
+	for i, f := range films {
+		genreList, err := json.Marshal(f.Genres)
+		if err != nil {
+			return fmt.Errorf("replaceFilms: error encoding genres \n err=%w name=%s", err, f.Name)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO films (position, film_id, name, genre, genres, provider, runtime, watched_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			i, f.ID, f.Name, genres.Join(f.Genres), string(genreList), f.Provider, f.Runtime, timeString(f.WatchedAt)); err != nil {
+			return fmt.Errorf("replaceFilms: error inserting film \n err=%w name=%s", err, f.Name)
+		}
+	}
+
+	return nil
+}
+
+// replaceWatchHistory replaces the watch history within tx.
+func replaceWatchHistory(tx *sql.Tx, events []data.WatchEvent) error {
+	if _, err := tx.Exec(`DELETE FROM watch_history`); err != nil {
+		return fmt.Errorf("replaceWatchHistory: error clearing history \n err=%w", err)
+	}
+
+	for _, e := range events {
+		if _, err := tx.Exec(`INSERT INTO watch_history (show_id, show, series, episode, watched_at, kind) VALUES (?, ?, ?, ?, ?, ?)`,
+			e.ShowID, e.Show, e.Series, e.Episode, e.WatchedAt.Format(time.RFC3339Nano), e.Kind); err != nil {
+			return fmt.Errorf("replaceWatchHistory: error inserting event \n err=%w show=%s", err, e.Show)
+		}
+	}
+
+	return nil
+}
+
+// replaceSubscriptions replaces the streaming subscriptions within tx.
+func replaceSubscriptions(tx *sql.Tx, subs []data.Subscription) error {
+	if _, err := tx.Exec(`DELETE FROM subscriptions`); err != nil {
+		return fmt.Errorf("replaceSubscriptions: error clearing subscriptions \n err=%w", err)
+	}
+
+	for i, sub := range subs {
+		if _, err := tx.Exec(`INSERT INTO subscriptions (position, provider, start_at, end_at) VALUES (?, ?, ?, ?)`,
+			i, sub.Provider, timeString(sub.Start), timeString(sub.End)); err != nil {
+			return fmt.Errorf("replaceSubscriptions: error inserting subscription \n err=%w provider=%s", err, sub.Provider)
+		}
+	}
+
+	return nil
+}
+
+// nullInt converts an optional int into a value suitable for a nullable column.
+func nullInt(v *int) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
+
+// timeString converts an optional time into a value suitable for a nullable column.
+func timeString(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.Format(time.RFC3339Nano), Valid: true}
+}
+
+// nullTime parses a nullable time column, returning nil when it is NULL.
+func nullTime(v sql.NullString) (*time.Time, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,433 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+)
+
+func openTestSQLiteStore(t *testing.T, path string) *SQLiteStore {
+	t.Helper()
+
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func TestSQLiteStoreShowLists(t *testing.T) {
+	store := openTestSQLiteStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	series, episode := 3, 4
+	completedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+	catalogue := []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"drama", "crime"}, Provider: "Netflix", Episodes: []int{8, 9}, Runtime: 45, SeriesRuntimes: []int{0, 55}},
+		{ID: 2, Name: "Show B", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{6}},
+	}
+	current := []data.Show{
+		{ID: 3, Name: "Show C", Genres: []string{"comedy"}, Provider: "Disney+", Episodes: []int{10, 10, 10}, Runtime: 22, CurrentSeries: &series, CurrentEpisode: &episode},
+	}
+	completed := []data.Show{
+		{ID: 4, Name: "Show D", Genres: []string{"documentary"}, Provider: "Netflix", Episodes: []int{8}, CompletedAt: &completedAt},
+	}
+
+	if err := store.WriteShows(catalogue); err != nil {
+		t.Fatalf("unexpected error writing shows: %v", err)
+	}
+	if err := store.WriteCurrentShows(current); err != nil {
+		t.Fatalf("unexpected error writing current shows: %v", err)
+	}
+	if err := store.WriteCompletedShows(completed); err != nil {
+		t.Fatalf("unexpected error writing completed shows: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		read     func() ([]data.Show, error)
+		expected []data.Show
+	}{
+		{name: "catalogue", read: store.ReadShows, expected: catalogue},
+		{name: "current", read: store.ReadCurrentShows, expected: current},
+		{name: "completed", read: store.ReadCompletedShows, expected: completed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.read()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+
+	// rewriting one list must not touch the others
+	if err := store.WriteCurrentShows(nil); err != nil {
+		t.Fatalf("unexpected error clearing current shows: %v", err)
+	}
+	result, err := store.ReadShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, catalogue) {
+		t.Errorf("expected catalogue %+v to be untouched, got %+v", catalogue, result)
+	}
+}
+
+func TestSQLiteStoreWatchHistory(t *testing.T) {
+	store := openTestSQLiteStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	events := []data.WatchEvent{
+		{ShowID: 1, Show: "Show A", Series: 1, Episode: 1, WatchedAt: time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)},
+		{ShowID: 2, Show: "Show B", Series: 3, Episode: 7, WatchedAt: time.Date(2025, 11, 2, 21, 0, 0, 0, time.UTC)},
+		{ShowID: 2, Show: "Show B", Series: 3, Episode: 7, WatchedAt: time.Date(2025, 11, 2, 21, 5, 0, 0, time.UTC), Kind: data.EventUndo},
+	}
+	for _, e := range events {
+		if err := store.AppendWatchEvent(e); err != nil {
+			t.Fatalf("unexpected error appending: %v", err)
+		}
+	}
+
+	result, err := store.ReadWatchHistory()
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+	if !reflect.DeepEqual(result, events) {
+		t.Errorf("expected %+v, got %+v", events, result)
+	}
+}
+
+func TestSQLiteStoreSubscriptions(t *testing.T) {
+	store := openTestSQLiteStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	subs := []data.Subscription{
+		{Provider: "Netflix"},
+		{Provider: "Disney+", Start: &start, End: &end},
+	}
+	if err := store.WriteSubscriptions(subs); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+
+	result, err := store.ReadSubscriptions()
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+	if !reflect.DeepEqual(result, subs) {
+		t.Errorf("expected %+v, got %+v", subs, result)
+	}
+}
+
+func TestSQLiteStoreMigrationsAreIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	first := openTestSQLiteStore(t, path)
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+	films := []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"war"}, Provider: "Netflix"},
+		{ID: 2, Name: "Film B", Genres: []string{"comedy"}, Provider: "itvX", Runtime: 118, WatchedAt: &watchedAt},
+	}
+	if err := first.WriteFilms(films); err != nil {
+		t.Fatalf("unexpected error writing films: %v", err)
+	}
+	first.Close()
+
+	second := openTestSQLiteStore(t, path)
+
+	var version int
+	if err := second.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatalf("unexpected error reading version: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("expected schema version %d, got %d", len(migrations), version)
+	}
+
+	result, err := second.ReadFilms()
+	if err != nil {
+		t.Fatalf("unexpected error reading films: %v", err)
+	}
+	if !reflect.DeepEqual(result, films) {
+		t.Errorf("expected %+v, got %+v", films, result)
+	}
+}
+
+func TestSQLiteStoreAssignsMissingIDs(t *testing.T) {
+	store := openTestSQLiteStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	if err := store.WriteShows([]data.Show{{Name: "Show A"}, {ID: 7, Name: "Show B"}}); err != nil {
+		t.Fatalf("unexpected error writing shows: %v", err)
+	}
+	if err := store.WriteCurrentShows([]data.Show{{Name: "Show C"}}); err != nil {
+		t.Fatalf("unexpected error writing current shows: %v", err)
+	}
+	if err := store.WriteFilms([]data.Film{{Name: "Film A"}, {Name: "Film B"}}); err != nil {
+		t.Fatalf("unexpected error writing films: %v", err)
+	}
+
+	catalogue, err := store.ReadShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current, err := store.ReadCurrentShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	films, err := store.ReadFilms()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// IDs continue on from the highest ID already in use, as in the other stores
+	ids := []int{catalogue[0].ID, catalogue[1].ID, current[0].ID, films[0].ID, films[1].ID}
+	if !reflect.DeepEqual(ids, []int{8, 7, 9, 1, 2}) {
+		t.Errorf("expected ids [8 7 9 1 2], got %v", ids)
+	}
+
+	// the assigned IDs are stored, so they are stable across reads
+	again, err := store.ReadShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(again, catalogue) {
+		t.Errorf("expected %+v, got %+v", catalogue, again)
+	}
+}
+
+func TestSQLiteStoreKeepsLastFilmID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	store := openTestSQLiteStore(t, path)
+
+	if err := store.WriteFilms([]data.Film{{ID: 1, Name: "Film A"}, {ID: 5, Name: "Film B"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// deleting the film with the highest ID must not free its ID
+	if err := store.WriteFilms([]data.Film{{ID: 1, Name: "Film A"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.Close()
+
+	reopened := openTestSQLiteStore(t, path)
+	last, err := reopened.LastFilmID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last != 5 {
+		t.Errorf("expected last film ID 5, got %d", last)
+	}
+}
+
+func TestSQLiteStoreKeepsLastShowID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	store := openTestSQLiteStore(t, path)
+
+	if err := store.WriteShows([]data.Show{{ID: 1, Name: "Show A"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.WriteCompletedShows([]data.Show{{ID: 6, Name: "Show B"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// deleting the show with the highest ID, from any list, must not free its ID
+	if err := store.WriteCompletedShows(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.Close()
+
+	reopened := openTestSQLiteStore(t, path)
+	last, err := reopened.LastShowID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last != 6 {
+		t.Errorf("expected last show ID 6, got %d", last)
+	}
+}
+
+func TestSQLiteStoreMigratesLegacyIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// build a database at schema version 1, before shows and films had IDs
+	sqlDB, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, stmt := range []string{
+		migrations[0],
+		`PRAGMA user_version = 1`,
+		`INSERT INTO shows (list, position, name, genre, provider, episodes) VALUES ('catalogue', 0, 'Show A', 'drama', 'Netflix', '[8]')`,
+		`INSERT INTO shows (list, position, name, genre, provider, episodes, current_series, current_episode) VALUES ('current', 0, 'Show B', 'comedy', 'itvX', '[6]', 1, 2)`,
+		`INSERT INTO films (position, name, genre, provider) VALUES (0, 'Film A', 'war', 'Netflix')`,
+		`INSERT INTO films (position, name, genre, provider) VALUES (1, 'Film B', 'comedy, Drama,comedy', 'Netflix')`,
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	sqlDB.Close()
+
+	store := openTestSQLiteStore(t, path)
+
+	catalogue, err := store.ReadShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current, err := store.ReadCurrentShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	films, err := store.ReadFilms()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ids := []int{catalogue[0].ID, current[0].ID, films[0].ID}
+	if !reflect.DeepEqual(ids, []int{1, 2, 1}) {
+		t.Errorf("expected ids [1 2 1], got %v", ids)
+	}
+
+	// the single genre column is carried over into the list of genres, split on commas
+	// as it is when legacy JSON is read
+	genres := [][]string{catalogue[0].Genres, current[0].Genres, films[0].Genres, films[1].Genres}
+	if !reflect.DeepEqual(genres, [][]string{{"drama"}, {"comedy"}, {"war"}, {"comedy", "Drama"}}) {
+		t.Errorf("expected genres [[drama] [comedy] [war] [comedy Drama]], got %v", genres)
+	}
+}
+
+func TestImportJSON(t *testing.T) {
+	dir := t.TempDir()
+	src := NewJSONStore(dir)
+
+	series, episode := 1, 2
+	catalogue := []data.Show{{Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{8}}}
+	current := []data.Show{{Name: "Show B", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{6}, CurrentSeries: &series, CurrentEpisode: &episode}}
+	films := []data.Film{{Name: "Film A", Genres: []string{"war"}, Provider: "Netflix"}}
+	history := []data.WatchEvent{{ShowID: 2, Show: "Show B", Series: 1, Episode: 1, WatchedAt: time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)}}
+	subs := []data.Subscription{{Provider: "Netflix"}}
+
+	if err := src.WriteShows(catalogue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := src.WriteCurrentShows(current); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := src.writeFile("films.json", films); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := src.AppendWatchEvent(history[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := src.WriteSubscriptions(subs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a deleted film had ID 4, which must not be reused after the import
+	if err := src.writeFile("lastIDs.json", lastIDs{Films: 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dst := openTestSQLiteStore(t, filepath.Join(dir, "test.db"))
+	if err := ImportJSON(dst, dir); err != nil {
+		t.Fatalf("unexpected error importing: %v", err)
+	}
+
+	// legacy JSON data without IDs is assigned them on import
+	catalogue[0].ID = 1
+	current[0].ID = 2
+	films[0].ID = 5
+
+	if result, _ := dst.ReadShows(); !reflect.DeepEqual(result, catalogue) {
+		t.Errorf("expected catalogue %+v, got %+v", catalogue, result)
+	}
+	if result, _ := dst.ReadCurrentShows(); !reflect.DeepEqual(result, current) {
+		t.Errorf("expected current %+v, got %+v", current, result)
+	}
+	if result, _ := dst.ReadCompletedShows(); len(result) != 0 {
+		t.Errorf("expected no completed shows, got %+v", result)
+	}
+	if result, _ := dst.ReadFilms(); !reflect.DeepEqual(result, films) {
+		t.Errorf("expected films %+v, got %+v", films, result)
+	}
+	if result, _ := dst.ReadWatchHistory(); !reflect.DeepEqual(result, history) {
+		t.Errorf("expected history %+v, got %+v", history, result)
+	}
+	if result, _ := dst.ReadSubscriptions(); !reflect.DeepEqual(result, subs) {
+		t.Errorf("expected subscriptions %+v, got %+v", subs, result)
+	}
+	if last, _ := dst.LastFilmID(); last != 5 {
+		t.Errorf("expected last film ID 5, got %d", last)
+	}
+}
+
+func TestImportJSONKeepsLastShowID(t *testing.T) {
+	dir := t.TempDir()
+	src := NewJSONStore(dir)
+	if err := src.WriteShows([]data.Show{{ID: 1, Name: "Show A"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := src.WriteCurrentShows([]data.Show{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := src.WriteFilms([]data.Film{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// a deleted show had ID 6, which must not be reused after the import
+	if err := src.writeFile("lastIDs.json", lastIDs{Shows: 6}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dst := openTestSQLiteStore(t, filepath.Join(dir, "test.db"))
+	if err := ImportJSON(dst, dir); err != nil {
+		t.Fatalf("unexpected error importing: %v", err)
+	}
+
+	last, err := dst.LastShowID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last != 6 {
+		t.Errorf("expected last show ID 6, got %d", last)
+	}
+}
+
+func TestImportJSONFailureLeavesStoreUntouched(t *testing.T) {
+	dir := t.TempDir()
+	src := NewJSONStore(dir)
+	if err := src.WriteShows([]data.Show{{ID: 1, Name: "New Show"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := src.WriteSubscriptions([]data.Subscription{{Provider: "Netflix"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dst := openTestSQLiteStore(t, filepath.Join(dir, "test.db"))
+	if err := dst.WriteShows([]data.Show{{ID: 1, Name: "Old Show"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// make the last step of the import fail
+	if _, err := dst.db.Exec(`CREATE TRIGGER fail_import BEFORE INSERT ON subscriptions BEGIN SELECT RAISE(ABORT, 'import failed'); END`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ImportJSON(dst, dir); err == nil {
+		t.Fatal("expected an error importing")
+	}
+
+	result, err := dst.ReadShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 || result[0].Name != "Old Show" {
+		t.Errorf("expected the catalogue to still hold only Old Show, got %+v", result)
+	}
+}
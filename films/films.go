@@ -0,0 +1,196 @@
+package films
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"what-to-watch/data"
+	"what-to-watch/genres"
+)
+
+// ErrFilmNotFound is returned when no film has the requested ID.
+var ErrFilmNotFound = errors.New("film not found")
+
+// ErrInvalidFilm is returned when a film being added or edited fails validation.
+var ErrInvalidFilm = errors.New("invalid film")
+
+// findFilm returns the position of the film with the given ID, or -1 if there is none.
+func findFilm(films []data.Film, id int) int {
+	for i, f := range films {
+		if f.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetUnwatchedFilms returns the films that haven't been watched yet
+func GetUnwatchedFilms(films []data.Film) []data.Film {
+	var unwatched []data.Film
+	for _, f := range films {
+		if f.WatchedAt == nil {
+			unwatched = append(unwatched, f)
+		}
+	}
+	return unwatched
+}
+
+// Filter narrows a list of films. Empty fields match every film.
+type Filter struct {
+	// Genres keeps films with any of the genres, or all of them when MatchAll is true
+	Genres   []string
+	MatchAll bool
+	// Providers keeps films on any of the providers
+	Providers []string
+	// IncludeWatched keeps watched films, which are otherwise left out
+	IncludeWatched bool
+	// SubscribedOnly keeps films on providers with an active subscription. The handlers
+	// resolve it into Providers, as the subscriptions are not known here
+	SubscribedOnly bool
+}
+
+// FilterFilms returns the films matching the filter, in their original order.
+// Genres and providers are matched case-insensitively.
+func FilterFilms(films []data.Film, filter Filter) []data.Film {
+	var matched []data.Film
+	for _, f := range films {
+		if f.WatchedAt != nil && !filter.IncludeWatched {
+			continue
+		}
+		if !genres.Matches(f.Genres, filter.Genres, filter.MatchAll) {
+			continue
+		}
+		if len(filter.Providers) > 0 && !slices.ContainsFunc(filter.Providers, func(p string) bool {
+			return strings.EqualFold(strings.TrimSpace(p), strings.TrimSpace(f.Provider))
+		}) {
+			continue
+		}
+		matched = append(matched, f)
+	}
+	return matched
+}
+
+// ValidateFilm checks that a film has a name, at least one genre and a provider, and no negative runtime.
+func ValidateFilm(film data.Film) error {
+	if strings.TrimSpace(film.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidFilm)
+	}
+	if len(genres.Clean(film.Genres)) == 0 {
+		return fmt.Errorf("%w: at least one genre is required", ErrInvalidFilm)
+	}
+	if strings.TrimSpace(film.Provider) == "" {
+		return fmt.Errorf("%w: provider is required", ErrInvalidFilm)
+	}
+	if film.Runtime < 0 {
+		return fmt.Errorf("%w: runtime cannot be negative, got %d", ErrInvalidFilm, film.Runtime)
+	}
+
+	return nil
+}
+
+// NextFilmID returns an ID one higher than last, the highest film ID ever stored, and than
+// any of the given films, so that the ID of a deleted film is never reused.
+func NextFilmID(last int, films []data.Film) int {
+	next := last + 1
+	for _, f := range films {
+		if f.ID >= next {
+			next = f.ID + 1
+		}
+	}
+	return next
+}
+
+// checkDuplicateName returns an error if a film other than the one with the given ID
+// already has the given name.
+func checkDuplicateName(films []data.Film, id int, name string) error {
+	for _, f := range films {
+		if f.ID != id && strings.EqualFold(f.Name, strings.TrimSpace(name)) {
+			return fmt.Errorf("%w: %s is already in the films", ErrInvalidFilm, f.Name)
+		}
+	}
+	return nil
+}
+
+// AddFilm validates the film and appends it, unwatched, with the given ID.
+// It returns the updated films slice, the added film, and an error.
+func AddFilm(films []data.Film, id int, film data.Film) ([]data.Film, data.Film, error) {
+	if err := ValidateFilm(film); err != nil {
+		return nil, data.Film{}, err
+	}
+
+	if err := checkDuplicateName(films, id, film.Name); err != nil {
+		return nil, data.Film{}, err
+	}
+
+	added := data.Film{
+		ID:       id,
+		Name:     strings.TrimSpace(film.Name),
+		Genres:   genres.Clean(film.Genres),
+		Provider: strings.TrimSpace(film.Provider),
+		Runtime:  film.Runtime,
+	}
+	return append(films, added), added, nil
+}
+
+// UpdateFilm validates the film and replaces the name, genres, provider and runtime of the film
+// with the given ID. Whether the film has been watched is left unchanged.
+// It returns the updated films slice, the updated film, and an error.
+func UpdateFilm(films []data.Film, id int, film data.Film) ([]data.Film, data.Film, error) {
+	pos := findFilm(films, id)
+	if pos == -1 {
+		return nil, data.Film{}, fmt.Errorf("%w: id %d", ErrFilmNotFound, id)
+	}
+	if err := ValidateFilm(film); err != nil {
+		return nil, data.Film{}, err
+	}
+	if err := checkDuplicateName(films, id, film.Name); err != nil {
+		return nil, data.Film{}, err
+	}
+
+	f := &films[pos]
+	f.Name = strings.TrimSpace(film.Name)
+	f.Genres = genres.Clean(film.Genres)
+	f.Provider = strings.TrimSpace(film.Provider)
+	f.Runtime = film.Runtime
+	return films, *f, nil
+}
+
+// DeleteFilm removes the film with the given ID.
+// It returns the updated films slice, the removed film, and an error.
+func DeleteFilm(films []data.Film, id int) ([]data.Film, data.Film, error) {
+	pos := findFilm(films, id)
+	if pos == -1 {
+		return nil, data.Film{}, fmt.Errorf("%w: id %d", ErrFilmNotFound, id)
+	}
+
+	removed := films[pos]
+	remaining := make([]data.Film, 0, len(films)-1)
+	remaining = append(remaining, films[:pos]...)
+	remaining = append(remaining, films[pos+1:]...)
+
+	return remaining, removed, nil
+}
+
+// SetWatched marks the film with the given ID as watched at the given time, or as
+// unwatched if watched is false. A film that is already watched keeps its original time.
+// It returns the updated films slice, the updated film, and an error.
+func SetWatched(films []data.Film, id int, watched bool, watchedAt time.Time) ([]data.Film, data.Film, error) {
+	pos := findFilm(films, id)
+	if pos == -1 {
+		return nil, data.Film{}, fmt.Errorf("%w: id %d", ErrFilmNotFound, id)
+	}
+
+	f := &films[pos]
+	switch {
+	case !watched:
+		f.WatchedAt = nil
+	case f.WatchedAt == nil:
+		at := watchedAt
+		f.WatchedAt = &at
+	}
+
+	return films, *f, nil
+}
@@ -0,0 +1,223 @@
+package films
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+)
+
+func TestGetUnwatchedFilms(t *testing.T) {
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+	films := []data.Film{
+		{ID: 1, Name: "Film A", WatchedAt: &watchedAt},
+		{ID: 2, Name: "Film B"},
+	}
+
+	expected := []data.Film{{ID: 2, Name: "Film B"}}
+	if result := GetUnwatchedFilms(films); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestFilterFilms(t *testing.T) {
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+	films := []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"drama", "war"}, Provider: "Netflix"},
+		{ID: 2, Name: "Film B", Genres: []string{"comedy"}, Provider: "Disney+"},
+		{ID: 3, Name: "Film C", Genres: []string{"drama"}, Provider: "Disney+", WatchedAt: &watchedAt},
+		{ID: 4, Name: "Film D", Genres: []string{"war"}, Provider: "Sky Cinema"},
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []int
+	}{
+		{name: "no filter leaves out watched films", expected: []int{1, 2, 4}},
+		{name: "include watched", filter: Filter{IncludeWatched: true}, expected: []int{1, 2, 3, 4}},
+		{name: "any genre", filter: Filter{Genres: []string{"Comedy", "war"}}, expected: []int{1, 2, 4}},
+		{name: "all genres", filter: Filter{Genres: []string{"war", "drama"}, MatchAll: true}, expected: []int{1}},
+		{name: "provider", filter: Filter{Providers: []string{"disney+"}, IncludeWatched: true}, expected: []int{2, 3}},
+		{name: "genre and provider", filter: Filter{Genres: []string{"war"}, Providers: []string{"Netflix", "BBC iPlayer"}}, expected: []int{1}},
+		{name: "nothing matches", filter: Filter{Genres: []string{"horror"}}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []int
+			for _, f := range FilterFilms(films, tt.filter) {
+				ids = append(ids, f.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("expected ids %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestValidateFilm(t *testing.T) {
+	tests := []struct {
+		name        string
+		film        data.Film
+		expectError bool
+	}{
+		{name: "valid film", film: data.Film{Name: "Film A", Genres: []string{"war"}, Provider: "Netflix"}},
+		{name: "missing name", film: data.Film{Name: " ", Genres: []string{"war"}, Provider: "Netflix"}, expectError: true},
+		{name: "missing genre", film: data.Film{Name: "Film A", Provider: "Netflix"}, expectError: true},
+		{name: "missing provider", film: data.Film{Name: "Film A", Genres: []string{"war"}}, expectError: true},
+		{name: "negative runtime", film: data.Film{Name: "Film A", Genres: []string{"war"}, Provider: "Netflix", Runtime: -1}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFilm(tt.film)
+			if tt.expectError {
+				if !errors.Is(err, ErrInvalidFilm) {
+					t.Errorf("expected ErrInvalidFilm, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAddFilm(t *testing.T) {
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+	films := []data.Film{{ID: 4, Name: "Film A", Genres: []string{"war"}, Provider: "Netflix"}}
+
+	result, added, err := AddFilm(films, 5, data.Film{ID: 9, Name: " Film B ", Genres: []string{"comedy"}, Provider: "itvX", Runtime: 95, WatchedAt: &watchedAt})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := data.Film{ID: 5, Name: "Film B", Genres: []string{"comedy"}, Provider: "itvX", Runtime: 95}
+	if !reflect.DeepEqual(added, expected) {
+		t.Errorf("expected %+v, got %+v", expected, added)
+	}
+	if len(result) != 2 {
+		t.Errorf("expected 2 films, got %+v", result)
+	}
+
+	if _, _, err := AddFilm(films, 5, data.Film{Name: "film a", Genres: []string{"war"}, Provider: "Netflix"}); !errors.Is(err, ErrInvalidFilm) {
+		t.Errorf("expected duplicate name to be rejected, got %v", err)
+	}
+}
+
+func TestNextFilmID(t *testing.T) {
+	films := []data.Film{{ID: 4}, {ID: 2}}
+
+	if got := NextFilmID(0, films); got != 5 {
+		t.Errorf("expected 5, got %d", got)
+	}
+	// a deleted film had a higher ID than any left
+	if got := NextFilmID(9, films); got != 10 {
+		t.Errorf("expected 10, got %d", got)
+	}
+	if got := NextFilmID(0, nil); got != 1 {
+		t.Errorf("expected 1, got %d", got)
+	}
+}
+
+func TestUpdateFilm(t *testing.T) {
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		id          int
+		film        data.Film
+		expected    data.Film
+		expectedErr error
+	}{
+		{
+			name:     "keeps watched state",
+			id:       1,
+			film:     data.Film{Name: "Film A", Genres: []string{"history"}, Provider: "Prime Video"},
+			expected: data.Film{ID: 1, Name: "Film A", Genres: []string{"history"}, Provider: "Prime Video", WatchedAt: &watchedAt},
+		},
+		{
+			name:        "renaming to another film's name",
+			id:          1,
+			film:        data.Film{Name: "Film B", Genres: []string{"war"}, Provider: "Netflix"},
+			expectedErr: ErrInvalidFilm,
+		},
+		{
+			name:        "unknown id",
+			id:          9,
+			film:        data.Film{Name: "Film C", Genres: []string{"war"}, Provider: "Netflix"},
+			expectedErr: ErrFilmNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			films := []data.Film{
+				{ID: 1, Name: "Film A", Genres: []string{"war"}, Provider: "Netflix", WatchedAt: &watchedAt},
+				{ID: 2, Name: "Film B", Genres: []string{"war"}, Provider: "Netflix"},
+			}
+
+			_, updated, err := UpdateFilm(films, tt.id, tt.film)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(updated, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, updated)
+			}
+		})
+	}
+}
+
+func TestDeleteFilm(t *testing.T) {
+	films := []data.Film{{ID: 1, Name: "Film A"}, {ID: 2, Name: "Film B"}}
+
+	result, removed, err := DeleteFilm(films, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed.Name != "Film A" || !reflect.DeepEqual(result, []data.Film{{ID: 2, Name: "Film B"}}) {
+		t.Errorf("expected Film A to be removed, got removed %+v remaining %+v", removed, result)
+	}
+
+	if _, _, err := DeleteFilm(films, 9); !errors.Is(err, ErrFilmNotFound) {
+		t.Errorf("expected ErrFilmNotFound, got %v", err)
+	}
+}
+
+func TestSetWatched(t *testing.T) {
+	first := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+	later := time.Date(2025, 12, 1, 20, 30, 0, 0, time.UTC)
+	films := []data.Film{{ID: 1, Name: "Film A"}}
+
+	films, film, err := SetWatched(films, 1, true, first)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if film.WatchedAt == nil || !film.WatchedAt.Equal(first) {
+		t.Errorf("expected watched at %v, got %v", first, film.WatchedAt)
+	}
+
+	// marking again keeps the original time
+	films, film, _ = SetWatched(films, 1, true, later)
+	if !film.WatchedAt.Equal(first) {
+		t.Errorf("expected watched at %v, got %v", first, film.WatchedAt)
+	}
+
+	films, film, _ = SetWatched(films, 1, false, later)
+	if film.WatchedAt != nil || films[0].WatchedAt != nil {
+		t.Errorf("expected film to be unwatched, got %+v", films[0])
+	}
+
+	if _, _, err := SetWatched(films, 9, true, later); !errors.Is(err, ErrFilmNotFound) {
+		t.Errorf("expected ErrFilmNotFound, got %v", err)
+	}
+}
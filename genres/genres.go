@@ -0,0 +1,56 @@
+package genres
+
+import (
+	"slices"
+	"strings"
+)
+
+// Split splits a comma separated list of genres, such as "comedy, drama", into a cleaned list.
+func Split(s string) []string {
+	return Clean(strings.Split(s, ","))
+}
+
+// Join joins genres into a single comma separated string for display.
+func Join(genres []string) string {
+	return strings.Join(genres, ", ")
+}
+
+// Clean trims each genre and drops empty genres and case-insensitive duplicates, keeping the
+// first spelling seen. It returns nil if no genres are left.
+func Clean(genres []string) []string {
+	var cleaned []string
+	for _, g := range genres {
+		g = strings.TrimSpace(g)
+		if g != "" && !Contains(cleaned, g) {
+			cleaned = append(cleaned, g)
+		}
+	}
+	return cleaned
+}
+
+// Contains reports whether genres includes genre, ignoring case.
+func Contains(genres []string, genre string) bool {
+	genre = strings.TrimSpace(genre)
+	return slices.ContainsFunc(genres, func(g string) bool {
+		return strings.EqualFold(strings.TrimSpace(g), genre)
+	})
+}
+
+// Matches reports whether have includes any of want, or every one of want when all is true.
+// An empty want matches everything.
+func Matches(have, want []string, all bool) bool {
+	if len(want) == 0 {
+		return true
+	}
+
+	for _, w := range want {
+		found := Contains(have, w)
+		if all && !found {
+			return false
+		}
+		if !all && found {
+			return true
+		}
+	}
+	return all
+}
@@ -0,0 +1,53 @@
+package genres
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "single genre", input: "drama", expected: []string{"drama"}},
+		{name: "comma separated", input: "comedy, drama ,", expected: []string{"comedy", "drama"}},
+		{name: "duplicates ignore case", input: "Drama,drama", expected: []string{"Drama"}},
+		{name: "empty", input: " ", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Split(tt.input); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	have := []string{"Comedy", "Drama"}
+
+	tests := []struct {
+		name     string
+		want     []string
+		all      bool
+		expected bool
+	}{
+		{name: "nothing wanted", expected: true},
+		{name: "any of one", want: []string{"drama"}, expected: true},
+		{name: "any of several", want: []string{"horror", "comedy"}, expected: true},
+		{name: "any of none matching", want: []string{"horror"}, expected: false},
+		{name: "all present", want: []string{"drama", "comedy"}, all: true, expected: true},
+		{name: "all with one missing", want: []string{"drama", "horror"}, all: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Matches(have, tt.want, tt.all); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -2,15 +2,43 @@ package handlers
 
 import (
 	"fmt"
+	"math/rand/v2"
+	"time"
 
 	"what-to-watch/data"
 	"what-to-watch/db"
+	"what-to-watch/films"
+	"what-to-watch/history"
+	"what-to-watch/normalise"
+	"what-to-watch/planner"
+	"what-to-watch/recommend"
+	"what-to-watch/search"
 	"what-to-watch/shows"
+	"what-to-watch/stats"
+	"what-to-watch/subscriptions"
 )
 
+// Handlers holds the business logic functions shared by the CLI and HTTP server
+type Handlers struct {
+	store db.Store
+	norm  *normalise.Normaliser
+}
+
+// New creates Handlers that read and write through the given store, normalising genres
+// and providers with the default aliases
+func New(store db.Store) *Handlers {
+	return NewWithNormaliser(store, normalise.Default())
+}
+
+// NewWithNormaliser creates Handlers that read and write through the given store, normalising
+// the genres and providers of everything read, added or filtered on with norm
+func NewWithNormaliser(store db.Store, norm *normalise.Normaliser) *Handlers {
+	return &Handlers{store: normalisedStore{Store: store, norm: norm}, norm: norm}
+}
+
 // GetCurrentlyWatchingShows retrieves the list of currently watching shows
-func GetCurrentlyWatchingShows() ([]data.Show, error) {
-	s, err := db.ReadCurrentShows()
+func (h *Handlers) GetCurrentlyWatchingShows() ([]data.Show, error) {
+	s, err := h.store.ReadCurrentShows()
 	if err != nil {
 		return nil, fmt.Errorf("error reading shows: %w", err)
 	}
@@ -23,29 +51,208 @@ func GetCurrentlyWatchingShows() ([]data.Show, error) {
 	return cw, nil
 }
 
-// MarkShowWatched marks an episode as watched and updates the data store
-// idx is 1-based index from the currently watching list
-func MarkShowWatched(idx int) (bool, error) {
-	s, err := db.ReadCurrentShows()
+// GetCurrentlyWatchingShowsMatching retrieves the currently watching shows matching the filter,
+// with its genres and providers normalised. SubscribedOnly leaves out shows on providers without
+// an active subscription
+func (h *Handlers) GetCurrentlyWatchingShowsMatching(filter shows.Filter) ([]data.Show, error) {
+	cw, err := h.GetCurrentlyWatchingShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetCurrentlyWatchingShowsMatching: %w", err)
+	}
+
+	filter, ok, err := h.resolveShowFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("GetCurrentlyWatchingShowsMatching: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return shows.Matching(cw, filter), nil
+}
+
+// MarkShowWatched marks the next count episodes of the show with the given ID as watched,
+// updates the data store and records each episode in the watch history
+func (h *Handlers) MarkShowWatched(id, count int) (bool, error) {
+	s, err := h.store.ReadCurrentShows()
 	if err != nil {
 		return false, fmt.Errorf("error reading shows: %w", err)
 	}
 
-	updatedShows, isCompleted, err := shows.MarkEpisodeWatched(s, idx)
+	updatedShows, watched, isCompleted, err := shows.MarkEpisodesWatched(s, id, count)
 	if err != nil {
 		return false, fmt.Errorf("error updating show: %w", err)
 	}
 
-	if err := db.WriteCurrentShows(updatedShows); err != nil {
+	now := time.Now()
+	events := make([]data.WatchEvent, 0, len(watched))
+	for _, show := range watched {
+		event, err := history.NewEvent(show, now)
+		if err != nil {
+			return false, fmt.Errorf("error updating show: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if isCompleted {
+		completed, err := h.store.ReadCompletedShows()
+		if err != nil {
+			return false, fmt.Errorf("error reading completed shows: %w", err)
+		}
+
+		var updatedCompleted []data.Show
+		updatedShows, updatedCompleted = shows.ArchiveCompleted(updatedShows, completed, now)
+
+		// write the completed list first so a failure part way through
+		// leaves the show duplicated rather than lost
+		if err := h.store.WriteCompletedShows(updatedCompleted); err != nil {
+			return false, fmt.Errorf("error saving completed shows: %w", err)
+		}
+	}
+
+	if err := h.store.WriteCurrentShows(updatedShows); err != nil {
 		return false, fmt.Errorf("error saving updated shows: %w", err)
 	}
 
+	for _, event := range events {
+		if err := h.store.AppendWatchEvent(event); err != nil {
+			return false, fmt.Errorf("error saving watch history: %w", err)
+		}
+	}
+
 	return isCompleted, nil
 }
 
+// MarkShowWatchedByIndex marks the next count episodes of a show as watched and updates the data store
+// idx is 1-based index from the currently watching list returned by GetCurrentlyWatchingShowsMatching
+// with the same filter
+func (h *Handlers) MarkShowWatchedByIndex(idx, count int, filter shows.Filter) (bool, error) {
+	s, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return false, fmt.Errorf("error reading shows: %w", err)
+	}
+
+	filter, ok, err := h.resolveShowFilter(filter)
+	if err != nil {
+		return false, fmt.Errorf("error resolving show: %w", err)
+	}
+	if !ok {
+		return false, fmt.Errorf("error resolving show: %w: index %d out of range", shows.ErrShowNotFound, idx)
+	}
+
+	show, err := shows.WatchingShowAt(s, filter, idx)
+	if err != nil {
+		return false, fmt.Errorf("error resolving show: %w", err)
+	}
+
+	return h.MarkShowWatched(show.ID, count)
+}
+
+// SetShowProgress moves the currently watching show with the given ID straight to the
+// given series and episode, updates the data store and records where the show was in the
+// watch history, so that UndoLastWatched reverses the jump
+func (h *Handlers) SetShowProgress(id, series, episode int) (data.Show, error) {
+	s, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("SetShowProgress: error reading shows: %w", err)
+	}
+
+	updatedShows, previous, show, err := shows.SetProgress(s, id, series, episode)
+	if err != nil {
+		return data.Show{}, fmt.Errorf("SetShowProgress: error updating show: %w", err)
+	}
+
+	if err := h.store.WriteCurrentShows(updatedShows); err != nil {
+		return data.Show{}, fmt.Errorf("SetShowProgress: error saving updated shows: %w", err)
+	}
+
+	// a finished show left in the currently watching list has no episode to go back to
+	if previous.CurrentSeries != nil && previous.CurrentEpisode != nil {
+		event, err := history.NewJumpEvent(previous, time.Now())
+		if err != nil {
+			return data.Show{}, fmt.Errorf("SetShowProgress: error recording jump: %w", err)
+		}
+		if err := h.store.AppendWatchEvent(event); err != nil {
+			return data.Show{}, fmt.Errorf("SetShowProgress: error saving watch history: %w", err)
+		}
+	}
+
+	return show, nil
+}
+
+// UndoLastWatched reverses the most recent MarkShowWatched or SetShowProgress not already undone,
+// putting the show back on the episode that was marked or that it jumped from, and recording the
+// undo in the watch history.
+// A show that was completed by that episode is moved back into the currently watching list.
+func (h *Handlers) UndoLastWatched() (data.WatchEvent, error) {
+	events, err := h.store.ReadWatchHistory()
+	if err != nil {
+		return data.WatchEvent{}, fmt.Errorf("UndoLastWatched: error reading watch history: %w", err)
+	}
+	events = history.Effective(events)
+	if len(events) == 0 {
+		return data.WatchEvent{}, fmt.Errorf("UndoLastWatched: %w", history.ErrNothingToUndo)
+	}
+	last := events[len(events)-1]
+
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return data.WatchEvent{}, fmt.Errorf("UndoLastWatched: error reading current shows: %w", err)
+	}
+
+	completed, err := h.store.ReadCompletedShows()
+	if err != nil {
+		return data.WatchEvent{}, fmt.Errorf("UndoLastWatched: error reading completed shows: %w", err)
+	}
+
+	updatedCurrent, updatedCompleted, _, err := shows.RestoreProgress(current, completed, last.ShowID, last.Series, last.Episode)
+	if err != nil {
+		return data.WatchEvent{}, fmt.Errorf("UndoLastWatched: error restoring show: %w", err)
+	}
+
+	// write the currently watching list first so a failure part way through
+	// leaves the show duplicated rather than lost
+	if err := h.store.WriteCurrentShows(updatedCurrent); err != nil {
+		return data.WatchEvent{}, fmt.Errorf("UndoLastWatched: error saving current shows: %w", err)
+	}
+
+	if len(updatedCompleted) != len(completed) {
+		if err := h.store.WriteCompletedShows(updatedCompleted); err != nil {
+			return data.WatchEvent{}, fmt.Errorf("UndoLastWatched: error saving completed shows: %w", err)
+		}
+	}
+
+	if err := h.store.AppendWatchEvent(history.NewUndoEvent(last, time.Now())); err != nil {
+		return data.WatchEvent{}, fmt.Errorf("UndoLastWatched: error saving watch history: %w", err)
+	}
+
+	return last, nil
+}
+
+// GetWatchHistory retrieves the episodes watched at or after from and before to, oldest first.
+// A zero from or to leaves that end of the range open.
+func (h *Handlers) GetWatchHistory(from, to time.Time) ([]data.WatchEvent, error) {
+	events, err := h.readWatchHistory()
+	if err != nil {
+		return nil, fmt.Errorf("GetWatchHistory: %w", err)
+	}
+
+	return history.FilterByDate(events, from, to), nil
+}
+
+// GetCompletedShows retrieves the list of shows that have been watched to the end
+func (h *Handlers) GetCompletedShows() ([]data.Show, error) {
+	s, err := h.store.ReadCompletedShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetCompletedShows: error reading completed shows: %w", err)
+	}
+
+	return s, nil
+}
+
 // GetAllFilms retrieves the list of all films
-func GetAllFilms() ([]data.Film, error) {
-	films, err := db.ReadFilms()
+func (h *Handlers) GetAllFilms() ([]data.Film, error) {
+	films, err := h.store.ReadFilms()
 	if err != nil {
 		return nil, fmt.Errorf("error reading films: %w", err)
 	}
@@ -53,22 +260,574 @@ func GetAllFilms() ([]data.Film, error) {
 	return films, nil
 }
 
-// GetAvailableGenres retrieves a list of unique genres from all shows
-func GetAvailableGenres() ([]string, error) {
-	s, err := db.ReadShows()
+// GetUnwatchedFilms retrieves the films that haven't been watched yet
+func (h *Handlers) GetUnwatchedFilms() ([]data.Film, error) {
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return nil, fmt.Errorf("GetUnwatchedFilms: error reading films: %w", err)
+	}
+
+	return films.GetUnwatchedFilms(f), nil
+}
+
+// GetFilms retrieves the films matching the filter, with its genres and providers normalised.
+// SubscribedOnly leaves out films on providers without an active subscription
+func (h *Handlers) GetFilms(filter films.Filter) ([]data.Film, error) {
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return nil, fmt.Errorf("GetFilms: error reading films: %w", err)
+	}
+
+	filter.Genres = h.norm.Genres(filter.Genres)
+	filter.Providers = h.norm.Providers(filter.Providers)
+	if filter.SubscribedOnly {
+		providers, ok, err := h.subscribedProviders(filter.Providers)
+		if err != nil {
+			return nil, fmt.Errorf("GetFilms: %w", err)
+		}
+		if !ok {
+			return nil, nil
+		}
+		filter.Providers = providers
+	}
+
+	return films.FilterFilms(f, filter), nil
+}
+
+// AddFilm validates the film and adds it, unwatched, with a new ID
+func (h *Handlers) AddFilm(film data.Film) (data.Film, error) {
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return data.Film{}, fmt.Errorf("AddFilm: error reading films: %w", err)
+	}
+
+	last, err := h.store.LastFilmID()
+	if err != nil {
+		return data.Film{}, fmt.Errorf("AddFilm: error reading last film ID: %w", err)
+	}
+
+	updatedFilms, added, err := films.AddFilm(f, films.NextFilmID(last, f), h.norm.Film(film))
+	if err != nil {
+		return data.Film{}, fmt.Errorf("AddFilm: error adding film: %w", err)
+	}
+
+	if err := h.store.WriteFilms(updatedFilms); err != nil {
+		return data.Film{}, fmt.Errorf("AddFilm: error saving films: %w", err)
+	}
+
+	return added, nil
+}
+
+// UpdateFilm validates the film and replaces the details of the film with the given ID
+func (h *Handlers) UpdateFilm(id int, film data.Film) (data.Film, error) {
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return data.Film{}, fmt.Errorf("UpdateFilm: error reading films: %w", err)
+	}
+
+	updatedFilms, updated, err := films.UpdateFilm(f, id, h.norm.Film(film))
+	if err != nil {
+		return data.Film{}, fmt.Errorf("UpdateFilm: error updating film: %w", err)
+	}
+
+	if err := h.store.WriteFilms(updatedFilms); err != nil {
+		return data.Film{}, fmt.Errorf("UpdateFilm: error saving films: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteFilm removes the film with the given ID
+func (h *Handlers) DeleteFilm(id int) (data.Film, error) {
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return data.Film{}, fmt.Errorf("DeleteFilm: error reading films: %w", err)
+	}
+
+	updatedFilms, removed, err := films.DeleteFilm(f, id)
+	if err != nil {
+		return data.Film{}, fmt.Errorf("DeleteFilm: error deleting film: %w", err)
+	}
+
+	if err := h.store.WriteFilms(updatedFilms); err != nil {
+		return data.Film{}, fmt.Errorf("DeleteFilm: error saving films: %w", err)
+	}
+
+	return removed, nil
+}
+
+// SetFilmWatched marks the film with the given ID as watched now, or as unwatched
+func (h *Handlers) SetFilmWatched(id int, watched bool) (data.Film, error) {
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return data.Film{}, fmt.Errorf("SetFilmWatched: error reading films: %w", err)
+	}
+
+	updatedFilms, updated, err := films.SetWatched(f, id, watched, time.Now())
+	if err != nil {
+		return data.Film{}, fmt.Errorf("SetFilmWatched: error updating film: %w", err)
+	}
+
+	if err := h.store.WriteFilms(updatedFilms); err != nil {
+		return data.Film{}, fmt.Errorf("SetFilmWatched: error saving films: %w", err)
+	}
+
+	return updated, nil
+}
+
+// GetAvailableGenres retrieves every genre, sorted, with the number of unwatched shows,
+// shows being watched and unwatched films in each
+func (h *Handlers) GetAvailableGenres() ([]shows.GenreCount, error) {
+	catalogue, err := h.store.ReadShows()
 	if err != nil {
 		return nil, fmt.Errorf("GetAvailableGenres: error reading shows: %w", err)
 	}
 
-	return shows.GetUniqueGenres(s), nil
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetAvailableGenres: error reading current shows: %w", err)
+	}
+
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return nil, fmt.Errorf("GetAvailableGenres: error reading films: %w", err)
+	}
+
+	return shows.GetGenreCounts(catalogue, current, f), nil
+}
+
+// GetAvailableProviders retrieves every provider, sorted, with the number of unwatched shows,
+// shows being watched and unwatched films on each
+func (h *Handlers) GetAvailableProviders() ([]shows.ProviderCount, error) {
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetAvailableProviders: error reading shows: %w", err)
+	}
+
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetAvailableProviders: error reading current shows: %w", err)
+	}
+
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return nil, fmt.Errorf("GetAvailableProviders: error reading films: %w", err)
+	}
+
+	return shows.GetProviderCounts(catalogue, current, f), nil
+}
+
+// GetUnwatchedShowsMatching retrieves all unwatched shows matching the filter, with its genres
+// and providers normalised. SubscribedOnly leaves out shows on providers without an active subscription
+func (h *Handlers) GetUnwatchedShowsMatching(filter shows.Filter) ([]data.Show, error) {
+	s, err := h.store.ReadShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetUnwatchedShowsMatching: error reading shows: %w", err)
+	}
+
+	filter, ok, err := h.resolveShowFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("GetUnwatchedShowsMatching: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return shows.GetUnwatchedShowsMatching(s, filter), nil
+}
+
+// resolveShowFilter normalises the genres and providers of a show filter and narrows its
+// providers to those subscribed to when SubscribedOnly is set. It reports false when no
+// show can match
+func (h *Handlers) resolveShowFilter(filter shows.Filter) (shows.Filter, bool, error) {
+	filter.Genres = h.norm.Genres(filter.Genres)
+	filter.Providers = h.norm.Providers(filter.Providers)
+	if !filter.SubscribedOnly {
+		return filter, true, nil
+	}
+
+	providers, ok, err := h.subscribedProviders(filter.Providers)
+	if err != nil || !ok {
+		return filter, false, err
+	}
+	filter.Providers = providers
+	return filter, true, nil
+}
+
+// GetUnwatchedShows retrieves all shows from the catalogue that haven't been started
+func (h *Handlers) GetUnwatchedShows() ([]data.Show, error) {
+	s, err := h.store.ReadShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetUnwatchedShows: error reading shows: %w", err)
+	}
+
+	return shows.GetUnwatchedShows(s), nil
+}
+
+// StartWatchingShow moves the show with the given ID from the catalogue into the
+// currently watching list
+func (h *Handlers) StartWatchingShow(id int) (data.Show, error) {
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("StartWatchingShow: error reading shows: %w", err)
+	}
+
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("StartWatchingShow: error reading current shows: %w", err)
+	}
+
+	updatedCatalogue, updatedCurrent, started, err := shows.StartWatching(catalogue, current, id)
+	if err != nil {
+		return data.Show{}, fmt.Errorf("StartWatchingShow: error starting show: %w", err)
+	}
+
+	// write the currently watching list first so a failure part way through
+	// leaves the show duplicated rather than lost
+	if err := h.store.WriteCurrentShows(updatedCurrent); err != nil {
+		return data.Show{}, fmt.Errorf("StartWatchingShow: error saving current shows: %w", err)
+	}
+
+	if err := h.store.WriteShows(updatedCatalogue); err != nil {
+		return data.Show{}, fmt.Errorf("StartWatchingShow: error saving shows: %w", err)
+	}
+
+	return started, nil
+}
+
+// AddShow validates the show and adds it to the catalogue with a new ID that is unique
+// across the catalogue, currently watching and completed shows
+func (h *Handlers) AddShow(show data.Show) (data.Show, error) {
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("AddShow: error reading shows: %w", err)
+	}
+
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("AddShow: error reading current shows: %w", err)
+	}
+
+	completed, err := h.store.ReadCompletedShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("AddShow: error reading completed shows: %w", err)
+	}
+
+	last, err := h.store.LastShowID()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("AddShow: error reading last show ID: %w", err)
+	}
+
+	id := shows.NextShowID(last, catalogue, current, completed)
+	updatedCatalogue, added, err := shows.AddShow(catalogue, current, completed, id, h.norm.Show(show))
+	if err != nil {
+		return data.Show{}, fmt.Errorf("AddShow: error adding show: %w", err)
+	}
+
+	if err := h.store.WriteShows(updatedCatalogue); err != nil {
+		return data.Show{}, fmt.Errorf("AddShow: error saving shows: %w", err)
+	}
+
+	return added, nil
+}
+
+// UpdateShow validates the show and replaces the details of the catalogue entry with the given ID
+func (h *Handlers) UpdateShow(id int, show data.Show) (data.Show, error) {
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("UpdateShow: error reading shows: %w", err)
+	}
+
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("UpdateShow: error reading current shows: %w", err)
+	}
+
+	completed, err := h.store.ReadCompletedShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("UpdateShow: error reading completed shows: %w", err)
+	}
+
+	updatedCatalogue, updated, err := shows.UpdateShow(catalogue, current, completed, id, h.norm.Show(show))
+	if err != nil {
+		return data.Show{}, fmt.Errorf("UpdateShow: error updating show: %w", err)
+	}
+
+	if err := h.store.WriteShows(updatedCatalogue); err != nil {
+		return data.Show{}, fmt.Errorf("UpdateShow: error saving shows: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteShow removes the show with the given ID from the catalogue
+func (h *Handlers) DeleteShow(id int) (data.Show, error) {
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return data.Show{}, fmt.Errorf("DeleteShow: error reading shows: %w", err)
+	}
+
+	updatedCatalogue, removed, err := shows.DeleteShow(catalogue, id)
+	if err != nil {
+		return data.Show{}, fmt.Errorf("DeleteShow: error deleting show: %w", err)
+	}
+
+	if err := h.store.WriteShows(updatedCatalogue); err != nil {
+		return data.Show{}, fmt.Errorf("DeleteShow: error saving shows: %w", err)
+	}
+
+	return removed, nil
+}
+
+// GetRecommendations ranks the shows in progress, unstarted shows and unwatched films
+// against the preferences and the watch history, returning at most limit of them.
+// SubscribedOnly leaves out anything on a provider without an active subscription
+func (h *Handlers) GetRecommendations(prefs recommend.Preferences, limit int) ([]recommend.Recommendation, error) {
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetRecommendations: error reading current shows: %w", err)
+	}
+
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetRecommendations: error reading shows: %w", err)
+	}
+
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return nil, fmt.Errorf("GetRecommendations: error reading films: %w", err)
+	}
+
+	events, err := h.readWatchHistory()
+	if err != nil {
+		return nil, fmt.Errorf("GetRecommendations: %w", err)
+	}
+
+	prefs.Genres = h.norm.Genres(prefs.Genres)
+	prefs.Providers = h.norm.Providers(prefs.Providers)
+	if prefs.SubscribedOnly {
+		providers, ok, err := h.subscribedProviders(prefs.Providers)
+		if err != nil {
+			return nil, fmt.Errorf("GetRecommendations: %w", err)
+		}
+		if !ok {
+			return nil, nil
+		}
+		prefs.Providers = providers
+	}
+
+	lib := recommend.Library{Current: current, Catalogue: catalogue, Films: f, History: events}
+	return recommend.Recommend(lib, prefs, time.Now(), limit), nil
+}
+
+// RandomPick picks a show in progress, unstarted show or unwatched film matching the filter at random.
+// Passing a seeded rng makes the pick repeatable; nil uses the shared random source
+func (h *Handlers) RandomPick(filter recommend.Filter, rng *rand.Rand) (recommend.Pick, error) {
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return recommend.Pick{}, fmt.Errorf("RandomPick: error reading current shows: %w", err)
+	}
+
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return recommend.Pick{}, fmt.Errorf("RandomPick: error reading shows: %w", err)
+	}
+
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return recommend.Pick{}, fmt.Errorf("RandomPick: error reading films: %w", err)
+	}
+
+	filter.Genre = h.norm.Genre(filter.Genre)
+	filter.Provider = h.norm.Provider(filter.Provider)
+
+	lib := recommend.Library{Current: current, Catalogue: catalogue, Films: f}
+	pick, err := recommend.Random(lib, filter, rng)
+	if err != nil {
+		return recommend.Pick{}, fmt.Errorf("RandomPick: %w", err)
+	}
+
+	return pick, nil
+}
+
+// PlanViewing proposes up to limit combinations of the next episodes of the shows being watched
+// and unwatched films that fit within the given number of minutes
+func (h *Handlers) PlanViewing(minutes, limit int) ([]planner.Plan, error) {
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return nil, fmt.Errorf("PlanViewing: error reading current shows: %w", err)
+	}
+
+	cw, err := shows.GetCurrentlyWatching(current)
+	if err != nil {
+		return nil, fmt.Errorf("PlanViewing: error getting currently watching shows: %w", err)
+	}
+
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return nil, fmt.Errorf("PlanViewing: error reading films: %w", err)
+	}
+
+	plans, err := planner.Propose(cw, f, minutes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("PlanViewing: %w", err)
+	}
+
+	return plans, nil
+}
+
+// GetStats aggregates viewing statistics from every show list, the films and the watch history
+func (h *Handlers) GetStats() (stats.Stats, error) {
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return stats.Stats{}, fmt.Errorf("GetStats: error reading shows: %w", err)
+	}
+
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return stats.Stats{}, fmt.Errorf("GetStats: error reading current shows: %w", err)
+	}
+
+	completed, err := h.store.ReadCompletedShows()
+	if err != nil {
+		return stats.Stats{}, fmt.Errorf("GetStats: error reading completed shows: %w", err)
+	}
+
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return stats.Stats{}, fmt.Errorf("GetStats: error reading films: %w", err)
+	}
+
+	events, err := h.readWatchHistory()
+	if err != nil {
+		return stats.Stats{}, fmt.Errorf("GetStats: %w", err)
+	}
+
+	lib := stats.Library{Catalogue: catalogue, Current: current, Completed: completed, Films: f, History: events}
+	return stats.Compute(lib, time.Now()), nil
+}
+
+// Search finds the shows in the catalogue, shows being watched and films whose name, genres or
+// provider match the query, allowing for typos, returning at most limit of them, best match first
+func (h *Handlers) Search(query string, limit int) ([]search.Result, error) {
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return nil, fmt.Errorf("Search: error reading shows: %w", err)
+	}
+
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return nil, fmt.Errorf("Search: error reading current shows: %w", err)
+	}
+
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return nil, fmt.Errorf("Search: error reading films: %w", err)
+	}
+
+	results := search.Search(catalogue, current, f, query)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// GetSubscriptions retrieves the streaming subscriptions
+func (h *Handlers) GetSubscriptions() ([]data.Subscription, error) {
+	subs, err := h.store.ReadSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("GetSubscriptions: error reading subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// SetSubscription validates the subscription and saves it, replacing any existing
+// subscription to the same provider
+func (h *Handlers) SetSubscription(sub data.Subscription) (data.Subscription, error) {
+	subs, err := h.store.ReadSubscriptions()
+	if err != nil {
+		return data.Subscription{}, fmt.Errorf("SetSubscription: error reading subscriptions: %w", err)
+	}
+
+	sub.Provider = h.norm.Provider(sub.Provider)
+	updatedSubs, saved, err := subscriptions.Set(subs, sub)
+	if err != nil {
+		return data.Subscription{}, fmt.Errorf("SetSubscription: error saving subscription: %w", err)
+	}
+
+	if err := h.store.WriteSubscriptions(updatedSubs); err != nil {
+		return data.Subscription{}, fmt.Errorf("SetSubscription: error saving subscriptions: %w", err)
+	}
+
+	return saved, nil
+}
+
+// DeleteSubscription removes the subscription to the given provider
+func (h *Handlers) DeleteSubscription(provider string) (data.Subscription, error) {
+	subs, err := h.store.ReadSubscriptions()
+	if err != nil {
+		return data.Subscription{}, fmt.Errorf("DeleteSubscription: error reading subscriptions: %w", err)
+	}
+
+	updatedSubs, removed, err := subscriptions.Remove(subs, h.norm.Provider(provider))
+	if err != nil {
+		return data.Subscription{}, fmt.Errorf("DeleteSubscription: error deleting subscription: %w", err)
+	}
+
+	if err := h.store.WriteSubscriptions(updatedSubs); err != nil {
+		return data.Subscription{}, fmt.Errorf("DeleteSubscription: error saving subscriptions: %w", err)
+	}
+
+	return removed, nil
+}
+
+// GetSubscriptionReport counts how much of the backlog is on each provider, marking those
+// currently subscribed to, with the provider that unlocks the most first
+func (h *Handlers) GetSubscriptionReport() ([]subscriptions.Unlock, error) {
+	catalogue, err := h.store.ReadShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetSubscriptionReport: error reading shows: %w", err)
+	}
+
+	current, err := h.store.ReadCurrentShows()
+	if err != nil {
+		return nil, fmt.Errorf("GetSubscriptionReport: error reading current shows: %w", err)
+	}
+
+	f, err := h.store.ReadFilms()
+	if err != nil {
+		return nil, fmt.Errorf("GetSubscriptionReport: error reading films: %w", err)
+	}
+
+	subs, err := h.store.ReadSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("GetSubscriptionReport: error reading subscriptions: %w", err)
+	}
+
+	return subscriptions.Report(catalogue, current, f, subs, time.Now()), nil
+}
+
+// readWatchHistory reads the episodes watched, leaving out jumps and the events that have been undone
+func (h *Handlers) readWatchHistory() ([]data.WatchEvent, error) {
+	events, err := h.store.ReadWatchHistory()
+	if err != nil {
+		return nil, fmt.Errorf("error reading watch history: %w", err)
+	}
+
+	return history.Watched(events), nil
 }
 
-// GetUnwatchedShowsByGenre retrieves all unwatched shows for a given genre
-func GetUnwatchedShowsByGenre(genre string) ([]data.Show, error) {
-	s, err := db.ReadShows()
+// subscribedProviders narrows the wanted providers, or every provider when none are wanted, to
+// those with an active subscription. It reports false when no wanted provider is subscribed to,
+// so nothing can match
+func (h *Handlers) subscribedProviders(want []string) ([]string, bool, error) {
+	subs, err := h.store.ReadSubscriptions()
 	if err != nil {
-		return nil, fmt.Errorf("GetUnwatchedShowsByGenre: error reading shows: %w", err)
+		return nil, false, fmt.Errorf("error reading subscriptions: %w", err)
 	}
 
-	return shows.GetUnwatchedShowsByGenre(s, genre), nil
+	restricted := subscriptions.Restrict(want, subscriptions.ActiveProviders(subs, time.Now()))
+	return restricted, len(restricted) > 0, nil
 }
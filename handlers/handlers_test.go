@@ -0,0 +1,1036 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+	"what-to-watch/db"
+	"what-to-watch/films"
+	"what-to-watch/history"
+	"what-to-watch/normalise"
+	"what-to-watch/planner"
+	"what-to-watch/recommend"
+	"what-to-watch/search"
+	"what-to-watch/shows"
+	"what-to-watch/stats"
+	"what-to-watch/subscriptions"
+)
+
+func TestGetCurrentlyWatchingShows(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+		{ID: 2, Name: "Show B", Episodes: []int{10}},
+	}
+
+	result, err := New(store).GetCurrentlyWatchingShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2), Series: "1", Episode: "2",
+			Progress: &data.Progress{Watched: 1, Remaining: 9, Total: 10, PercentComplete: 10}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestMarkShowWatched(t *testing.T) {
+	tests := []struct {
+		name              string
+		current           []data.Show
+		id                int
+		expectedCurrent   []data.Show
+		expectedCompleted []string
+		expectedFinish    bool
+		expectError       bool
+	}{
+		{
+			name: "advances episode",
+			current: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			id: 1,
+			expectedCurrent: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+			},
+			expectedCompleted: nil,
+			expectedFinish:    false,
+		},
+		{
+			name: "finishing a show archives it",
+			current: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+				{ID: 2, Name: "Show B", Episodes: []int{2}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			id: 2,
+			expectedCurrent: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			expectedCompleted: []string{"Show B"},
+			expectedFinish:    true,
+		},
+		{
+			name: "unknown id leaves store untouched",
+			current: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			id: 3,
+			expectedCurrent: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := db.NewMemoryStore()
+			store.CurrentShows = tt.current
+
+			finish, err := New(store).MarkShowWatched(tt.id, 1)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("expected error but got none")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if finish != tt.expectedFinish {
+				t.Errorf("expected finish %v, got %v", tt.expectedFinish, finish)
+			}
+
+			if !reflect.DeepEqual(store.CurrentShows, tt.expectedCurrent) {
+				t.Errorf("expected current %+v, got %+v", tt.expectedCurrent, store.CurrentShows)
+			}
+
+			var completed []string
+			for _, s := range store.CompletedShows {
+				if s.CompletedAt == nil {
+					t.Errorf("expected completed show %s to have a completion time", s.Name)
+				}
+				completed = append(completed, s.Name)
+			}
+			if !reflect.DeepEqual(completed, tt.expectedCompleted) {
+				t.Errorf("expected completed %v, got %v", tt.expectedCompleted, completed)
+			}
+		})
+	}
+}
+
+func TestMarkShowWatchedBinge(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{3, 3}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+	}
+
+	h := New(store)
+	finish, err := h.MarkShowWatched(1, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if finish {
+		t.Errorf("expected show not to be finished")
+	}
+
+	expected := []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{3, 3}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(2)},
+	}
+	if !reflect.DeepEqual(store.CurrentShows, expected) {
+		t.Errorf("expected %+v, got %+v", expected, store.CurrentShows)
+	}
+
+	// every episode is recorded so undo steps back one episode at a time
+	var watched []string
+	for _, e := range store.WatchHistory {
+		watched = append(watched, fmt.Sprintf("S%dE%d", e.Series, e.Episode))
+	}
+	if expected := []string{"S1E2", "S1E3", "S2E1"}; !reflect.DeepEqual(watched, expected) {
+		t.Errorf("expected history %v, got %v", expected, watched)
+	}
+
+	// a count past the end finishes and archives the show
+	finish, err = h.MarkShowWatched(1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !finish {
+		t.Errorf("expected show to be finished")
+	}
+	if len(store.CurrentShows) != 0 || len(store.CompletedShows) != 1 {
+		t.Errorf("expected show to be archived, got current %+v completed %+v", store.CurrentShows, store.CompletedShows)
+	}
+	if len(store.WatchHistory) != 5 {
+		t.Errorf("expected 5 history events, got %d", len(store.WatchHistory))
+	}
+}
+
+func TestMarkShowWatchedRecordsHistory(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{2}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+
+	h := New(store)
+	for range 2 {
+		if _, err := h.MarkShowWatched(1, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// a failed mark must not be recorded
+	if _, err := h.MarkShowWatched(1, 1); err == nil {
+		t.Fatalf("expected error marking a completed show")
+	}
+
+	events, err := h.GetWatchHistory(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var watched []string
+	for _, e := range events {
+		if e.ShowID != 1 || e.Show != "Show A" || e.WatchedAt.IsZero() {
+			t.Errorf("unexpected event %+v", e)
+		}
+		watched = append(watched, fmt.Sprintf("S%dE%d", e.Series, e.Episode))
+	}
+
+	expected := []string{"S1E1", "S1E2"}
+	if !reflect.DeepEqual(watched, expected) {
+		t.Errorf("expected %v, got %v", expected, watched)
+	}
+}
+
+func TestSetShowProgress(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10, 10, 10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+	}
+
+	h := New(store)
+	if _, err := h.SetShowProgress(1, 3, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10, 10, 10}, CurrentSeries: intPtr(3), CurrentEpisode: intPtr(5)},
+	}
+	if !reflect.DeepEqual(store.CurrentShows, expected) {
+		t.Errorf("expected %+v, got %+v", expected, store.CurrentShows)
+	}
+
+	// an out of range position leaves the store untouched
+	if _, err := h.SetShowProgress(1, 4, 1); !errors.Is(err, shows.ErrInvalidProgress) {
+		t.Errorf("expected ErrInvalidProgress, got %v", err)
+	}
+	if !reflect.DeepEqual(store.CurrentShows, expected) {
+		t.Errorf("expected %+v, got %+v", expected, store.CurrentShows)
+	}
+}
+
+func TestUndoLastWatchedReversesJump(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10, 10, 10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+
+	h := New(store)
+	if _, err := h.MarkShowWatched(1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := h.SetShowProgress(1, 3, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := h.MarkShowWatched(1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the jump is not an episode watched
+	watched, err := h.GetWatchHistory(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(watched) != 2 {
+		t.Errorf("expected 2 episodes watched, got %+v", watched)
+	}
+
+	// undo the episode after the jump, then the jump itself, then the episode before it
+	steps := []struct {
+		kind    data.EventKind
+		series  int
+		episode int
+	}{
+		{kind: data.EventWatched, series: 3, episode: 5},
+		{kind: data.EventJump, series: 1, episode: 2},
+		{kind: data.EventWatched, series: 1, episode: 1},
+	}
+	for _, step := range steps {
+		event, err := h.UndoLastWatched()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if event.Kind != step.kind {
+			t.Errorf("expected to undo a %q event, got %q", step.kind, event.Kind)
+		}
+
+		show := store.CurrentShows[0]
+		if *show.CurrentSeries != step.series || *show.CurrentEpisode != step.episode {
+			t.Errorf("expected Show A on S%dE%d, got S%dE%d", step.series, step.episode, *show.CurrentSeries, *show.CurrentEpisode)
+		}
+	}
+
+	if _, err := h.UndoLastWatched(); !errors.Is(err, history.ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestUndoLastWatched(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{1, 2}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+		{ID: 2, Name: "Show B", Episodes: []int{1}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+	original := slices.Clone(store.CurrentShows)
+
+	h := New(store)
+	if _, err := h.MarkShowWatched(1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if finish, err := h.MarkShowWatched(2, 1); err != nil || !finish {
+		t.Fatalf("expected Show B to finish, got finish=%v err=%v", finish, err)
+	}
+
+	// undo the completion of Show B, then the series rollover of Show A
+	for _, expected := range []string{"Show B", "Show A"} {
+		event, err := h.UndoLastWatched()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if event.Show != expected {
+			t.Errorf("expected to undo %s, got %s", expected, event.Show)
+		}
+	}
+
+	slices.SortFunc(store.CurrentShows, func(a, b data.Show) int { return a.ID - b.ID })
+	if !reflect.DeepEqual(store.CurrentShows, original) {
+		t.Errorf("expected current %+v, got %+v", original, store.CurrentShows)
+	}
+	if len(store.CompletedShows) != 0 {
+		t.Errorf("expected no completed shows, got %+v", store.CompletedShows)
+	}
+	// the history is only appended to, with each undo recorded after the events it undoes
+	if len(store.WatchHistory) != 4 || store.WatchHistory[2].Kind != data.EventUndo || store.WatchHistory[3].Kind != data.EventUndo {
+		t.Errorf("expected two watch events followed by two undo events, got %+v", store.WatchHistory)
+	}
+	if events, err := h.GetWatchHistory(time.Time{}, time.Time{}); err != nil || len(events) != 0 {
+		t.Errorf("expected no watched episodes, got %+v err=%v", events, err)
+	}
+
+	if _, err := h.UndoLastWatched(); !errors.Is(err, history.ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestGetWatchHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 11, d, 20, 0, 0, 0, time.UTC) }
+
+	store := db.NewMemoryStore()
+	store.WatchHistory = []data.WatchEvent{
+		{ShowID: 1, Show: "Show A", Series: 1, Episode: 1, WatchedAt: day(1)},
+		{ShowID: 1, Show: "Show A", Series: 1, Episode: 2, WatchedAt: day(2)},
+		{ShowID: 2, Show: "Show B", Series: 2, Episode: 5, WatchedAt: day(3)},
+	}
+
+	result, err := New(store).GetWatchHistory(day(2), day(3))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []data.WatchEvent{store.WatchHistory[1]}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestGetRecommendations(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"Drama"}, Provider: "Netflix", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+	}
+	store.Shows = []data.Show{
+		{ID: 2, Name: "Show B", Genres: []string{"Comedy"}, Provider: "Netflix", Episodes: []int{20}},
+		{ID: 3, Name: "Show C", Genres: []string{"Comedy"}, Provider: "BBC iPlayer", Episodes: []int{6}},
+	}
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"Comedy"}, Provider: "Netflix"},
+	}
+
+	result, err := New(store).GetRecommendations(recommend.Preferences{Genres: []string{"Comedy"}, Providers: []string{"Netflix"}}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, r := range result {
+		names = append(names, r.Name)
+	}
+	expected := []string{"Film A", "Show B"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestRandomPick(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"Drama"}, Provider: "Netflix", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+	}
+	store.Shows = []data.Show{
+		{ID: 2, Name: "Show B", Genres: []string{"Comedy"}, Provider: "Netflix", Episodes: []int{20}},
+	}
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"Comedy"}, Provider: "Netflix"},
+	}
+	h := New(store)
+
+	first, err := h.RandomPick(recommend.Filter{Genre: "comedy"}, rand.New(rand.NewPCG(7, 7)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(first.Genres, []string{"comedy"}) {
+		t.Errorf("expected a comedy, got %+v", first)
+	}
+
+	again, err := h.RandomPick(recommend.Filter{Genre: "comedy"}, rand.New(rand.NewPCG(7, 7)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(again, first) {
+		t.Errorf("expected the same seed to pick %+v, got %+v", first, again)
+	}
+
+	if _, err := h.RandomPick(recommend.Filter{Kind: recommend.KindFilm, InProgressOnly: true}, nil); !errors.Is(err, recommend.ErrNoMatches) {
+		t.Errorf("expected ErrNoMatches, got %v", err)
+	}
+}
+
+func TestPlanViewing(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10}, Runtime: 45, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+		{ID: 2, Name: "Show B", Episodes: []int{10}, Runtime: 20},
+	}
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Runtime: 120},
+	}
+	h := New(store)
+
+	result, err := h.PlanViewing(90, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// shows that haven't been started and films that are too long are left out
+	expected := []planner.Plan{
+		{Items: []planner.Item{
+			{Kind: recommend.KindShow, ID: 1, Name: "Show A", Series: 1, Episode: 2, Episodes: 2, Minutes: 90},
+		}, Minutes: 90},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+
+	if _, err := h.PlanViewing(0, 5); !errors.Is(err, planner.ErrInvalidBudget) {
+		t.Errorf("expected ErrInvalidBudget, got %v", err)
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	now := time.Now()
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"Drama"}, Provider: "Netflix", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+	}
+	store.CompletedShows = []data.Show{
+		{ID: 2, Name: "Show B", Genres: []string{"Comedy"}, Provider: "itvX", Episodes: []int{6}, CompletedAt: &now},
+	}
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"Comedy"}, Provider: "Netflix", WatchedAt: &now},
+	}
+	store.WatchHistory = []data.WatchEvent{
+		{ShowID: 1, Series: 1, Episode: 1, WatchedAt: now},
+		{ShowID: 1, Series: 1, Episode: 2, WatchedAt: now},
+	}
+
+	result, err := New(store).GetStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.EpisodesWatched != 2 || result.FilmsWatched != 1 || result.CurrentStreak != 1 {
+		t.Errorf("unexpected totals %+v", result)
+	}
+	if got := result.EpisodesPerWeek[len(result.EpisodesPerWeek)-1].Episodes; got != 2 {
+		t.Errorf("expected 2 episodes this week, got %d", got)
+	}
+	expectedProviders := []stats.Count{{Name: "Netflix", Count: 3}}
+	if !reflect.DeepEqual(result.BusiestProviders, expectedProviders) {
+		t.Errorf("expected providers %+v, got %+v", expectedProviders, result.BusiestProviders)
+	}
+	if len(result.CompletedPerMonth) != 1 || result.CompletedPerMonth[0].Shows != 1 {
+		t.Errorf("expected 1 show completed this month, got %+v", result.CompletedPerMonth)
+	}
+}
+
+func TestMarkShowWatchedByIndex(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10}},
+		{ID: 2, Name: "Show B", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+	}
+
+	// index 1 is the first show in the currently watching list, which skips finished Show A
+	if _, err := New(store).MarkShowWatchedByIndex(1, 1, shows.Filter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10}},
+		{ID: 2, Name: "Show B", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+	}
+	if !reflect.DeepEqual(store.CurrentShows, expected) {
+		t.Errorf("expected %+v, got %+v", expected, store.CurrentShows)
+	}
+
+	for _, idx := range []int{0, 2} {
+		if _, err := New(store).MarkShowWatchedByIndex(idx, 1, shows.Filter{}); !errors.Is(err, shows.ErrShowNotFound) {
+			t.Errorf("expected ErrShowNotFound for index %d, got %v", idx, err)
+		}
+	}
+
+	// with no subscriptions nothing is listed, so no index resolves
+	if _, err := New(store).MarkShowWatchedByIndex(1, 1, shows.Filter{SubscribedOnly: true}); !errors.Is(err, shows.ErrShowNotFound) {
+		t.Errorf("expected ErrShowNotFound for a subscribed index, got %v", err)
+	}
+}
+
+func TestStartWatchingShow(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{6}},
+		{ID: 2, Name: "Show B", Episodes: []int{8}},
+	}
+
+	started, err := New(store).StartWatchingShow(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := data.Show{ID: 2, Name: "Show B", Episodes: []int{8}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)}
+	if !reflect.DeepEqual(started, expected) {
+		t.Errorf("expected started %+v, got %+v", expected, started)
+	}
+
+	if !reflect.DeepEqual(store.CurrentShows, []data.Show{expected}) {
+		t.Errorf("expected current %+v, got %+v", []data.Show{expected}, store.CurrentShows)
+	}
+
+	if !reflect.DeepEqual(store.Shows, []data.Show{{ID: 1, Name: "Show A", Episodes: []int{6}}}) {
+		t.Errorf("expected catalogue to only contain Show A, got %+v", store.Shows)
+	}
+}
+
+func TestLegacyShowsAreAssignedIDs(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{Name: "Show A", Episodes: []int{6}},
+	}
+	store.CurrentShows = []data.Show{
+		{ID: 4, Name: "Show B", Episodes: []int{8}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+		{Name: "Show C", Episodes: []int{8}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+
+	h := New(store)
+	unwatched, err := h.GetUnwatchedShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	watching, err := h.GetCurrentlyWatchingShows()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ids := map[string]int{}
+	for _, s := range append(unwatched, watching...) {
+		ids[s.Name] = s.ID
+	}
+
+	expected := map[string]int{"Show A": 5, "Show B": 4, "Show C": 6}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected ids %v, got %v", expected, ids)
+	}
+}
+
+func TestCatalogueCRUD(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+	}
+	store.CurrentShows = []data.Show{
+		{ID: 4, Name: "Show B", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+
+	h := New(store)
+
+	// new IDs must not clash with shows outside the catalogue
+	added, err := h.AddShow(data.Show{Name: "Show C", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8, 8}})
+	if err != nil {
+		t.Fatalf("unexpected error adding: %v", err)
+	}
+	if added.ID != 5 {
+		t.Errorf("expected id 5, got %d", added.ID)
+	}
+
+	if _, err := h.UpdateShow(5, data.Show{Name: "Show C", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8, 10}}); err != nil {
+		t.Fatalf("unexpected error updating: %v", err)
+	}
+
+	if _, err := h.DeleteShow(1); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+
+	expected := []data.Show{
+		{ID: 5, Name: "Show C", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8, 10}},
+	}
+	if !reflect.DeepEqual(store.Shows, expected) {
+		t.Errorf("expected catalogue %+v, got %+v", expected, store.Shows)
+	}
+
+	// invalid shows are rejected without touching the store
+	if _, err := h.AddShow(data.Show{Name: "Show D"}); !errors.Is(err, shows.ErrInvalidShow) {
+		t.Errorf("expected ErrInvalidShow, got %v", err)
+	}
+	if _, err := h.AddShow(data.Show{Name: "Show B", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}}); !errors.Is(err, shows.ErrInvalidShow) {
+		t.Errorf("expected a show already being watched to be rejected, got %v", err)
+	}
+	if _, err := h.DeleteShow(4); !errors.Is(err, shows.ErrShowNotFound) {
+		t.Errorf("expected currently watching show not to be deletable from the catalogue, got %v", err)
+	}
+	if !reflect.DeepEqual(store.Shows, expected) {
+		t.Errorf("expected catalogue %+v, got %+v", expected, store.Shows)
+	}
+}
+
+func TestGetFilms(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"Sci-Fi"}, Provider: "netflix"},
+		{ID: 2, Name: "Film B", Genres: []string{"science fiction"}, Provider: "Disney+"},
+		{ID: 3, Name: "Film C", Genres: []string{"comedy"}, Provider: "Netflix"},
+	}
+
+	result, err := New(store).GetFilms(films.Filter{Genres: []string{"SCIFI"}, Providers: []string{"NETFLIX"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []data.Film{{ID: 1, Name: "Film A", Genres: []string{"science fiction"}, Provider: "Netflix"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestFilmCRUD(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"war"}, Provider: "Netflix"},
+		{ID: 2, Name: "Film B", Genres: []string{"comedy"}, Provider: "itvX"},
+	}
+
+	h := New(store)
+
+	added, err := h.AddFilm(data.Film{Name: "Film C", Genres: []string{"crime"}, Provider: "Netflix"})
+	if err != nil {
+		t.Fatalf("unexpected error adding: %v", err)
+	}
+	if added.ID != 3 {
+		t.Errorf("expected id 3, got %d", added.ID)
+	}
+
+	if _, err := h.UpdateFilm(3, data.Film{Name: "Film C", Genres: []string{"thriller"}, Provider: "Netflix"}); err != nil {
+		t.Fatalf("unexpected error updating: %v", err)
+	}
+	if _, err := h.DeleteFilm(2); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+
+	watched, err := h.SetFilmWatched(1, true)
+	if err != nil {
+		t.Fatalf("unexpected error marking watched: %v", err)
+	}
+	if watched.WatchedAt == nil {
+		t.Errorf("expected film to have a watched time")
+	}
+
+	unwatched, err := h.GetUnwatchedFilms()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []data.Film{{ID: 3, Name: "Film C", Genres: []string{"thriller"}, Provider: "Netflix"}}
+	if !reflect.DeepEqual(unwatched, expected) {
+		t.Errorf("expected unwatched %+v, got %+v", expected, unwatched)
+	}
+
+	all, err := h.GetAllFilms()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("expected 2 films in total, got %+v", all)
+	}
+
+	if _, err := h.AddFilm(data.Film{Name: "Film D"}); !errors.Is(err, films.ErrInvalidFilm) {
+		t.Errorf("expected ErrInvalidFilm, got %v", err)
+	}
+}
+
+func TestAddShowDoesNotReuseDeletedID(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+		{ID: 2, Name: "Show B", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8}},
+	}
+	store.WatchHistory = []data.WatchEvent{{ShowID: 2, Show: "Show B", Series: 1, Episode: 1}}
+	h := New(store)
+
+	if _, err := h.DeleteShow(2); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+
+	// the watch history still refers to show 2, so a new show must not take its ID
+	added, err := h.AddShow(data.Show{Name: "Show C", Genres: []string{"crime"}, Provider: "Netflix", Episodes: []int{4}})
+	if err != nil {
+		t.Fatalf("unexpected error adding: %v", err)
+	}
+	if added.ID != 3 {
+		t.Errorf("expected id 3, got %d", added.ID)
+	}
+}
+
+func TestAddFilmDoesNotReuseDeletedID(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"war"}, Provider: "Netflix"},
+		{ID: 2, Name: "Film B", Genres: []string{"comedy"}, Provider: "itvX"},
+	}
+	h := New(store)
+
+	if _, err := h.DeleteFilm(2); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+
+	added, err := h.AddFilm(data.Film{Name: "Film C", Genres: []string{"crime"}, Provider: "Netflix"})
+	if err != nil {
+		t.Fatalf("unexpected error adding: %v", err)
+	}
+	if added.ID != 3 {
+		t.Errorf("expected id 3, got %d", added.ID)
+	}
+}
+
+func TestGetAvailableGenres(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"drama"}},
+	}
+	store.CurrentShows = []data.Show{
+		{ID: 2, Name: "Show B", Genres: []string{"Comedy"}, Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"sci-fi", "drama"}},
+	}
+
+	result, err := New(store).GetAvailableGenres()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []shows.GenreCount{
+		{Genre: "comedy", Watching: 1},
+		{Genre: "drama", Unwatched: 1, Films: 1},
+		{Genre: "science fiction", Films: 1},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestGetAvailableProviders(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "Show A", Provider: "iplayer"},
+	}
+	store.CurrentShows = []data.Show{
+		{ID: 2, Name: "Show B", Provider: "Netflix", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Provider: "NETFLIX"},
+	}
+
+	result, err := New(store).GetAvailableProviders()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []shows.ProviderCount{
+		{Provider: "BBC iPlayer", Unwatched: 1},
+		{Provider: "Netflix", Watching: 1, Films: 1},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestGetUnwatchedShowsMatching(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix"},
+		{ID: 2, Name: "Show B", Genres: []string{"drama"}, Provider: "BBC iPlayer"},
+	}
+
+	result, err := New(store).GetUnwatchedShowsMatching(shows.Filter{Genres: []string{"Drama"}, Providers: []string{"iplayer"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []data.Show{{ID: 2, Name: "Show B", Genres: []string{"drama"}, Provider: "BBC iPlayer"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestNormalisesGenresAndProviders(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"Drama"}, Provider: "netflix", Episodes: []int{6}},
+		{ID: 2, Name: "Show B", Genres: []string{"Sci-Fi"}, Provider: "Disney+", Episodes: []int{8}},
+	}
+	h := NewWithNormaliser(store, normalise.New(normalise.Aliases{Providers: map[string]string{"disney": "Disney+"}}))
+
+	result, err := h.GetUnwatchedShowsMatching(shows.Filter{Genres: []string{"DRAMA", "science fiction"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+		{ID: 2, Name: "Show B", Genres: []string{"science fiction"}, Provider: "Disney+", Episodes: []int{8}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+
+	added, err := h.AddShow(data.Show{Name: "Show C", Genres: []string{" Rom-Com"}, Provider: "DISNEY", Episodes: []int{10}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(added.Genres, []string{"romantic comedy"}) || added.Provider != "Disney+" {
+		t.Errorf("expected the added show to be normalised, got %+v", added)
+	}
+
+	// everything read is written back normalised
+	if store.Shows[0].Provider != "Netflix" || !reflect.DeepEqual(store.Shows[1].Genres, []string{"science fiction"}) {
+		t.Errorf("expected the catalogue to be saved normalised, got %+v", store.Shows)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "The Crown", Genres: []string{"drama"}, Provider: "netflix"},
+		{ID: 2, Name: "Crownies", Genres: []string{"drama"}, Provider: "BBC iPlayer"},
+	}
+	store.CurrentShows = []data.Show{
+		{ID: 3, Name: "The Night Manager", Genres: []string{"thriller"}, Provider: "BBC iPlayer", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+	store.Films = []data.Film{
+		{ID: 1, Name: "The Crown Jewels", Genres: []string{"documentary"}, Provider: "BBC iPlayer"},
+	}
+	h := New(store)
+
+	result, err := h.Search("crwon", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []search.Result{
+		{Kind: search.KindShow, ID: 1, Name: "The Crown", Genres: []string{"drama"}, Provider: "Netflix", Match: search.FieldName},
+		{Kind: search.KindFilm, ID: 1, Name: "The Crown Jewels", Genres: []string{"documentary"}, Provider: "BBC iPlayer", Match: search.FieldName},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d results, got %+v", len(expected), result)
+	}
+	for i := range result {
+		// scores are covered by the search package's tests
+		result[i].Score = 0
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+
+	result, err = h.Search("night manger", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 || !result[0].Watching {
+		t.Errorf("expected the show being watched, got %+v", result)
+	}
+}
+
+func TestSubscriptionCRUD(t *testing.T) {
+	store := db.NewMemoryStore()
+	h := New(store)
+
+	saved, err := h.SetSubscription(data.Subscription{Provider: "disney plus"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.Provider != "Disney+" {
+		t.Errorf("expected the provider to be normalised, got %q", saved.Provider)
+	}
+
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := h.SetSubscription(data.Subscription{Provider: "Disney+", End: &end}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	subs, err := h.GetSubscriptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []data.Subscription{{Provider: "Disney+", End: &end}}
+	if !reflect.DeepEqual(subs, expected) {
+		t.Errorf("expected %+v, got %+v", expected, subs)
+	}
+
+	if _, err := h.SetSubscription(data.Subscription{}); !errors.Is(err, subscriptions.ErrInvalidSubscription) {
+		t.Errorf("expected ErrInvalidSubscription, got %v", err)
+	}
+
+	if _, err := h.DeleteSubscription("DISNEY+"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.Subscriptions) != 0 {
+		t.Errorf("expected no subscriptions, got %+v", store.Subscriptions)
+	}
+	if _, err := h.DeleteSubscription("Netflix"); !errors.Is(err, subscriptions.ErrSubscriptionNotFound) {
+		t.Errorf("expected ErrSubscriptionNotFound, got %v", err)
+	}
+}
+
+func TestSubscribedOnly(t *testing.T) {
+	ended := time.Now().Add(-24 * time.Hour)
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+		{ID: 2, Name: "Show B", Genres: []string{"drama"}, Provider: "Disney+", Episodes: []int{6}},
+	}
+	store.Films = []data.Film{
+		{ID: 1, Name: "Film A", Genres: []string{"drama"}, Provider: "Netflix"},
+		{ID: 2, Name: "Film B", Genres: []string{"drama"}, Provider: "NOW"},
+	}
+	store.Subscriptions = []data.Subscription{{Provider: "netflix"}, {Provider: "NOW", End: &ended}}
+	h := New(store)
+
+	unwatched, err := h.GetUnwatchedShowsMatching(shows.Filter{Genres: []string{"drama"}, SubscribedOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(unwatched) != 1 || unwatched[0].ID != 1 {
+		t.Errorf("expected only Show A, got %+v", unwatched)
+	}
+
+	f, err := h.GetFilms(films.Filter{SubscribedOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f) != 1 || f[0].ID != 1 {
+		t.Errorf("expected only Film A, got %+v", f)
+	}
+
+	// asking for a provider that isn't subscribed to matches nothing rather than everything
+	f, err = h.GetFilms(films.Filter{Providers: []string{"NOW"}, SubscribedOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f) != 0 {
+		t.Errorf("expected no films, got %+v", f)
+	}
+
+	recs, err := h.GetRecommendations(recommend.Preferences{SubscribedOnly: true}, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, r := range recs {
+		if r.Provider != "Netflix" {
+			t.Errorf("expected only Netflix recommendations, got %+v", r)
+		}
+	}
+	if len(recs) != 2 {
+		t.Errorf("expected 2 recommendations, got %d", len(recs))
+	}
+}
+
+func TestGetCurrentlyWatchingShowsMatching(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.CurrentShows = []data.Show{
+		{ID: 1, Name: "Show A", Provider: "Netflix", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+		{ID: 2, Name: "Show B", Provider: "Disney+", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+	}
+	store.Subscriptions = []data.Subscription{{Provider: "netflix"}}
+
+	result, err := New(store).GetCurrentlyWatchingShowsMatching(shows.Filter{SubscribedOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 || result[0].ID != 1 || result[0].Progress == nil {
+		t.Errorf("expected only Show A with its progress, got %+v", result)
+	}
+}
+
+func TestGetSubscriptionReport(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.Shows = []data.Show{
+		{ID: 1, Name: "Show A", Provider: "Netflix"},
+		{ID: 2, Name: "Show B", Provider: "Disney+"},
+		{ID: 3, Name: "Show C", Provider: "Disney+"},
+	}
+	store.Subscriptions = []data.Subscription{{Provider: "netflix"}}
+
+	result, err := New(store).GetSubscriptionReport()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []subscriptions.Unlock{
+		{Provider: "Disney+", Unwatched: 2, Total: 2},
+		{Provider: "Netflix", Active: true, Unwatched: 1, Total: 1},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+// intPtr is a small test helper to construct *int values inline.
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"what-to-watch/data"
+	"what-to-watch/db"
+	"what-to-watch/normalise"
+)
+
+// normalisedStore normalises the genres and providers of every show and film, and the provider
+// of every subscription, read from the wrapped store, so data saved before normalisation, or edited by hand, matches consistently.
+// Writes are passed straight through.
+type normalisedStore struct {
+	db.Store
+	norm *normalise.Normaliser
+}
+
+func (s normalisedStore) ReadShows() ([]data.Show, error) {
+	shows, err := s.Store.ReadShows()
+	return s.norm.Shows(shows), err
+}
+
+func (s normalisedStore) ReadCurrentShows() ([]data.Show, error) {
+	shows, err := s.Store.ReadCurrentShows()
+	return s.norm.Shows(shows), err
+}
+
+func (s normalisedStore) ReadCompletedShows() ([]data.Show, error) {
+	shows, err := s.Store.ReadCompletedShows()
+	return s.norm.Shows(shows), err
+}
+
+func (s normalisedStore) ReadFilms() ([]data.Film, error) {
+	films, err := s.Store.ReadFilms()
+	return s.norm.Films(films), err
+}
+
+func (s normalisedStore) ReadSubscriptions() ([]data.Subscription, error) {
+	subs, err := s.Store.ReadSubscriptions()
+	for i := range subs {
+		subs[i].Provider = s.norm.Provider(subs[i].Provider)
+	}
+	return subs, err
+}
@@ -0,0 +1,127 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"what-to-watch/data"
+)
+
+// ErrNothingToUndo is returned when there is no watch event left to undo.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// DateLayout is the layout used for the dates accepted by ParseDateRange.
+const DateLayout = "2006-01-02"
+
+// NewEvent records the episode the show is currently on as watched at the given time.
+// It returns an error if the show is not currently being watched.
+func NewEvent(show data.Show, watchedAt time.Time) (data.WatchEvent, error) {
+	if show.CurrentSeries == nil || show.CurrentEpisode == nil {
+		return data.WatchEvent{}, fmt.Errorf("%s is not currently being watched", show.Name)
+	}
+
+	return data.WatchEvent{
+		ShowID:    show.ID,
+		Show:      show.Name,
+		Series:    *show.CurrentSeries,
+		Episode:   *show.CurrentEpisode,
+		WatchedAt: watchedAt,
+	}, nil
+}
+
+// NewJumpEvent records the show being moved straight to another episode at the given time.
+// The show is as it was before the jump, and must be on an episode.
+func NewJumpEvent(show data.Show, at time.Time) (data.WatchEvent, error) {
+	event, err := NewEvent(show, at)
+	if err != nil {
+		return data.WatchEvent{}, err
+	}
+
+	event.Kind = data.EventJump
+	return event, nil
+}
+
+// NewUndoEvent records undoing the given event at the given time.
+func NewUndoEvent(undone data.WatchEvent, at time.Time) data.WatchEvent {
+	return data.WatchEvent{
+		ShowID:    undone.ShowID,
+		Show:      undone.Show,
+		Series:    undone.Series,
+		Episode:   undone.Episode,
+		WatchedAt: at,
+		Kind:      data.EventUndo,
+	}
+}
+
+// Effective returns the events that have not been undone, oldest first, leaving out the undo
+// events themselves. Each undo event cancels the latest event before it not already undone.
+func Effective(events []data.WatchEvent) []data.WatchEvent {
+	var kept []data.WatchEvent
+	for _, e := range events {
+		if e.Kind == data.EventUndo {
+			if len(kept) > 0 {
+				kept = kept[:len(kept)-1]
+			}
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// Watched returns the episodes watched that have not been undone, oldest first.
+func Watched(events []data.WatchEvent) []data.WatchEvent {
+	var watched []data.WatchEvent
+	for _, e := range Effective(events) {
+		if e.Kind == data.EventWatched {
+			watched = append(watched, e)
+		}
+	}
+	return watched
+}
+
+// FilterByDate returns the events watched at or after from and before to.
+// A zero from or to leaves that end of the range open.
+func FilterByDate(events []data.WatchEvent, from, to time.Time) []data.WatchEvent {
+	var filtered []data.WatchEvent
+	for _, e := range events {
+		if !from.IsZero() && e.WatchedAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !e.WatchedAt.Before(to) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
+// ParseDateRange parses an inclusive range of YYYY-MM-DD dates in the local time zone into
+// the half-open range used by FilterByDate, so that every event on the to date is included.
+// An empty string leaves that end of the range open.
+func ParseDateRange(from, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+
+	if from != "" {
+		d, err := time.ParseInLocation(DateLayout, from, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be a date in YYYY-MM-DD format")
+		}
+		start = d
+	}
+
+	if to != "" {
+		d, err := time.ParseInLocation(DateLayout, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be a date in YYYY-MM-DD format")
+		}
+		end = d.AddDate(0, 0, 1)
+	}
+
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+	}
+
+	return start, end, nil
+}
@@ -0,0 +1,182 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+)
+
+func TestNewEvent(t *testing.T) {
+	series, episode := 2, 3
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		show        data.Show
+		expected    data.WatchEvent
+		expectError bool
+	}{
+		{
+			name:     "records current position",
+			show:     data.Show{ID: 4, Name: "Show A", CurrentSeries: &series, CurrentEpisode: &episode},
+			expected: data.WatchEvent{ShowID: 4, Show: "Show A", Series: 2, Episode: 3, WatchedAt: watchedAt},
+		},
+		{
+			name:        "show not being watched",
+			show:        data.Show{ID: 4, Name: "Show A"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewEvent(tt.show, watchedAt)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestEffective(t *testing.T) {
+	watched := func(name string) data.WatchEvent { return data.WatchEvent{Show: name} }
+	undo := func(name string) data.WatchEvent { return NewUndoEvent(watched(name), time.Time{}) }
+
+	tests := []struct {
+		name     string
+		events   []data.WatchEvent
+		expected []string
+	}{
+		{name: "no undo", events: []data.WatchEvent{watched("Show A"), watched("Show B")}, expected: []string{"Show A", "Show B"}},
+		{name: "undo cancels the latest event", events: []data.WatchEvent{watched("Show A"), watched("Show B"), undo("Show B")}, expected: []string{"Show A"}},
+		{name: "repeated undo steps further back", events: []data.WatchEvent{watched("Show A"), watched("Show B"), undo("Show B"), undo("Show A")}, expected: nil},
+		{name: "watching after an undo", events: []data.WatchEvent{watched("Show A"), undo("Show A"), watched("Show C")}, expected: []string{"Show C"}},
+		{name: "undo with nothing left is ignored", events: []data.WatchEvent{undo("Show A"), watched("Show B")}, expected: []string{"Show B"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []string
+			for _, e := range Effective(tt.events) {
+				result = append(result, e.Show)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestWatched(t *testing.T) {
+	series, episode := 1, 2
+	show := data.Show{ID: 1, Name: "Show A", CurrentSeries: &series, CurrentEpisode: &episode}
+	at := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+
+	watched, err := NewEvent(show, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jump, err := NewJumpEvent(show, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jump.Kind != data.EventJump || jump.Series != 1 || jump.Episode != 2 {
+		t.Errorf("expected a jump from S1E2, got %+v", jump)
+	}
+
+	events := []data.WatchEvent{watched, jump, watched, NewUndoEvent(watched, at)}
+	result := Watched(events)
+	if !reflect.DeepEqual(result, []data.WatchEvent{watched}) {
+		t.Errorf("expected only the first episode watched, got %+v", result)
+	}
+}
+
+func TestFilterByDate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 11, d, 0, 0, 0, 0, time.UTC) }
+	events := []data.WatchEvent{
+		{Show: "Show A", WatchedAt: day(1)},
+		{Show: "Show B", WatchedAt: day(2)},
+		{Show: "Show C", WatchedAt: day(3)},
+	}
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		expected []string
+	}{
+		{name: "open range", expected: []string{"Show A", "Show B", "Show C"}},
+		{name: "from is inclusive", from: day(2), expected: []string{"Show B", "Show C"}},
+		{name: "to is exclusive", to: day(2), expected: []string{"Show A"}},
+		{name: "bounded range", from: day(2), to: day(3), expected: []string{"Show B"}},
+		{name: "empty range", from: day(4), expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []string
+			for _, e := range FilterByDate(events, tt.from, tt.to) {
+				result = append(result, e.Show)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		from         string
+		to           string
+		expectedFrom time.Time
+		expectedTo   time.Time
+		expectError  bool
+	}{
+		{name: "open range"},
+		{
+			name:         "bounded range includes the whole to date",
+			from:         "2025-11-01",
+			to:           "2025-11-01",
+			expectedFrom: time.Date(2025, 11, 1, 0, 0, 0, 0, time.Local),
+			expectedTo:   time.Date(2025, 11, 2, 0, 0, 0, 0, time.Local),
+		},
+		{name: "invalid from", from: "yesterday", expectError: true},
+		{name: "invalid to", to: "2025-13-01", expectError: true},
+		{name: "from after to", from: "2025-11-02", to: "2025-11-01", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := ParseDateRange(tt.from, tt.to)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !from.Equal(tt.expectedFrom) || !to.Equal(tt.expectedTo) {
+				t.Errorf("expected %v to %v, got %v to %v", tt.expectedFrom, tt.expectedTo, from, to)
+			}
+		})
+	}
+}
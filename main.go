@@ -4,28 +4,87 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"what-to-watch/cmd/cli"
 	"what-to-watch/cmd/http"
+	"what-to-watch/db"
+	"what-to-watch/normalise"
 )
 
 func main() {
 	// Define command-line flags
-	mode := flag.String("mode", "cli", "Run mode: 'cli' for interactive CLI or 'http' for HTTP server")
+	mode := flag.String("mode", "cli", "Run mode: 'cli' for interactive CLI, 'http' for HTTP server or 'import' to load the JSON files into SQLite")
+	storage := flag.String("store", "json", "Storage backend: 'json' for the JSON files or 'sqlite' for the SQLite database")
 	port := flag.Int("port", 8080, "HTTP server port (only used in http mode)")
+	dataDir := flag.String("data-dir", "", "Directory holding the data files (default $WHAT_TO_WATCH_DATA or ~/.local/share/what-to-watch)")
 	flag.Parse()
 
+	dir, err := db.ResolveDataDir(*dataDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving data directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := db.InitDataDir(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error initialising data directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	sqlitePath := filepath.Join(dir, "what-to-watch.db")
+
+	if *mode == "import" {
+		store, err := db.OpenSQLiteStore(sqlitePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening SQLite database: %v\n", err)
+			os.Exit(1)
+		}
+		defer store.Close()
+
+		if err := db.ImportJSON(store, dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Import error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Imported JSON files from %s into %s\n", dir, sqlitePath)
+		return
+	}
+
+	var store db.Store
+	switch *storage {
+	case "json":
+		store = db.NewJSONStore(dir)
+	case "sqlite":
+		sqliteStore, err := db.OpenSQLiteStore(sqlitePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening SQLite database: %v\n", err)
+			os.Exit(1)
+		}
+		defer sqliteStore.Close()
+		store = sqliteStore
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid store: %s. Use 'json' or 'sqlite'.\n", *storage)
+		os.Exit(1)
+	}
+
+	aliases, err := normalise.LoadAliases(filepath.Join(dir, normalise.AliasesFile))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading aliases: %v\n", err)
+		os.Exit(1)
+	}
+	norm := normalise.New(aliases)
+
 	switch *mode {
 	case "cli":
-		cli.Run()
+		cli.Run(store, norm)
 	case "http":
-		server := http.NewServer(*port)
+		server := http.NewServer(*port, store, norm)
 		if err := server.Start(); err != nil {
 			fmt.Fprintf(os.Stderr, "HTTP server error: %v\n", err)
 			os.Exit(1)
 		}
 	default:
-		fmt.Fprintf(os.Stderr, "Invalid mode: %s. Use 'cli' or 'http'.\n", *mode)
+		fmt.Fprintf(os.Stderr, "Invalid mode: %s. Use 'cli', 'http' or 'import'.\n", *mode)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,192 @@
+package normalise
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"what-to-watch/data"
+	"what-to-watch/genres"
+)
+
+// AliasesFile is the optional file in the data directory holding extra aliases.
+const AliasesFile = "aliases.json"
+
+// Aliases map alternative spellings, matched ignoring case and spacing, to the canonical
+// genre or provider they stand for.
+type Aliases struct {
+	Genres    map[string]string `json:"genres"`
+	Providers map[string]string `json:"providers"`
+}
+
+// DefaultAliases are always applied, and can be overridden or extended by the aliases file.
+// Providers are listed under their own names too, so any casing of a known provider is
+// rewritten to its usual spelling.
+var DefaultAliases = Aliases{
+	Genres: map[string]string{
+		"sci-fi":        "science fiction",
+		"sci fi":        "science fiction",
+		"scifi":         "science fiction",
+		"rom-com":       "romantic comedy",
+		"romcom":        "romantic comedy",
+		"documentaries": "documentary",
+		"doc":           "documentary",
+		"docs":          "documentary",
+	},
+	Providers: map[string]string{
+		"netflix":            "Netflix",
+		"disney+":            "Disney+",
+		"disney plus":        "Disney+",
+		"bbc iplayer":        "BBC iPlayer",
+		"iplayer":            "BBC iPlayer",
+		"channel 4":          "Channel 4",
+		"channel4":           "Channel 4",
+		"itvx":               "itvX",
+		"itv x":              "itvX",
+		"sky cinema":         "Sky Cinema",
+		"prime video":        "Prime Video",
+		"amazon prime":       "Prime Video",
+		"amazon prime video": "Prime Video",
+		"apple tv+":          "Apple TV+",
+		"apple tv plus":      "Apple TV+",
+		"now":                "NOW",
+		"now tv":             "NOW",
+	},
+}
+
+// Normaliser rewrites genres and providers to a canonical form, so the same genre or provider
+// always has the same spelling. Genres are lower case; providers keep the casing of their alias,
+// or their own casing when they have none.
+type Normaliser struct {
+	genres    map[string]string
+	providers map[string]string
+}
+
+// New creates a Normaliser applying the given aliases on top of DefaultAliases.
+func New(aliases Aliases) *Normaliser {
+	n := &Normaliser{genres: map[string]string{}, providers: map[string]string{}}
+	for _, a := range []Aliases{DefaultAliases, aliases} {
+		for from, to := range a.Genres {
+			n.genres[key(from)] = key(to)
+		}
+		for from, to := range a.Providers {
+			n.providers[key(from)] = collapse(to)
+		}
+	}
+	return n
+}
+
+// Default creates a Normaliser applying only DefaultAliases.
+func Default() *Normaliser {
+	return New(Aliases{})
+}
+
+// LoadAliases reads the aliases file at path. A missing file is not an error and
+// gives no extra aliases.
+func LoadAliases(path string) (Aliases, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Aliases{}, nil
+	}
+	if err != nil {
+		return Aliases{}, fmt.Errorf("LoadAliases: error reading file \n err=%w path=%s", err, path)
+	}
+
+	var aliases Aliases
+	if err := json.Unmarshal(raw, &aliases); err != nil {
+		return Aliases{}, fmt.Errorf("LoadAliases: error decoding file \n err=%w path=%s", err, path)
+	}
+
+	return aliases, nil
+}
+
+// Genre returns the canonical form of a genre, or "" for a blank genre.
+func (n *Normaliser) Genre(genre string) string {
+	k := key(genre)
+	if to, ok := n.genres[k]; ok {
+		return to
+	}
+	return k
+}
+
+// Genres returns the canonical form of each genre, dropping blanks and any that
+// end up the same as an earlier genre.
+func (n *Normaliser) Genres(list []string) []string {
+	normalised := make([]string, len(list))
+	for i, g := range list {
+		normalised[i] = n.Genre(g)
+	}
+	return genres.Clean(normalised)
+}
+
+// Provider returns the canonical form of a provider, or "" for a blank provider.
+func (n *Normaliser) Provider(provider string) string {
+	if to, ok := n.providers[key(provider)]; ok {
+		return to
+	}
+	return collapse(provider)
+}
+
+// Providers returns the canonical form of each provider, dropping blanks.
+func (n *Normaliser) Providers(list []string) []string {
+	var normalised []string
+	for _, p := range list {
+		if p = n.Provider(p); p != "" {
+			normalised = append(normalised, p)
+		}
+	}
+	return normalised
+}
+
+// Show returns the show with its genres and provider normalised.
+func (n *Normaliser) Show(show data.Show) data.Show {
+	show.Genres = n.Genres(show.Genres)
+	show.Provider = n.Provider(show.Provider)
+	return show
+}
+
+// Shows returns a copy of shows with every show normalised.
+func (n *Normaliser) Shows(shows []data.Show) []data.Show {
+	if shows == nil {
+		return nil
+	}
+
+	normalised := make([]data.Show, len(shows))
+	for i, s := range shows {
+		normalised[i] = n.Show(s)
+	}
+	return normalised
+}
+
+// Film returns the film with its genres and provider normalised.
+func (n *Normaliser) Film(film data.Film) data.Film {
+	film.Genres = n.Genres(film.Genres)
+	film.Provider = n.Provider(film.Provider)
+	return film
+}
+
+// Films returns a copy of films with every film normalised.
+func (n *Normaliser) Films(films []data.Film) []data.Film {
+	if films == nil {
+		return nil
+	}
+
+	normalised := make([]data.Film, len(films))
+	for i, f := range films {
+		normalised[i] = n.Film(f)
+	}
+	return normalised
+}
+
+// key is the form aliases are looked up by: lower case with runs of spaces collapsed.
+func key(s string) string {
+	return strings.ToLower(collapse(s))
+}
+
+// collapse trims s and collapses runs of whitespace to single spaces.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
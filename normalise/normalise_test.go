@@ -0,0 +1,122 @@
+package normalise
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"what-to-watch/data"
+)
+
+func TestGenre(t *testing.T) {
+	n := New(Aliases{Genres: map[string]string{"Whodunnit": "Mystery"}})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "already canonical", input: "drama", expected: "drama"},
+		{name: "lower cased", input: "Drama", expected: "drama"},
+		{name: "spacing collapsed", input: "  Star   Wars ", expected: "star wars"},
+		{name: "default alias", input: "Sci-Fi", expected: "science fiction"},
+		{name: "configured alias", input: "whodunnit", expected: "mystery"},
+		{name: "blank", input: " ", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := n.Genre(tt.input); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenres(t *testing.T) {
+	result := Default().Genres([]string{"Sci-Fi", "science fiction", "", "Comedy"})
+
+	expected := []string{"science fiction", "comedy"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	n := New(Aliases{Providers: map[string]string{"Disney+": "Disney Plus", "my box": "My Box"}})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "already canonical", input: "Netflix", expected: "Netflix"},
+		{name: "known provider in another case", input: "NETFLIX", expected: "Netflix"},
+		{name: "default alias", input: "iplayer", expected: "BBC iPlayer"},
+		{name: "configured alias overrides default", input: "disney+", expected: "Disney Plus"},
+		{name: "configured alias", input: "MY  BOX", expected: "My Box"},
+		{name: "unknown provider keeps its casing", input: " Shudder ", expected: "Shudder"},
+		{name: "blank", input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := n.Provider(tt.input); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestShowsAndFilms(t *testing.T) {
+	n := Default()
+
+	shows := n.Shows([]data.Show{{ID: 1, Name: "Show A", Genres: []string{"Drama", "sci fi"}, Provider: "netflix"}})
+	expectedShows := []data.Show{{ID: 1, Name: "Show A", Genres: []string{"drama", "science fiction"}, Provider: "Netflix"}}
+	if !reflect.DeepEqual(shows, expectedShows) {
+		t.Errorf("expected %+v, got %+v", expectedShows, shows)
+	}
+
+	films := n.Films([]data.Film{{ID: 1, Name: "Film A", Genres: []string{"Docs"}, Provider: "bbc iplayer"}})
+	expectedFilms := []data.Film{{ID: 1, Name: "Film A", Genres: []string{"documentary"}, Provider: "BBC iPlayer"}}
+	if !reflect.DeepEqual(films, expectedFilms) {
+		t.Errorf("expected %+v, got %+v", expectedFilms, films)
+	}
+
+	if n.Shows(nil) != nil || n.Films(nil) != nil {
+		t.Errorf("expected nil lists to stay nil")
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	dir := t.TempDir()
+
+	aliases, err := LoadAliases(filepath.Join(dir, AliasesFile))
+	if err != nil {
+		t.Fatalf("expected a missing file to be ignored, got %v", err)
+	}
+	if !reflect.DeepEqual(aliases, Aliases{}) {
+		t.Errorf("expected no aliases, got %+v", aliases)
+	}
+
+	path := filepath.Join(dir, AliasesFile)
+	if err := os.WriteFile(path, []byte(`{"genres": {"whodunnit": "mystery"}, "providers": {"beeb": "BBC iPlayer"}}`), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	aliases, err = LoadAliases(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Aliases{Genres: map[string]string{"whodunnit": "mystery"}, Providers: map[string]string{"beeb": "BBC iPlayer"}}
+	if !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("expected %+v, got %+v", expected, aliases)
+	}
+
+	if err := os.WriteFile(path, []byte(`not json`), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := LoadAliases(path); err == nil {
+		t.Errorf("expected an error for an invalid file")
+	}
+}
@@ -0,0 +1,192 @@
+package planner
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sort"
+
+	"what-to-watch/data"
+	"what-to-watch/recommend"
+	"what-to-watch/shows"
+)
+
+// ErrInvalidBudget is returned when the time available is not a positive number of minutes
+var ErrInvalidBudget = errors.New("invalid time budget")
+
+// Item is one part of a plan: a film, or a run of consecutive episodes of a show.
+type Item struct {
+	Kind recommend.Kind `json:"kind"`
+	ID   int            `json:"id"`
+	Name string         `json:"name"`
+	// Series and Episode are the first episode to watch, and Episodes how many to watch from there.
+	// They are only set for shows
+	Series   int `json:"series,omitempty"`
+	Episode  int `json:"episode,omitempty"`
+	Episodes int `json:"episodes,omitempty"`
+	Minutes  int `json:"minutes"`
+}
+
+// Plan is a combination of things to watch that fits the time available.
+type Plan struct {
+	Items   []Item `json:"items"`
+	Minutes int    `json:"minutes"`
+	// Spare is the time left over
+	Spare int `json:"spare"`
+}
+
+// Propose returns up to limit plans that fit within budget minutes, each made of at most one
+// film and runs of the next episodes of up to two of the shows being watched. Plans that use the
+// most time come first. A limit of 0 or less returns every plan.
+// Shows whose next episode has no runtime and films without a runtime are left out.
+func Propose(current []data.Show, films []data.Film, budget, limit int) ([]Plan, error) {
+	if budget < 1 {
+		return nil, ErrInvalidBudget
+	}
+
+	var runs [][]Item
+	for _, s := range current {
+		if r := nextEpisodes(s, budget); len(r) > 0 {
+			runs = append(runs, r)
+		}
+	}
+
+	starts := [][]Item{nil}
+	for _, f := range films {
+		if f.WatchedAt == nil && f.Runtime > 0 && f.Runtime <= budget {
+			starts = append(starts, []Item{{Kind: recommend.KindFilm, ID: f.ID, Name: f.Name, Minutes: f.Runtime}})
+		}
+	}
+
+	b := &best{limit: limit}
+	for _, start := range starts {
+		addShows(b, start, runs, budget)
+	}
+
+	return b.result(), nil
+}
+
+// addShows adds the plans that start with the given items and add the most episodes that fit
+// of one or two shows. Shorter runs of a lone show are left out as they only waste time.
+func addShows(b *best, start []Item, runs [][]Item, budget int) {
+	used := minutes(start)
+
+	if len(start) > 0 {
+		b.add(newPlan(budget, start...))
+	}
+
+	for i, first := range runs {
+		// the longest run of this show on its own
+		if k := fit(first, budget-used); k > 0 {
+			b.add(newPlan(budget, append(slices.Clone(start), first[k-1])...))
+		}
+
+		// every length of this show, topped up with as much of another as fits
+		for k := 1; k <= len(first) && used+first[k-1].Minutes <= budget; k++ {
+			for _, second := range runs[i+1:] {
+				if j := fit(second, budget-used-first[k-1].Minutes); j > 0 {
+					b.add(newPlan(budget, append(slices.Clone(start), first[k-1], second[j-1])...))
+				}
+			}
+		}
+	}
+}
+
+// best keeps the best plans added so far in order, holding at most limit of them so that the
+// work stays bounded however many combinations there are. A limit of 0 or less keeps every plan.
+type best struct {
+	limit int
+	plans []Plan
+}
+
+// add inserts the plan after any plans that are as good, dropping it or the worst plan kept
+// when there are more than limit. Without a limit the plans are only sorted by result.
+func (b *best) add(p Plan) {
+	if b.limit <= 0 {
+		b.plans = append(b.plans, p)
+		return
+	}
+
+	i := sort.Search(len(b.plans), func(i int) bool { return comparePlans(p, b.plans[i]) < 0 })
+	if i >= b.limit {
+		return
+	}
+
+	b.plans = slices.Insert(b.plans, i, p)
+	if len(b.plans) > b.limit {
+		b.plans = b.plans[:b.limit]
+	}
+}
+
+// result returns the plans kept, best first.
+func (b *best) result() []Plan {
+	if b.limit <= 0 {
+		slices.SortStableFunc(b.plans, comparePlans)
+	}
+	return b.plans
+}
+
+// comparePlans orders the plans that use the most time first, then those with the fewest items.
+func comparePlans(a, b Plan) int {
+	if c := cmp.Compare(b.Minutes, a.Minutes); c != 0 {
+		return c
+	}
+	return cmp.Compare(len(a.Items), len(b.Items))
+}
+
+// nextEpisodes returns the runs of the next episodes of the show that fit within budget,
+// where the run at index k covers k+1 episodes starting from the current one.
+func nextEpisodes(s data.Show, budget int) []Item {
+	if s.CurrentSeries == nil || s.CurrentEpisode == nil {
+		return nil
+	}
+
+	first := Item{Kind: recommend.KindShow, ID: s.ID, Name: s.Name, Series: *s.CurrentSeries, Episode: *s.CurrentEpisode}
+
+	var runs []Item
+	total := 0
+	series, episode := *s.CurrentSeries, *s.CurrentEpisode
+	for series >= 1 && series <= len(s.Episodes) {
+		if episode > s.Episodes[series-1] {
+			series, episode = series+1, 1
+			continue
+		}
+
+		runtime := shows.EpisodeRuntime(s, series)
+		if runtime < 1 || total+runtime > budget {
+			break
+		}
+		total += runtime
+
+		run := first
+		run.Episodes = len(runs) + 1
+		run.Minutes = total
+		runs = append(runs, run)
+
+		episode++
+	}
+
+	return runs
+}
+
+// fit returns how many episodes of the run fit within the minutes available.
+func fit(run []Item, available int) int {
+	k := 0
+	for k < len(run) && run[k].Minutes <= available {
+		k++
+	}
+	return k
+}
+
+func newPlan(budget int, items ...Item) Plan {
+	total := minutes(items)
+	return Plan{Items: items, Minutes: total, Spare: budget - total}
+}
+
+func minutes(items []Item) int {
+	total := 0
+	for _, it := range items {
+		total += it.Minutes
+	}
+	return total
+}
@@ -0,0 +1,118 @@
+package planner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+	"what-to-watch/recommend"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestPropose(t *testing.T) {
+	watchedAt := time.Date(2025, 11, 1, 20, 0, 0, 0, time.UTC)
+	current := []data.Show{
+		// two episodes left in series 1, then a longer series 2
+		{ID: 1, Name: "Show A", Episodes: []int{6, 6}, Runtime: 30, SeriesRuntimes: []int{0, 45}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(5)},
+		{ID: 2, Name: "Show B", Episodes: []int{10}, Runtime: 25, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(9)},
+		{ID: 3, Name: "No Runtime", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+	films := []data.Film{
+		{ID: 1, Name: "Film A", Runtime: 80},
+		{ID: 2, Name: "Watched Film", Runtime: 60, WatchedAt: &watchedAt},
+		{ID: 3, Name: "Long Film", Runtime: 180},
+		{ID: 4, Name: "Unknown Film"},
+	}
+
+	tests := []struct {
+		name     string
+		budget   int
+		limit    int
+		expected []Plan
+	}{
+		{
+			name:   "most time used first, then fewest items",
+			budget: 90,
+			limit:  3,
+			expected: []Plan{
+				{Items: []Item{
+					{Kind: recommend.KindShow, ID: 1, Name: "Show A", Series: 1, Episode: 5, Episodes: 2, Minutes: 60},
+					{Kind: recommend.KindShow, ID: 2, Name: "Show B", Series: 1, Episode: 9, Episodes: 1, Minutes: 25},
+				}, Minutes: 85, Spare: 5},
+				{Items: []Item{
+					{Kind: recommend.KindFilm, ID: 1, Name: "Film A", Minutes: 80},
+				}, Minutes: 80, Spare: 10},
+				{Items: []Item{
+					{Kind: recommend.KindShow, ID: 1, Name: "Show A", Series: 1, Episode: 5, Episodes: 1, Minutes: 30},
+					{Kind: recommend.KindShow, ID: 2, Name: "Show B", Series: 1, Episode: 9, Episodes: 2, Minutes: 50},
+				}, Minutes: 80, Spare: 10},
+			},
+		},
+		{
+			name:   "series runtimes apply across series",
+			budget: 105,
+			limit:  1,
+			expected: []Plan{
+				{Items: []Item{
+					{Kind: recommend.KindShow, ID: 1, Name: "Show A", Series: 1, Episode: 5, Episodes: 3, Minutes: 105},
+				}, Minutes: 105, Spare: 0},
+			},
+		},
+		{
+			name:     "nothing fits",
+			budget:   20,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Propose(current, films, tt.budget, tt.limit)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestProposeInvalidBudget(t *testing.T) {
+	if _, err := Propose(nil, nil, 0, 5); !errors.Is(err, ErrInvalidBudget) {
+		t.Errorf("expected ErrInvalidBudget, got %v", err)
+	}
+}
+
+func TestProposeLimitKeepsBestPlans(t *testing.T) {
+	var current []data.Show
+	for i := 1; i <= 20; i++ {
+		current = append(current, data.Show{ID: i, Name: "Show", Episodes: []int{12}, Runtime: 20 + i, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)})
+	}
+	var films []data.Film
+	for i := 1; i <= 20; i++ {
+		films = append(films, data.Film{ID: i, Name: "Film", Runtime: 60 + i})
+	}
+
+	all, err := Propose(current, films, 240, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// keeping only the best plans while generating gives the same plans as ranking them all
+	for _, limit := range []int{1, 5, 50} {
+		result, err := Propose(current, films, 240, limit)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, all[:limit]) {
+			t.Errorf("limit %d: expected %+v, got %+v", limit, all[:limit], result)
+		}
+	}
+}
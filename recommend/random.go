@@ -0,0 +1,98 @@
+package recommend
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+
+	"what-to-watch/genres"
+)
+
+// ErrNoMatches is returned when nothing matches the filter for a random pick
+var ErrNoMatches = errors.New("nothing matches the filter")
+
+// Filter narrows the candidates for a random pick. Empty fields match everything.
+type Filter struct {
+	Genre    string
+	Provider string
+	// Kind restricts the pick to shows or films
+	Kind Kind
+	// InProgressOnly restricts the pick to shows that have been started, which leaves out films
+	InProgressOnly bool
+}
+
+// Pick is a randomly chosen show or film.
+type Pick struct {
+	Kind     Kind     `json:"kind"`
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Genres   []string `json:"genres"`
+	Provider string   `json:"provider"`
+	// Series and Episode are the next episode to watch, and are only set for shows already in progress
+	Series  int `json:"series,omitempty"`
+	Episode int `json:"episode,omitempty"`
+}
+
+// Candidates returns every show in progress, unstarted show and unwatched film that matches the filter.
+// Genre and provider are matched case-insensitively, with the genre matching any of an item's genres.
+func Candidates(lib Library, filter Filter) []Pick {
+	var picks []Pick
+	if filter.Kind == "" || filter.Kind == KindShow {
+		for _, s := range lib.Current {
+			if s.CurrentSeries == nil || s.CurrentEpisode == nil {
+				continue
+			}
+			picks = append(picks, Pick{Kind: KindShow, ID: s.ID, Name: s.Name, Genres: s.Genres, Provider: s.Provider, Series: *s.CurrentSeries, Episode: *s.CurrentEpisode})
+		}
+
+		if !filter.InProgressOnly {
+			for _, s := range lib.Catalogue {
+				if s.CurrentSeries != nil || s.CurrentEpisode != nil {
+					continue
+				}
+				picks = append(picks, Pick{Kind: KindShow, ID: s.ID, Name: s.Name, Genres: s.Genres, Provider: s.Provider})
+			}
+		}
+	}
+
+	if (filter.Kind == "" || filter.Kind == KindFilm) && !filter.InProgressOnly {
+		for _, f := range lib.Films {
+			if f.WatchedAt != nil {
+				continue
+			}
+			picks = append(picks, Pick{Kind: KindFilm, ID: f.ID, Name: f.Name, Genres: f.Genres, Provider: f.Provider})
+		}
+	}
+
+	var matches []Pick
+	for _, p := range picks {
+		if (filter.Genre == "" || genres.Contains(p.Genres, filter.Genre)) && matchFold(filter.Provider, p.Provider) {
+			matches = append(matches, p)
+		}
+	}
+	return matches
+}
+
+// Random picks one of the candidates matching the filter using rng, so a seeded rng always
+// makes the same pick. A nil rng uses the shared random source.
+// ErrNoMatches is returned when there is nothing to pick from.
+func Random(lib Library, filter Filter, rng *rand.Rand) (Pick, error) {
+	matches := Candidates(lib, filter)
+	if len(matches) == 0 {
+		return Pick{}, ErrNoMatches
+	}
+
+	var i int
+	if rng == nil {
+		i = rand.IntN(len(matches))
+	} else {
+		i = rng.IntN(len(matches))
+	}
+	return matches[i], nil
+}
+
+// matchFold reports whether value matches want ignoring case, with an empty want matching anything.
+func matchFold(want, value string) bool {
+	want = strings.TrimSpace(want)
+	return want == "" || strings.EqualFold(want, value)
+}
@@ -0,0 +1,131 @@
+package recommend
+
+import (
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+)
+
+func randomLibrary() Library {
+	watchedAt := time.Date(2025, 11, 1, 20, 0, 0, 0, time.UTC)
+	return Library{
+		Current: []data.Show{
+			{ID: 1, Name: "Show A", Genres: []string{"Drama"}, Provider: "Netflix", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(4)},
+			{ID: 2, Name: "Show B", Genres: []string{"Comedy"}, Provider: "Netflix", Episodes: []int{10}},
+		},
+		Catalogue: []data.Show{
+			{ID: 3, Name: "Show C", Genres: []string{"Drama"}, Provider: "BBC iPlayer", Episodes: []int{6}},
+		},
+		Films: []data.Film{
+			{ID: 1, Name: "Film A", Genres: []string{"Drama"}, Provider: "Netflix"},
+			{ID: 2, Name: "Film B", Genres: []string{"Comedy"}, Provider: "Netflix", WatchedAt: &watchedAt},
+		},
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			expected: []string{"Show A", "Show C", "Film A"},
+		},
+		{
+			name:     "genre ignores case",
+			filter:   Filter{Genre: "drama"},
+			expected: []string{"Show A", "Show C", "Film A"},
+		},
+		{
+			name:     "provider",
+			filter:   Filter{Provider: "bbc iplayer"},
+			expected: []string{"Show C"},
+		},
+		{
+			name:     "films only",
+			filter:   Filter{Kind: KindFilm},
+			expected: []string{"Film A"},
+		},
+		{
+			name:     "shows only",
+			filter:   Filter{Kind: KindShow},
+			expected: []string{"Show A", "Show C"},
+		},
+		{
+			name:     "in progress only",
+			filter:   Filter{InProgressOnly: true},
+			expected: []string{"Show A"},
+		},
+		{
+			name:     "nothing matches",
+			filter:   Filter{Genre: "Comedy"},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Candidates(randomLibrary(), tt.filter)
+			got := []string{}
+			for _, p := range result {
+				got = append(got, p.Name)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRandom(t *testing.T) {
+	lib := randomLibrary()
+
+	first, err := Random(lib, Filter{}, rand.New(rand.NewPCG(42, 42)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the same seed always makes the same pick
+	for range 5 {
+		again, err := Random(lib, Filter{}, rand.New(rand.NewPCG(42, 42)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(again, first) {
+			t.Fatalf("expected %+v, got %+v", first, again)
+		}
+	}
+
+	// every candidate is eventually picked
+	seen := map[string]bool{}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 100 {
+		p, err := Random(lib, Filter{}, rng)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen[p.Name] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("expected all 3 candidates to be picked, got %v", seen)
+	}
+
+	inProgress, err := Random(lib, Filter{InProgressOnly: true}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Pick{Kind: KindShow, ID: 1, Name: "Show A", Genres: []string{"Drama"}, Provider: "Netflix", Series: 1, Episode: 4}
+	if !reflect.DeepEqual(inProgress, expected) {
+		t.Errorf("expected %+v, got %+v", expected, inProgress)
+	}
+
+	if _, err := Random(lib, Filter{Genre: "Horror"}, nil); !errors.Is(err, ErrNoMatches) {
+		t.Errorf("expected ErrNoMatches, got %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package recommend
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"what-to-watch/data"
+	"what-to-watch/shows"
+)
+
+// Kind is the kind of thing being recommended.
+type Kind string
+
+const (
+	KindShow Kind = "show"
+	KindFilm Kind = "film"
+)
+
+// Recommendation is a single scored suggestion, with the reasons that contributed to its score.
+type Recommendation struct {
+	Kind     Kind     `json:"kind"`
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Genres   []string `json:"genres"`
+	Provider string   `json:"provider"`
+	Score    float64  `json:"score"`
+	// Series and Episode are the next episode to watch, and are only set for shows already in progress
+	Series  int      `json:"series,omitempty"`
+	Episode int      `json:"episode,omitempty"`
+	Reasons []string `json:"reasons"`
+}
+
+// Preferences narrow and weight the recommendations.
+type Preferences struct {
+	// Genres are favourite genres, which score higher
+	Genres []string
+	// Providers are the providers available to watch on. When empty every provider is allowed,
+	// otherwise anything on another provider is left out
+	Providers []string
+	// SubscribedOnly leaves out anything on a provider without an active subscription. The
+	// handlers resolve it into Providers, as the subscriptions are not known here
+	SubscribedOnly bool
+}
+
+// Library holds everything that can be recommended, along with the watch history used to
+// learn genre preferences and how recently each show was watched.
+type Library struct {
+	Current   []data.Show
+	Catalogue []data.Show
+	Films     []data.Film
+	History   []data.WatchEvent
+}
+
+// scoring weights
+const (
+	inProgressWeight   = 2.0
+	favouriteWeight    = 3.0
+	historyGenreWeight = 2.0
+	recencyWeight      = 2.0
+	nearlyDoneWeight   = 1.5
+	shortSeriesWeight  = 0.5
+	filmWeight         = 1.0
+
+	// recencyHalfLife is how long it takes for the bonus for a recently watched show to halve
+	recencyHalfLife = 7 * 24 * time.Hour
+	// nearlyDoneEpisodes is the number of remaining episodes at or below which a show is nearly done
+	nearlyDoneEpisodes = 5
+	// shortSeriesEpisodes is the total number of episodes at or below which an unstarted show is short
+	shortSeriesEpisodes = 10
+)
+
+// Recommend scores the shows in progress, the unstarted shows in the catalogue and the
+// unwatched films, and returns the top limit of them, highest score first.
+// Shows without progress in lib.Current and watched films are never recommended.
+func Recommend(lib Library, prefs Preferences, now time.Time, limit int) []Recommendation {
+	historyGenres := genreShares(lib)
+	lastWatched := lastWatchedByShow(lib.History)
+
+	var recs []Recommendation
+	for _, s := range lib.Current {
+		if s.CurrentSeries == nil || s.CurrentEpisode == nil || !available(s.Provider, prefs) {
+			continue
+		}
+
+		r := newRecommendation(KindShow, s.ID, s.Name, s.Genres, s.Provider, prefs, historyGenres)
+		r.Series, r.Episode = *s.CurrentSeries, *s.CurrentEpisode
+		r.add(inProgressWeight, fmt.Sprintf("you're part way through, next up is series %d episode %d", r.Series, r.Episode))
+
+		if at, ok := lastWatched[s.ID]; ok {
+			since := now.Sub(at)
+			r.add(recencyWeight*math.Pow(0.5, float64(since)/float64(recencyHalfLife)), fmt.Sprintf("last watched %s", describeSince(since)))
+		}
+
+		if p, ok := shows.ShowProgress(s); ok && p.Remaining <= nearlyDoneEpisodes {
+			r.add(nearlyDoneWeight, fmt.Sprintf("only %d %s left", p.Remaining, plural(p.Remaining, "episode")))
+		}
+
+		recs = append(recs, r)
+	}
+
+	for _, s := range lib.Catalogue {
+		if s.CurrentSeries != nil || s.CurrentEpisode != nil || !available(s.Provider, prefs) {
+			continue
+		}
+
+		r := newRecommendation(KindShow, s.ID, s.Name, s.Genres, s.Provider, prefs, historyGenres)
+		if total := shows.TotalEpisodes(s); total > 0 && total <= shortSeriesEpisodes {
+			r.add(shortSeriesWeight, fmt.Sprintf("short, at %d %s", total, plural(total, "episode")))
+		}
+
+		recs = append(recs, r)
+	}
+
+	for _, f := range lib.Films {
+		if f.WatchedAt != nil || !available(f.Provider, prefs) {
+			continue
+		}
+
+		r := newRecommendation(KindFilm, f.ID, f.Name, f.Genres, f.Provider, prefs, historyGenres)
+		r.add(filmWeight, "a film you can finish tonight")
+
+		recs = append(recs, r)
+	}
+
+	slices.SortStableFunc(recs, func(a, b Recommendation) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if limit > 0 && len(recs) > limit {
+		recs = recs[:limit]
+	}
+	return recs
+}
+
+// newRecommendation creates a recommendation scored on the genre and provider preferences,
+// which apply equally to shows and films. With several genres, the first favourite genre and
+// the genre watched most often count.
+func newRecommendation(kind Kind, id int, name string, itemGenres []string, provider string, prefs Preferences, historyGenres map[string]float64) Recommendation {
+	r := Recommendation{Kind: kind, ID: id, Name: name, Genres: itemGenres, Provider: provider, Reasons: []string{}}
+
+	for _, g := range itemGenres {
+		if containsFold(prefs.Genres, g) {
+			r.add(favouriteWeight, fmt.Sprintf("%s is one of your favourite genres", g))
+			break
+		}
+	}
+
+	var top string
+	for _, g := range itemGenres {
+		if historyGenres[strings.ToLower(g)] > historyGenres[strings.ToLower(top)] {
+			top = g
+		}
+	}
+	if share := historyGenres[strings.ToLower(top)]; share > 0 {
+		r.add(historyGenreWeight*share, fmt.Sprintf("%.0f%% of what you've watched recently is %s", share*100, top))
+	}
+	if len(prefs.Providers) > 0 {
+		r.add(0, fmt.Sprintf("available on %s", provider))
+	}
+
+	return r
+}
+
+// add increases the score and records why.
+func (r *Recommendation) add(score float64, reason string) {
+	r.Score += score
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// available reports whether the provider is one of the preferred providers, or whether
+// there are no provider preferences at all.
+func available(provider string, prefs Preferences) bool {
+	return len(prefs.Providers) == 0 || containsFold(prefs.Providers, provider)
+}
+
+// genreShares returns the fraction of watch events for each genre, keyed by lower case genre.
+// An episode of a show with several genres counts towards each of them.
+func genreShares(lib Library) map[string]float64 {
+	showGenres := map[int][]string{}
+	for _, list := range [][]data.Show{lib.Catalogue, lib.Current} {
+		for _, s := range list {
+			showGenres[s.ID] = s.Genres
+		}
+	}
+
+	counts := map[string]float64{}
+	var total float64
+	for _, e := range lib.History {
+		gs, ok := showGenres[e.ShowID]
+		if !ok || len(gs) == 0 {
+			continue
+		}
+		for _, g := range gs {
+			counts[strings.ToLower(g)]++
+		}
+		total++
+	}
+
+	for g := range counts {
+		counts[g] /= total
+	}
+	return counts
+}
+
+// lastWatchedByShow returns the most recent watch time for each show ID.
+func lastWatchedByShow(history []data.WatchEvent) map[int]time.Time {
+	last := map[int]time.Time{}
+	for _, e := range history {
+		if e.WatchedAt.After(last[e.ShowID]) {
+			last[e.ShowID] = e.WatchedAt
+		}
+	}
+	return last
+}
+
+// describeSince describes a duration in whole days.
+func describeSince(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "yesterday"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+func containsFold(values []string, v string) bool {
+	return slices.ContainsFunc(values, func(s string) bool {
+		return strings.EqualFold(strings.TrimSpace(s), v)
+	})
+}
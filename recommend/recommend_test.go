@@ -0,0 +1,124 @@
+package recommend
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func names(recs []Recommendation) []string {
+	result := make([]string, len(recs))
+	for i, r := range recs {
+		result[i] = r.Name
+	}
+	return result
+}
+
+func TestRecommend(t *testing.T) {
+	now := time.Date(2025, 11, 15, 20, 0, 0, 0, time.UTC)
+	watchedAt := func(daysAgo int) time.Time { return now.Add(-time.Duration(daysAgo) * 24 * time.Hour) }
+
+	lib := Library{
+		Current: []data.Show{
+			{ID: 1, Name: "Recent Drama", Genres: []string{"Drama"}, Provider: "Netflix", Episodes: []int{10, 10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(4)},
+			{ID: 2, Name: "Stale Comedy", Genres: []string{"Comedy"}, Provider: "BBC iPlayer", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(5)},
+			{ID: 3, Name: "Not Started", Genres: []string{"Comedy"}, Provider: "Netflix", Episodes: []int{6}},
+		},
+		Catalogue: []data.Show{
+			{ID: 4, Name: "Short Thriller", Genres: []string{"Thriller"}, Provider: "Netflix", Episodes: []int{6}},
+			{ID: 5, Name: "Long Thriller", Genres: []string{"Thriller"}, Provider: "Prime Video", Episodes: []int{10, 10}},
+			{ID: 6, Name: "Already Going", Genres: []string{"Drama"}, Provider: "Netflix", Episodes: []int{8}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+		},
+		Films: []data.Film{
+			{ID: 1, Name: "Unwatched Film", Genres: []string{"Thriller"}, Provider: "Disney+"},
+			{ID: 2, Name: "Watched Film", Genres: []string{"Drama"}, Provider: "Netflix", WatchedAt: &now},
+		},
+		History: []data.WatchEvent{
+			{ShowID: 1, Series: 1, Episode: 3, WatchedAt: watchedAt(1)},
+			{ShowID: 1, Series: 1, Episode: 2, WatchedAt: watchedAt(2)},
+			{ShowID: 2, Series: 1, Episode: 4, WatchedAt: watchedAt(28)},
+			{ShowID: 99, Series: 1, Episode: 1, WatchedAt: watchedAt(1)},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		prefs    Preferences
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "no preferences ranks in progress shows first",
+			expected: []string{"Recent Drama", "Stale Comedy", "Unwatched Film", "Short Thriller", "Long Thriller"},
+		},
+		{
+			name:     "favourite genre outweighs recency",
+			prefs:    Preferences{Genres: []string{"comedy"}},
+			expected: []string{"Stale Comedy", "Recent Drama", "Unwatched Film", "Short Thriller", "Long Thriller"},
+		},
+		{
+			name:     "providers filter everything else out",
+			prefs:    Preferences{Providers: []string{"netflix", " Prime Video "}},
+			expected: []string{"Recent Drama", "Short Thriller", "Long Thriller"},
+		},
+		{
+			name:     "limit keeps the top results",
+			limit:    2,
+			expected: []string{"Recent Drama", "Stale Comedy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Recommend(lib, tt.prefs, now, tt.limit)
+			if got := names(result); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRecommendReasons(t *testing.T) {
+	now := time.Date(2025, 11, 15, 20, 0, 0, 0, time.UTC)
+	lib := Library{
+		Current: []data.Show{
+			{ID: 1, Name: "Show A", Genres: []string{"Drama"}, Provider: "Netflix", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+		},
+		History: []data.WatchEvent{
+			{ShowID: 1, Series: 1, Episode: 2, WatchedAt: now.Add(-24 * time.Hour)},
+		},
+	}
+
+	result := Recommend(lib, Preferences{Genres: []string{"Drama"}, Providers: []string{"Netflix"}}, now, 0)
+	if len(result) != 1 {
+		t.Fatalf("expected 1 recommendation, got %d", len(result))
+	}
+
+	r := result[0]
+	if r.Kind != KindShow || r.ID != 1 || r.Series != 1 || r.Episode != 3 {
+		t.Errorf("unexpected recommendation %+v", r)
+	}
+
+	expected := []string{
+		"Drama is one of your favourite genres",
+		"100% of what you've watched recently is Drama",
+		"available on Netflix",
+		"you're part way through, next up is series 1 episode 3",
+		"last watched yesterday",
+		"only 4 episodes left",
+	}
+	if !reflect.DeepEqual(r.Reasons, expected) {
+		t.Errorf("expected reasons %q, got %q", expected, r.Reasons)
+	}
+
+	// favourite 3 + history 2 + in progress 2 + recency 2*0.5^(1/7) + nearly done 1.5
+	if r.Score < 10.3 || r.Score > 10.4 {
+		t.Errorf("expected a score of about 10.35, got %f", r.Score)
+	}
+}
@@ -0,0 +1,224 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"what-to-watch/data"
+)
+
+// Kind is the kind of thing a result is.
+type Kind string
+
+const (
+	KindShow Kind = "show"
+	KindFilm Kind = "film"
+)
+
+// Fields a result can be matched on.
+const (
+	FieldName     = "name"
+	FieldGenre    = "genre"
+	FieldProvider = "provider"
+)
+
+// Result is a show or film matching a search, with how well it matched.
+type Result struct {
+	Kind     Kind     `json:"kind"`
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Genres   []string `json:"genres"`
+	Provider string   `json:"provider"`
+	// Watching is only set for shows currently being watched
+	Watching bool `json:"watching,omitempty"`
+	// Watched is only set for films that have been watched
+	Watched bool `json:"watched,omitempty"`
+	// Match is the field that matched best
+	Match string  `json:"match"`
+	Score float64 `json:"score"`
+}
+
+// scoring weights: a genre or provider match ranks below an equally good name match
+const (
+	nameWeight     = 1.0
+	genreWeight    = 0.8
+	providerWeight = 0.8
+)
+
+// Search returns the shows in the catalogue, the shows being watched and the films, watched or not,
+// whose name, genres or provider match the query, best match first. Matching ignores case and
+// punctuation, and every word of the query must match a word of the same field, either exactly,
+// as the start of the word, or with a typo or two in longer words. A blank query matches nothing.
+func Search(catalogue, current []data.Show, films []data.Film, query string) []Result {
+	tokens := words(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var results []Result
+	watching := map[int]bool{}
+	for _, s := range current {
+		watching[s.ID] = true
+		if r, ok := matchShow(s, tokens); ok {
+			r.Watching = true
+			results = append(results, r)
+		}
+	}
+	for _, s := range catalogue {
+		// a show being watched is only listed once
+		if watching[s.ID] {
+			continue
+		}
+		if r, ok := matchShow(s, tokens); ok {
+			results = append(results, r)
+		}
+	}
+	for _, f := range films {
+		r := Result{Kind: KindFilm, ID: f.ID, Name: f.Name, Genres: f.Genres, Provider: f.Provider, Watched: f.WatchedAt != nil}
+		if r.Match, r.Score = bestMatch(f.Name, f.Genres, f.Provider, tokens); r.Score > 0 {
+			results = append(results, r)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return results
+}
+
+// matchShow scores a show against the query tokens, reporting whether it matched at all.
+func matchShow(s data.Show, tokens []string) (Result, bool) {
+	r := Result{Kind: KindShow, ID: s.ID, Name: s.Name, Genres: s.Genres, Provider: s.Provider}
+	r.Match, r.Score = bestMatch(s.Name, s.Genres, s.Provider, tokens)
+	return r, r.Score > 0
+}
+
+// bestMatch returns the field that matches the query tokens best and its weighted score,
+// or a score of 0 when no field matches.
+func bestMatch(name string, itemGenres []string, provider string, tokens []string) (string, float64) {
+	field, best := "", 0.0
+	consider := func(f string, score float64) {
+		if score > best {
+			field, best = f, score
+		}
+	}
+
+	consider(FieldName, nameWeight*fieldScore(tokens, name))
+	for _, g := range itemGenres {
+		consider(FieldGenre, genreWeight*fieldScore(tokens, g))
+	}
+	consider(FieldProvider, providerWeight*fieldScore(tokens, provider))
+
+	return field, best
+}
+
+// fieldScore is how well the query tokens match a field, from 0 for no match to 1 when the
+// field is exactly the query. Every token must match one of the field's words; fields with
+// words the query doesn't mention score a little lower.
+func fieldScore(tokens []string, field string) float64 {
+	fieldWords := words(field)
+	if len(fieldWords) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, t := range tokens {
+		best := 0.0
+		for _, w := range fieldWords {
+			best = max(best, tokenScore(t, w))
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	coverage := float64(len(tokens)) / float64(max(len(tokens), len(fieldWords)))
+	return total / float64(len(tokens)) * (0.9 + 0.1*coverage)
+}
+
+// tokenScore is how well a query token matches a word: 1 for the same word, 0.9 when the word
+// starts with the token, and less for each typo, or 0 when they don't match. As with a word's start,
+// typos in the start of a longer word score lower than typos in the whole word. Short tokens must
+// match without typos.
+func tokenScore(token, word string) float64 {
+	if token == word {
+		return 1
+	}
+	if strings.HasPrefix(word, token) {
+		return 0.9
+	}
+
+	allowed := typosAllowed(token)
+	if allowed == 0 {
+		return 0
+	}
+
+	score := 0.0
+	t, w := []rune(token), []rune(word)
+	if d := distance(t, w); d <= allowed {
+		score = 0.9 - 0.2*float64(d)
+	}
+
+	// also allow typos in the start of a longer word, so "brakin" finds "breaking"
+	for n := max(1, len(t)-allowed); n <= len(t)+allowed && n < len(w); n++ {
+		if d := distance(t, w[:n]); d <= allowed {
+			score = max(score, 0.8-0.2*float64(d))
+		}
+	}
+
+	return score
+}
+
+// typosAllowed is the number of typos tolerated in a query token of its length.
+func typosAllowed(token string) int {
+	switch n := len([]rune(token)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// distance is the number of single character insertions, deletions, substitutions and swaps
+// of adjacent characters needed to turn a into b.
+func distance(a, b []rune) int {
+	// rows of the dynamic programming table: two back, previous and current
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(b)]
+}
+
+// words splits s into lower case words, dropping punctuation.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,129 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "crown", b: "crown", expected: 0},
+		{a: "crwon", b: "crown", expected: 1},
+		{a: "crow", b: "crown", expected: 1},
+		{a: "crowns", b: "crown", expected: 1},
+		{a: "crewn", b: "crown", expected: 1},
+		{a: "", b: "crown", expected: 5},
+		{a: "kitten", b: "sitting", expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if result := distance([]rune(tt.a), []rune(tt.b)); result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTokenScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		word     string
+		expected float64
+	}{
+		{name: "same word", token: "crown", word: "crown", expected: 1},
+		{name: "start of word", token: "bre", word: "breaking", expected: 0.9},
+		{name: "one typo", token: "crwon", word: "crown", expected: 0.7},
+		{name: "typo in the start of a longer word", token: "brakin", word: "breaking", expected: 0.6},
+		{name: "two typos in a long word", token: "documantery", word: "documentary", expected: 0.5},
+		{name: "too many typos", token: "crane", word: "crown", expected: 0},
+		{name: "short words need no typos", token: "bda", word: "bad", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tokenScore(tt.token, tt.word); result < tt.expected-1e-9 || result > tt.expected+1e-9 {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	series, episode := 1, 1
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+	catalogue := []data.Show{
+		{ID: 1, Name: "Breaking Bad", Genres: []string{"drama", "crime"}, Provider: "Netflix"},
+		{ID: 2, Name: "The Crown", Genres: []string{"drama"}, Provider: "Netflix"},
+		{ID: 3, Name: "Bluey", Genres: []string{"animation"}, Provider: "Disney+"},
+		{ID: 4, Name: "Slow Horses", Genres: []string{"thriller"}, Provider: "Apple TV+"},
+	}
+	current := []data.Show{
+		{ID: 4, Name: "Slow Horses", Genres: []string{"thriller"}, Provider: "Apple TV+", CurrentSeries: &series, CurrentEpisode: &episode},
+	}
+	films := []data.Film{
+		{ID: 1, Name: "The Crown Jewels", Genres: []string{"documentary"}, Provider: "BBC iPlayer"},
+		{ID: 2, Name: "Heat", Genres: []string{"crime"}, Provider: "Netflix", WatchedAt: &watchedAt},
+	}
+
+	type hit struct {
+		kind  Kind
+		id    int
+		match string
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []hit
+	}{
+		{name: "blank query", query: "  ", expected: nil},
+		{name: "exact name first", query: "the crown", expected: []hit{{KindShow, 2, FieldName}, {KindFilm, 1, FieldName}}},
+		{name: "ignores case", query: "BREAKING Bad", expected: []hit{{KindShow, 1, FieldName}}},
+		{name: "short words must be spelt right", query: "breaking bda", expected: nil},
+		{name: "typo in a longer word", query: "braking bad", expected: []hit{{KindShow, 1, FieldName}}},
+		{name: "start of a word", query: "slow hor", expected: []hit{{KindShow, 4, FieldName}}},
+		{name: "genre", query: "crime", expected: []hit{{KindShow, 1, FieldGenre}, {KindFilm, 2, FieldGenre}}},
+		{name: "genre with a typo", query: "documantery", expected: []hit{{KindFilm, 1, FieldGenre}}},
+		{name: "provider ignoring punctuation", query: "disney", expected: []hit{{KindShow, 3, FieldProvider}}},
+		{name: "no match", query: "zombies", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits []hit
+			for _, r := range Search(catalogue, current, films, tt.query) {
+				hits = append(hits, hit{r.Kind, r.ID, r.Match})
+			}
+			if !reflect.DeepEqual(hits, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, hits)
+			}
+		})
+	}
+}
+
+func TestSearchMarksWatchingAndWatched(t *testing.T) {
+	series, episode := 1, 1
+	watchedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+	catalogue := []data.Show{{ID: 1, Name: "Slow Horses"}}
+	current := []data.Show{{ID: 1, Name: "Slow Horses", CurrentSeries: &series, CurrentEpisode: &episode}}
+	films := []data.Film{{ID: 1, Name: "Slow West", WatchedAt: &watchedAt}}
+
+	results := Search(catalogue, current, films, "slow")
+	if len(results) != 2 {
+		t.Fatalf("expected the show being watched to be listed once alongside the film, got %+v", results)
+	}
+	if !results[0].Watching || results[0].Kind != KindShow {
+		t.Errorf("expected the show to be marked as being watched, got %+v", results[0])
+	}
+	if !results[1].Watched || results[1].Kind != KindFilm {
+		t.Errorf("expected the film to be marked as watched, got %+v", results[1])
+	}
+}
@@ -0,0 +1,160 @@
+package shows
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"what-to-watch/data"
+	"what-to-watch/genres"
+)
+
+// ErrInvalidShow is returned when a show being added to or edited in the catalogue fails validation.
+var ErrInvalidShow = errors.New("invalid show")
+
+// ValidateShow checks that a catalogue entry has a name, at least one genre and a provider, at least
+// one series with a positive number of episodes, and no negative runtimes.
+func ValidateShow(show data.Show) error {
+	if strings.TrimSpace(show.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidShow)
+	}
+	if len(genres.Clean(show.Genres)) == 0 {
+		return fmt.Errorf("%w: at least one genre is required", ErrInvalidShow)
+	}
+	if strings.TrimSpace(show.Provider) == "" {
+		return fmt.Errorf("%w: provider is required", ErrInvalidShow)
+	}
+	if len(show.Episodes) == 0 {
+		return fmt.Errorf("%w: at least one series is required", ErrInvalidShow)
+	}
+	for i, n := range show.Episodes {
+		if n < 1 {
+			return fmt.Errorf("%w: series %d must have at least one episode, got %d", ErrInvalidShow, i+1, n)
+		}
+	}
+	if show.Runtime < 0 {
+		return fmt.Errorf("%w: runtime cannot be negative, got %d", ErrInvalidShow, show.Runtime)
+	}
+	if len(show.SeriesRuntimes) > len(show.Episodes) {
+		return fmt.Errorf("%w: %d series runtimes given for %d series", ErrInvalidShow, len(show.SeriesRuntimes), len(show.Episodes))
+	}
+	for i, n := range show.SeriesRuntimes {
+		if n < 0 {
+			return fmt.Errorf("%w: series %d runtime cannot be negative, got %d", ErrInvalidShow, i+1, n)
+		}
+	}
+
+	return nil
+}
+
+// NextShowID returns an ID one higher than last, the highest show ID ever stored, and than
+// any show in the given lists, so that it is unique across the catalogue, currently watching
+// and completed shows and the ID of a deleted show, which the watch history may still refer
+// to, is never reused.
+func NextShowID(last int, lists ...[]data.Show) int {
+	next := last + 1
+	for _, list := range lists {
+		for _, s := range list {
+			if s.ID >= next {
+				next = s.ID + 1
+			}
+		}
+	}
+	return next
+}
+
+// catalogueEntry returns the fields of show that belong in the catalogue, with surrounding
+// whitespace trimmed and any progress cleared.
+func catalogueEntry(id int, show data.Show) data.Show {
+	return data.Show{
+		ID:             id,
+		Name:           strings.TrimSpace(show.Name),
+		Genres:         genres.Clean(show.Genres),
+		Provider:       strings.TrimSpace(show.Provider),
+		Episodes:       append([]int(nil), show.Episodes...),
+		Runtime:        show.Runtime,
+		SeriesRuntimes: append([]int(nil), show.SeriesRuntimes...),
+	}
+}
+
+// checkDuplicateName returns an error if a show other than the one with the given ID
+// already has the given name, whether it is in the catalogue, being watched or completed.
+func checkDuplicateName(catalogue, current, completed []data.Show, id int, name string) error {
+	lists := []struct {
+		shows []data.Show
+		where string
+	}{
+		{catalogue, "in the catalogue"},
+		{current, "being watched"},
+		{completed, "completed"},
+	}
+
+	for _, list := range lists {
+		for _, s := range list.shows {
+			if s.ID != id && strings.EqualFold(s.Name, strings.TrimSpace(name)) {
+				return fmt.Errorf("%w: %s is already %s", ErrInvalidShow, s.Name, list.where)
+			}
+		}
+	}
+	return nil
+}
+
+// AddShow validates the show and appends it to the catalogue with the given ID. The name must
+// not already be used by a show in the catalogue, being watched or completed.
+// It returns the updated catalogue, the added show, and an error.
+func AddShow(catalogue, current, completed []data.Show, id int, show data.Show) ([]data.Show, data.Show, error) {
+	if err := ValidateShow(show); err != nil {
+		return nil, data.Show{}, err
+	}
+	if err := checkDuplicateName(catalogue, current, completed, id, show.Name); err != nil {
+		return nil, data.Show{}, err
+	}
+
+	added := catalogueEntry(id, show)
+	return append(catalogue, added), added, nil
+}
+
+// UpdateShow validates the show and replaces the name, genre, provider and episode counts
+// of the catalogue entry with the given ID. The name must not already be used by another show
+// in the catalogue, being watched or completed.
+// It returns the updated catalogue, the updated show, and an error.
+func UpdateShow(catalogue, current, completed []data.Show, id int, show data.Show) ([]data.Show, data.Show, error) {
+	pos := findShow(catalogue, id)
+	if pos == -1 {
+		return nil, data.Show{}, fmt.Errorf("%w: id %d", ErrShowNotFound, id)
+	}
+	if err := ValidateShow(show); err != nil {
+		return nil, data.Show{}, err
+	}
+	if err := checkDuplicateName(catalogue, current, completed, id, show.Name); err != nil {
+		return nil, data.Show{}, err
+	}
+
+	catalogue[pos] = catalogueEntry(id, show)
+	return catalogue, catalogue[pos], nil
+}
+
+// DeleteShow removes the show with the given ID from the catalogue.
+// It returns the updated catalogue, the removed show, and an error.
+func DeleteShow(catalogue []data.Show, id int) ([]data.Show, data.Show, error) {
+	pos := findShow(catalogue, id)
+	if pos == -1 {
+		return nil, data.Show{}, fmt.Errorf("%w: id %d", ErrShowNotFound, id)
+	}
+
+	removed := catalogue[pos]
+	remaining := make([]data.Show, 0, len(catalogue)-1)
+	remaining = append(remaining, catalogue[:pos]...)
+	remaining = append(remaining, catalogue[pos+1:]...)
+
+	return remaining, removed, nil
+}
+
+// EpisodeRuntime returns the length in minutes of an episode in the given series, using the
+// series runtime when one is set and the show's runtime otherwise. It returns 0 if unknown.
+func EpisodeRuntime(show data.Show, series int) int {
+	if series >= 1 && series <= len(show.SeriesRuntimes) && show.SeriesRuntimes[series-1] > 0 {
+		return show.SeriesRuntimes[series-1]
+	}
+	return show.Runtime
+}
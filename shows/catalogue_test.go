@@ -0,0 +1,252 @@
+package shows
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"what-to-watch/data"
+)
+
+func TestValidateShow(t *testing.T) {
+	valid := data.Show{Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{8, 10}}
+
+	tests := []struct {
+		name        string
+		modify      func(s *data.Show)
+		expectError bool
+	}{
+		{name: "valid show", modify: func(s *data.Show) {}},
+		{name: "missing name", modify: func(s *data.Show) { s.Name = "  " }, expectError: true},
+		{name: "missing genre", modify: func(s *data.Show) { s.Genres = nil }, expectError: true},
+		{name: "missing provider", modify: func(s *data.Show) { s.Provider = "" }, expectError: true},
+		{name: "no series", modify: func(s *data.Show) { s.Episodes = nil }, expectError: true},
+		{name: "series without episodes", modify: func(s *data.Show) { s.Episodes = []int{8, 0} }, expectError: true},
+		{name: "negative episode count", modify: func(s *data.Show) { s.Episodes = []int{-1} }, expectError: true},
+		{name: "runtimes", modify: func(s *data.Show) { s.Runtime = 45; s.SeriesRuntimes = []int{0, 50} }},
+		{name: "negative runtime", modify: func(s *data.Show) { s.Runtime = -1 }, expectError: true},
+		{name: "negative series runtime", modify: func(s *data.Show) { s.SeriesRuntimes = []int{-5} }, expectError: true},
+		{name: "more series runtimes than series", modify: func(s *data.Show) { s.SeriesRuntimes = []int{40, 40, 40} }, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			show := valid
+			show.Episodes = append([]int(nil), valid.Episodes...)
+			tt.modify(&show)
+
+			err := ValidateShow(show)
+			if tt.expectError {
+				if !errors.Is(err, ErrInvalidShow) {
+					t.Errorf("expected ErrInvalidShow, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEpisodeRuntime(t *testing.T) {
+	show := data.Show{Episodes: []int{6, 8, 10}, Runtime: 45, SeriesRuntimes: []int{30, 0}}
+
+	tests := []struct {
+		name     string
+		show     data.Show
+		series   int
+		expected int
+	}{
+		{name: "series runtime", show: show, series: 1, expected: 30},
+		{name: "zero series runtime falls back", show: show, series: 2, expected: 45},
+		{name: "missing series runtime falls back", show: show, series: 3, expected: 45},
+		{name: "unknown", show: data.Show{Episodes: []int{6}}, series: 1, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := EpisodeRuntime(tt.show, tt.series); result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNextShowID(t *testing.T) {
+	catalogue := []data.Show{{ID: 3}, {ID: 1}}
+	current := []data.Show{{ID: 7}}
+	completed := []data.Show{{ID: 5}}
+
+	if got := NextShowID(0, catalogue, current, completed); got != 8 {
+		t.Errorf("expected 8, got %d", got)
+	}
+	// a deleted show had a higher ID than any left
+	if got := NextShowID(9, catalogue, current, completed); got != 10 {
+		t.Errorf("expected 10, got %d", got)
+	}
+	if got := NextShowID(0, nil); got != 1 {
+		t.Errorf("expected 1 for no shows, got %d", got)
+	}
+}
+
+func TestAddShow(t *testing.T) {
+	catalogue := []data.Show{
+		{ID: 1, Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+	}
+	current := []data.Show{
+		{ID: 2, Name: "Show C", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+	}
+	completed := []data.Show{
+		{ID: 3, Name: "Show D", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+	}
+
+	tests := []struct {
+		name        string
+		show        data.Show
+		expected    data.Show
+		expectError bool
+	}{
+		{
+			name:     "adds trimmed show without progress",
+			show:     data.Show{ID: 99, Name: " Show B ", Genres: []string{"comedy "}, Provider: " itvX", Episodes: []int{6, 8}, Runtime: 30, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+			expected: data.Show{ID: 5, Name: "Show B", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{6, 8}, Runtime: 30},
+		},
+		{
+			name:        "duplicate name",
+			show:        data.Show{Name: "show a", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+			expectError: true,
+		},
+		{
+			name:        "name of a show being watched",
+			show:        data.Show{Name: "Show C", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+			expectError: true,
+		},
+		{
+			name:        "name of a completed show",
+			show:        data.Show{Name: "show d", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+			expectError: true,
+		},
+		{
+			name:        "invalid show",
+			show:        data.Show{Name: "Show B"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, added, err := AddShow(append([]data.Show(nil), catalogue...), current, completed, 5, tt.show)
+			if tt.expectError {
+				if !errors.Is(err, ErrInvalidShow) {
+					t.Fatalf("expected ErrInvalidShow, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(added, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, added)
+			}
+			if !reflect.DeepEqual(result, append(catalogue, tt.expected)) {
+				t.Errorf("expected catalogue %+v, got %+v", append(catalogue, tt.expected), result)
+			}
+		})
+	}
+}
+
+func TestUpdateShow(t *testing.T) {
+	catalogue := func() []data.Show {
+		return []data.Show{
+			{ID: 1, Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+			{ID: 2, Name: "Show B", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		id          int
+		show        data.Show
+		expected    []data.Show
+		expectedErr error
+	}{
+		{
+			name: "corrects episode counts",
+			id:   2,
+			show: data.Show{Name: "Show B", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8, 10}},
+			expected: []data.Show{
+				{ID: 1, Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}},
+				{ID: 2, Name: "Show B", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8, 10}},
+			},
+		},
+		{
+			name:        "renaming to another show's name",
+			id:          2,
+			show:        data.Show{Name: "Show A", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8}},
+			expectedErr: ErrInvalidShow,
+		},
+		{
+			name:        "renaming to the name of a show being watched",
+			id:          2,
+			show:        data.Show{Name: "Show C", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8}},
+			expectedErr: ErrInvalidShow,
+		},
+		{
+			name:        "invalid show",
+			id:          2,
+			show:        data.Show{Name: "Show B", Genres: []string{"comedy"}, Provider: "itvX"},
+			expectedErr: ErrInvalidShow,
+		},
+		{
+			name:        "unknown id",
+			id:          3,
+			show:        data.Show{Name: "Show C", Genres: []string{"comedy"}, Provider: "itvX", Episodes: []int{8}},
+			expectedErr: ErrShowNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := []data.Show{{ID: 4, Name: "Show C", Genres: []string{"drama"}, Provider: "Netflix", Episodes: []int{6}}}
+			result, _, err := UpdateShow(catalogue(), current, nil, tt.id, tt.show)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDeleteShow(t *testing.T) {
+	catalogue := []data.Show{
+		{ID: 1, Name: "Show A"},
+		{ID: 2, Name: "Show B"},
+		{ID: 3, Name: "Show C"},
+	}
+
+	result, removed, err := DeleteShow(catalogue, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if removed.Name != "Show B" {
+		t.Errorf("expected Show B to be removed, got %+v", removed)
+	}
+	expected := []data.Show{{ID: 1, Name: "Show A"}, {ID: 3, Name: "Show C"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+
+	if _, _, err := DeleteShow(catalogue, 9); !errors.Is(err, ErrShowNotFound) {
+		t.Errorf("expected ErrShowNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package shows
+
+import (
+	"math"
+
+	"what-to-watch/data"
+)
+
+// TotalEpisodes counts every episode of every series of the show.
+func TotalEpisodes(show data.Show) int {
+	total := 0
+	for _, n := range show.Episodes {
+		total += n
+	}
+	return total
+}
+
+// ShowProgress works out how many episodes of the show have been watched and are left,
+// treating the current episode as the next one to watch. Time remaining is included when
+// every remaining episode has a runtime. It returns false if the show is not being watched
+// or its position is not one of its episodes.
+func ShowProgress(show data.Show) (data.Progress, bool) {
+	if show.CurrentSeries == nil || show.CurrentEpisode == nil {
+		return data.Progress{}, false
+	}
+
+	series, episode := *show.CurrentSeries, *show.CurrentEpisode
+	if series < 1 || series > len(show.Episodes) || episode < 1 || episode > show.Episodes[series-1] {
+		return data.Progress{}, false
+	}
+
+	watched := episode - 1
+	for _, n := range show.Episodes[:series-1] {
+		watched += n
+	}
+
+	total := TotalEpisodes(show)
+	p := data.Progress{
+		Watched:         watched,
+		Remaining:       total - watched,
+		Total:           total,
+		PercentComplete: math.Round(float64(watched)/float64(total)*1000) / 10,
+	}
+
+	minutes := 0
+	for s := series; s <= len(show.Episodes); s++ {
+		runtime := EpisodeRuntime(show, s)
+		if runtime < 1 {
+			return p, true
+		}
+
+		left := show.Episodes[s-1]
+		if s == series {
+			left -= episode - 1
+		}
+		minutes += left * runtime
+	}
+	p.MinutesRemaining = minutes
+	p.HoursRemaining = math.Round(float64(minutes)/60*10) / 10
+
+	return p, true
+}
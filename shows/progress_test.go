@@ -0,0 +1,83 @@
+package shows
+
+import (
+	"reflect"
+	"testing"
+
+	"what-to-watch/data"
+)
+
+func TestShowProgress(t *testing.T) {
+	tests := []struct {
+		name       string
+		show       data.Show
+		expected   data.Progress
+		expectedOK bool
+	}{
+		{
+			name:       "start of first series",
+			show:       data.Show{Episodes: []int{6, 8}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+			expected:   data.Progress{Watched: 0, Remaining: 14, Total: 14, PercentComplete: 0},
+			expectedOK: true,
+		},
+		{
+			name:       "part way through a later series",
+			show:       data.Show{Episodes: []int{6, 8, 10}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(3)},
+			expected:   data.Progress{Watched: 8, Remaining: 16, Total: 24, PercentComplete: 33.3},
+			expectedOK: true,
+		},
+		{
+			name:       "last episode",
+			show:       data.Show{Episodes: []int{6, 8}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(8)},
+			expected:   data.Progress{Watched: 13, Remaining: 1, Total: 14, PercentComplete: 92.9},
+			expectedOK: true,
+		},
+		{
+			name: "time remaining uses series runtimes",
+			show: data.Show{Episodes: []int{6, 4}, Runtime: 30, SeriesRuntimes: []int{0, 45}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(5)},
+			expected: data.Progress{Watched: 4, Remaining: 6, Total: 10, PercentComplete: 40,
+				MinutesRemaining: 240, HoursRemaining: 4},
+			expectedOK: true,
+		},
+		{
+			name:       "time remaining is left out when a runtime is unknown",
+			show:       data.Show{Episodes: []int{6, 4}, SeriesRuntimes: []int{30}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(5)},
+			expected:   data.Progress{Watched: 4, Remaining: 6, Total: 10, PercentComplete: 40},
+			expectedOK: true,
+		},
+		{
+			name: "not being watched",
+			show: data.Show{Episodes: []int{6}},
+		},
+		{
+			name: "series out of range",
+			show: data.Show{Episodes: []int{6}, CurrentSeries: intPtr(3), CurrentEpisode: intPtr(1)},
+		},
+		{
+			name: "episode out of range",
+			show: data.Show{Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ShowProgress(tt.show)
+			if ok != tt.expectedOK {
+				t.Fatalf("expected ok %v, got %v", tt.expectedOK, ok)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTotalEpisodes(t *testing.T) {
+	if got := TotalEpisodes(data.Show{Episodes: []int{6, 8, 10}}); got != 24 {
+		t.Errorf("expected 24, got %d", got)
+	}
+	if got := TotalEpisodes(data.Show{}); got != 0 {
+		t.Errorf("expected 0, got %d", got)
+	}
+}
@@ -1,14 +1,38 @@
 package shows
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"what-to-watch/data"
+	"what-to-watch/genres"
 )
 
+// ErrShowNotFound is returned when no show has the requested ID.
+var ErrShowNotFound = errors.New("show not found")
+
+// ErrInvalidProgress is returned when a series or episode does not exist in a show.
+var ErrInvalidProgress = errors.New("invalid progress")
+
+// ErrAlreadyStarted is returned when starting a show that has already been started.
+var ErrAlreadyStarted = errors.New("show already started")
+
+// findShow returns the position of the show with the given ID, or -1 if there is none.
+func findShow(shows []data.Show, id int) int {
+	for i, s := range shows {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
 // GetCurrentlyWatching returns a slice of shows that the user is currently watching,
-// including their current series and episode information.
+// including their current series and episode information and how far through each show they are.
 func GetCurrentlyWatching(shows []data.Show) ([]data.Show, error) {
 	var watching []data.Show
 	for _, s := range shows {
@@ -28,26 +52,48 @@ func GetCurrentlyWatching(shows []data.Show) ([]data.Show, error) {
 		}
 		s.Episode = episode
 
+		if p, ok := ShowProgress(s); ok {
+			s.Progress = &p
+		}
+
 		watching = append(watching, s)
 	}
 
 	return watching, nil
 }
 
-// MarkEpisodeWatched updates the provided shows slice when the user reports they've
-// watched the next episode of a show. The parameter `listIndex` is 1-based and
-// corresponds to the index displayed by currently watching shows.
-// It returns the updated shows slice, a boolean if the show was completed, and an error.
-func MarkEpisodeWatched(shows []data.Show, listIndex int) ([]data.Show, bool, error) {
+// WatchingShowAt resolves a 1-based index, as displayed alongside the output of
+// GetCurrentlyWatching narrowed by the filter, to the show at that position. Finished
+// shows and shows not matching the filter are skipped exactly as the listing skips
+// them, so the index always refers to the same show the user saw.
+func WatchingShowAt(shows []data.Show, filter Filter, listIndex int) (data.Show, error) {
 	if listIndex <= 0 {
-		return nil, false, fmt.Errorf("invalid index: %d", listIndex)
+		return data.Show{}, fmt.Errorf("%w: invalid index %d", ErrShowNotFound, listIndex)
 	}
 
-	if listIndex > len(shows) {
-		return nil, false, fmt.Errorf("index out of range")
+	watching, err := GetCurrentlyWatching(shows)
+	if err != nil {
+		return data.Show{}, err
 	}
+	watching = Matching(watching, filter)
 
-	s := &shows[listIndex-1]
+	if listIndex > len(watching) {
+		return data.Show{}, fmt.Errorf("%w: index %d out of range", ErrShowNotFound, listIndex)
+	}
+
+	return watching[listIndex-1], nil
+}
+
+// MarkEpisodeWatched updates the provided shows slice when the user reports they've
+// watched the next episode of the show with the given ID.
+// It returns the updated shows slice, a boolean if the show was completed, and an error.
+func MarkEpisodeWatched(shows []data.Show, id int) ([]data.Show, bool, error) {
+	pos := findShow(shows, id)
+	if pos == -1 {
+		return nil, false, fmt.Errorf("%w: id %d", ErrShowNotFound, id)
+	}
+
+	s := &shows[pos]
 
 	if s.CurrentSeries == nil || s.CurrentEpisode == nil {
 		return nil, false, fmt.Errorf("selected show is not currently being watched")
@@ -87,30 +133,289 @@ func MarkEpisodeWatched(shows []data.Show, listIndex int) ([]data.Show, bool, er
 	return shows, false, nil
 }
 
-// GetUniqueGenres returns a sorted list of unique genres from all shows
-func GetUniqueGenres(shows []data.Show) []string {
-	genreMap := make(map[string]bool)
-	for _, s := range shows {
-		if s.Genre != "" {
-			genreMap[s.Genre] = true
+// MarkEpisodesWatched marks the next count episodes of the show with the given ID as watched,
+// rolling across series boundaries exactly as repeated calls to MarkEpisodeWatched would and
+// stopping early if the show is finished.
+// It returns the updated shows slice, the show as it was before each episode was watched
+// (one entry per episode actually watched), a boolean if the show was completed, and an error.
+func MarkEpisodesWatched(shows []data.Show, id, count int) ([]data.Show, []data.Show, bool, error) {
+	if count < 1 {
+		return nil, nil, false, fmt.Errorf("%w: count must be at least 1, got %d", ErrInvalidProgress, count)
+	}
+
+	pos := findShow(shows, id)
+	if pos == -1 {
+		return nil, nil, false, fmt.Errorf("%w: id %d", ErrShowNotFound, id)
+	}
+
+	var watched []data.Show
+	for range count {
+		before := shows[pos]
+
+		var isCompleted bool
+		var err error
+		shows, isCompleted, err = MarkEpisodeWatched(shows, id)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		watched = append(watched, before)
+		if isCompleted {
+			return shows, watched, true, nil
 		}
 	}
 
-	var genres []string
-	for genre := range genreMap {
-		genres = append(genres, genre)
+	return shows, watched, false, nil
+}
+
+// SetProgress moves the show with the given ID straight to the given series and episode,
+// which must exist in the show's Episodes slice.
+// It returns the updated shows slice, the show as it was before the move, the updated show, and an error.
+func SetProgress(shows []data.Show, id, series, episode int) ([]data.Show, data.Show, data.Show, error) {
+	pos := findShow(shows, id)
+	if pos == -1 {
+		return nil, data.Show{}, data.Show{}, fmt.Errorf("%w: id %d", ErrShowNotFound, id)
 	}
-	return genres
+
+	s := &shows[pos]
+
+	if series < 1 || series > len(s.Episodes) {
+		return nil, data.Show{}, data.Show{}, fmt.Errorf("%w: %s has %d series, got series %d", ErrInvalidProgress, s.Name, len(s.Episodes), series)
+	}
+	if episode < 1 || episode > s.Episodes[series-1] {
+		return nil, data.Show{}, data.Show{}, fmt.Errorf("%w: series %d of %s has %d episodes, got episode %d", ErrInvalidProgress, series, s.Name, s.Episodes[series-1], episode)
+	}
+
+	previous := *s
+	s.CurrentSeries = &series
+	s.CurrentEpisode = &episode
+	return shows, previous, *s, nil
 }
 
-// GetUnwatchedShowsByGenre returns all shows from a given genre that haven't been watched
+// RestoreProgress puts the show with the given ID back at the given series and episode,
+// reversing one or more calls to MarkEpisodeWatched. A show that has since been archived
+// is moved out of the completed shows and back into the currently watching shows.
+// It returns the updated currently watching and completed slices, the restored show, and an error.
+func RestoreProgress(current, completed []data.Show, id, series, episode int) ([]data.Show, []data.Show, data.Show, error) {
+	if pos := findShow(current, id); pos != -1 {
+		current[pos].CurrentSeries = &series
+		current[pos].CurrentEpisode = &episode
+		return current, completed, current[pos], nil
+	}
+
+	pos := findShow(completed, id)
+	if pos == -1 {
+		return nil, nil, data.Show{}, fmt.Errorf("%w: id %d", ErrShowNotFound, id)
+	}
+
+	show := completed[pos]
+	show.CurrentSeries = &series
+	show.CurrentEpisode = &episode
+	show.CompletedAt = nil
+
+	remaining := make([]data.Show, 0, len(completed)-1)
+	remaining = append(remaining, completed[:pos]...)
+	remaining = append(remaining, completed[pos+1:]...)
+
+	return append(current, show), remaining, show, nil
+}
+
+// ArchiveCompleted moves every finished show (i.e., shows with neither CurrentSeries nor
+// CurrentEpisode set) out of the currently watching shows and into the completed shows,
+// stamping each with the provided completion time.
+// It returns the updated currently watching and completed slices.
+func ArchiveCompleted(current, completed []data.Show, completedAt time.Time) ([]data.Show, []data.Show) {
+	remaining := make([]data.Show, 0, len(current))
+	for _, s := range current {
+		if s.CurrentSeries != nil || s.CurrentEpisode != nil {
+			remaining = append(remaining, s)
+			continue
+		}
+
+		at := completedAt
+		s.CompletedAt = &at
+		completed = append(completed, s)
+	}
+
+	return remaining, completed
+}
+
+// GenreCount is how many unwatched shows, shows being watched and unwatched films have a genre.
+type GenreCount struct {
+	Genre     string `json:"genre"`
+	Unwatched int    `json:"unwatched"`
+	Watching  int    `json:"watching"`
+	Films     int    `json:"films"`
+}
+
+// GetGenreCounts counts the unwatched shows in the catalogue, the shows being watched and the
+// unwatched films for every genre any of them has, sorted by genre. Genres that differ only in
+// case are counted together under the first spelling seen.
+func GetGenreCounts(catalogue, current []data.Show, films []data.Film) []GenreCount {
+	tallies := countBy(catalogue, current, films,
+		func(s data.Show) []string { return s.Genres },
+		func(f data.Film) []string { return f.Genres })
+
+	counts := make([]GenreCount, len(tallies))
+	for i, t := range tallies {
+		counts[i] = GenreCount{Genre: t.name, Unwatched: t.unwatched, Watching: t.watching, Films: t.films}
+	}
+	return counts
+}
+
+// ProviderCount is how many unwatched shows, shows being watched and unwatched films are on a provider.
+type ProviderCount struct {
+	Provider  string `json:"provider"`
+	Unwatched int    `json:"unwatched"`
+	Watching  int    `json:"watching"`
+	Films     int    `json:"films"`
+}
+
+// GetProviderCounts counts the unwatched shows in the catalogue, the shows being watched and the
+// unwatched films on every provider any of them is on, sorted by provider. Providers that differ
+// only in case are counted together under the first spelling seen.
+func GetProviderCounts(catalogue, current []data.Show, films []data.Film) []ProviderCount {
+	tallies := countBy(catalogue, current, films,
+		func(s data.Show) []string { return []string{s.Provider} },
+		func(f data.Film) []string { return []string{f.Provider} })
+
+	counts := make([]ProviderCount, len(tallies))
+	for i, t := range tallies {
+		counts[i] = ProviderCount{Provider: t.name, Unwatched: t.unwatched, Watching: t.watching, Films: t.films}
+	}
+	return counts
+}
+
+// tally is the number of unwatched shows, shows being watched and unwatched films with a name.
+type tally struct {
+	name                       string
+	unwatched, watching, films int
+}
+
+// countBy tallies the unwatched shows in the catalogue, the shows being watched and the unwatched
+// films under each of the names returned for them, sorted by name ignoring case.
+func countBy(catalogue, current []data.Show, films []data.Film, showNames func(data.Show) []string, filmNames func(data.Film) []string) []tally {
+	tallies := map[string]*tally{}
+	tallyFor := func(name string) *tally {
+		key := strings.ToLower(name)
+		if tallies[key] == nil {
+			tallies[key] = &tally{name: name}
+		}
+		return tallies[key]
+	}
+
+	for _, s := range GetUnwatchedShows(catalogue) {
+		for _, name := range genres.Clean(showNames(s)) {
+			tallyFor(name).unwatched++
+		}
+	}
+	for _, s := range current {
+		if s.CurrentSeries == nil && s.CurrentEpisode == nil {
+			continue
+		}
+		for _, name := range genres.Clean(showNames(s)) {
+			tallyFor(name).watching++
+		}
+	}
+	for _, f := range films {
+		if f.WatchedAt != nil {
+			continue
+		}
+		for _, name := range genres.Clean(filmNames(f)) {
+			tallyFor(name).films++
+		}
+	}
+
+	sorted := make([]tally, 0, len(tallies))
+	for _, t := range tallies {
+		sorted = append(sorted, *t)
+	}
+	slices.SortFunc(sorted, func(a, b tally) int {
+		return strings.Compare(strings.ToLower(a.name), strings.ToLower(b.name))
+	})
+	return sorted
+}
+
+// Filter narrows a list of shows. Empty fields match every show.
+type Filter struct {
+	// Genres keeps shows with any of the genres, or all of them when MatchAll is true
+	Genres   []string
+	MatchAll bool
+	// Providers keeps shows on any of the providers
+	Providers []string
+	// SubscribedOnly keeps shows on providers with an active subscription. The handlers
+	// resolve it into Providers, as the subscriptions are not known here
+	SubscribedOnly bool
+}
+
+// GetUnwatchedShowsMatching returns the shows that haven't been started and match the filter,
+// in their original order
+func GetUnwatchedShowsMatching(shows []data.Show, filter Filter) []data.Show {
+	return Matching(GetUnwatchedShows(shows), filter)
+}
+
+// Matching returns the shows that match the filter, in their original order. Genres and
+// providers are matched case-insensitively.
+func Matching(shows []data.Show, filter Filter) []data.Show {
+	var matched []data.Show
+	for _, s := range shows {
+		if !genres.Matches(s.Genres, filter.Genres, filter.MatchAll) {
+			continue
+		}
+		if len(filter.Providers) > 0 && !slices.ContainsFunc(filter.Providers, func(p string) bool {
+			return strings.EqualFold(strings.TrimSpace(p), strings.TrimSpace(s.Provider))
+		}) {
+			continue
+		}
+		matched = append(matched, s)
+	}
+	return matched
+}
+
+// GetUnwatchedShows returns all shows that haven't been started
 // (i.e., shows without CurrentSeries and CurrentEpisode set)
-func GetUnwatchedShowsByGenre(shows []data.Show, genre string) []data.Show {
+func GetUnwatchedShows(shows []data.Show) []data.Show {
 	var unwatched []data.Show
 	for _, s := range shows {
-		if s.Genre == genre && s.CurrentSeries == nil && s.CurrentEpisode == nil {
+		if s.CurrentSeries == nil && s.CurrentEpisode == nil {
 			unwatched = append(unwatched, s)
 		}
 	}
 	return unwatched
 }
+
+// StartWatching moves the show with the given ID from the catalogue into the currently
+// watching shows, starting it at series 1 episode 1.
+// It returns the updated catalogue, the updated currently watching shows, the started show, and an error.
+func StartWatching(catalogue, current []data.Show, id int) ([]data.Show, []data.Show, data.Show, error) {
+	pos := findShow(catalogue, id)
+	if pos == -1 {
+		return nil, nil, data.Show{}, fmt.Errorf("%w: id %d", ErrShowNotFound, id)
+	}
+
+	show := catalogue[pos]
+	if show.CurrentSeries != nil || show.CurrentEpisode != nil {
+		return nil, nil, data.Show{}, fmt.Errorf("%w: %s has already been started", ErrAlreadyStarted, show.Name)
+	}
+	if len(show.Episodes) == 0 {
+		return nil, nil, data.Show{}, fmt.Errorf("%w: selected show has no episodes", ErrInvalidShow)
+	}
+
+	for _, c := range current {
+		if c.ID == show.ID || c.Name == show.Name {
+			return nil, nil, data.Show{}, fmt.Errorf("%w: already watching %s", ErrAlreadyStarted, show.Name)
+		}
+	}
+
+	series, episode := 1, 1
+	show.CurrentSeries = &series
+	show.CurrentEpisode = &episode
+
+	updatedCatalogue := make([]data.Show, 0, len(catalogue)-1)
+	updatedCatalogue = append(updatedCatalogue, catalogue[:pos]...)
+	updatedCatalogue = append(updatedCatalogue, catalogue[pos+1:]...)
+
+	updatedCurrent := append(current, show)
+
+	return updatedCatalogue, updatedCurrent, show, nil
+}
@@ -1,8 +1,11 @@
 package shows
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"what-to-watch/data"
 )
@@ -21,23 +24,33 @@ func TestGetCurrentlyWatching(t *testing.T) {
 		{
 			name: "no currently watching shows",
 			shows: []data.Show{
-				{Name: "Show A", Genre: "Drama"},
-				{Name: "Show B", Genre: "Comedy"},
+				{Name: "Show A", Genres: []string{"Drama"}},
+				{Name: "Show B", Genres: []string{"Comedy"}},
 			},
 			expected: nil,
 		},
 		{
 			name: "some currently watching shows",
 			shows: []data.Show{
-				{Name: "Show A", Genre: "Drama", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
-				{Name: "Show B", Genre: "Comedy"},
-				{Name: "Show C", Genre: "Sci-Fi", CurrentSeries: intPtr(2)},
-				{Name: "Show D", Genre: "Horror", CurrentEpisode: intPtr(3)},
+				{Name: "Show A", Genres: []string{"Drama"}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+				{Name: "Show B", Genres: []string{"Comedy"}},
+				{Name: "Show C", Genres: []string{"Sci-Fi"}, CurrentSeries: intPtr(2)},
+				{Name: "Show D", Genres: []string{"Horror"}, CurrentEpisode: intPtr(3)},
 			},
 			expected: []data.Show{
-				{Name: "Show A", Genre: "Drama", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2), Series: "1", Episode: "2"},
-				{Name: "Show C", Genre: "Sci-Fi", CurrentSeries: intPtr(2), CurrentEpisode: nil, Series: "2", Episode: "-"},
-				{Name: "Show D", Genre: "Horror", CurrentSeries: nil, CurrentEpisode: intPtr(3), Series: "-", Episode: "3"},
+				{Name: "Show A", Genres: []string{"Drama"}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2), Series: "1", Episode: "2"},
+				{Name: "Show C", Genres: []string{"Sci-Fi"}, CurrentSeries: intPtr(2), CurrentEpisode: nil, Series: "2", Episode: "-"},
+				{Name: "Show D", Genres: []string{"Horror"}, CurrentSeries: nil, CurrentEpisode: intPtr(3), Series: "-", Episode: "3"},
+			},
+		},
+		{
+			name: "progress is included",
+			shows: []data.Show{
+				{Name: "Show A", Episodes: []int{10, 10}, Runtime: 30, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(6)},
+			},
+			expected: []data.Show{
+				{Name: "Show A", Episodes: []int{10, 10}, Runtime: 30, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(6), Series: "2", Episode: "6",
+					Progress: &data.Progress{Watched: 15, Remaining: 5, Total: 20, PercentComplete: 75, MinutesRemaining: 150, HoursRemaining: 2.5}},
 			},
 		},
 	}
@@ -60,11 +73,57 @@ func TestGetCurrentlyWatching(t *testing.T) {
 	}
 }
 
+func TestWatchingShowAt(t *testing.T) {
+	// finished shows (no current series or episode) are hidden by GetCurrentlyWatching,
+	// so indices must skip them rather than address the raw slice
+	shows := []data.Show{
+		{ID: 1, Name: "Show A", Episodes: []int{10}},
+		{ID: 2, Name: "Show B", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(4)},
+		{ID: 3, Name: "Show C", Episodes: []int{10}},
+		{ID: 4, Name: "Show D", Episodes: []int{10}, Provider: "Netflix", CurrentSeries: intPtr(2), CurrentEpisode: intPtr(1)},
+	}
+
+	tests := []struct {
+		name        string
+		filter      Filter
+		listIndex   int
+		expectedID  int
+		expectError bool
+	}{
+		{name: "first watching show skips finished show", listIndex: 1, expectedID: 2},
+		{name: "second watching show skips finished shows", listIndex: 2, expectedID: 4},
+		{name: "invalid (non-positive) index", listIndex: 0, expectError: true},
+		{name: "index beyond watching shows", listIndex: 3, expectError: true},
+		{name: "index into filtered list", filter: Filter{Providers: []string{"Netflix"}}, listIndex: 1, expectedID: 4},
+		{name: "index beyond filtered list", filter: Filter{Providers: []string{"Netflix"}}, listIndex: 2, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := WatchingShowAt(shows, tt.filter, tt.listIndex)
+			if tt.expectError {
+				if !errors.Is(err, ErrShowNotFound) {
+					t.Fatalf("expected ErrShowNotFound, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.ID != tt.expectedID {
+				t.Errorf("expected show %d, got %d", tt.expectedID, result.ID)
+			}
+		})
+	}
+}
+
 func TestMarkEpisodeWatched(t *testing.T) {
 	tests := []struct {
 		name           string
 		shows          []data.Show
-		listIndex      int
+		id             int
 		expected       []data.Show
 		expectedFinish bool
 		expectError    bool
@@ -72,11 +131,11 @@ func TestMarkEpisodeWatched(t *testing.T) {
 		{
 			name: "increment episode within series",
 			shows: []data.Show{
-				{Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
 			},
-			listIndex: 1,
+			id: 1,
 			expected: []data.Show{
-				{Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
 			},
 			expectedFinish: false,
 			expectError:    false,
@@ -84,11 +143,11 @@ func TestMarkEpisodeWatched(t *testing.T) {
 		{
 			name: "rollover to next series",
 			shows: []data.Show{
-				{Name: "Show B", Episodes: []int{2, 3}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+				{ID: 2, Name: "Show B", Episodes: []int{2, 3}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
 			},
-			listIndex: 1,
+			id: 2,
 			expected: []data.Show{
-				{Name: "Show B", Episodes: []int{2, 3}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(1)},
+				{ID: 2, Name: "Show B", Episodes: []int{2, 3}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(1)},
 			},
 			expectedFinish: false,
 			expectError:    false,
@@ -96,29 +155,29 @@ func TestMarkEpisodeWatched(t *testing.T) {
 		{
 			name: "finish show when past last series",
 			shows: []data.Show{
-				{Name: "Show C", Episodes: []int{1, 1}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(1)},
+				{ID: 3, Name: "Show C", Episodes: []int{1, 1}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(1)},
 			},
-			listIndex: 1,
+			id: 3,
 			expected: []data.Show{
-				{Name: "Show C", Episodes: []int{1, 1}, CurrentSeries: nil, CurrentEpisode: nil},
+				{ID: 3, Name: "Show C", Episodes: []int{1, 1}, CurrentSeries: nil, CurrentEpisode: nil},
 			},
 			expectedFinish: true,
 			expectError:    false,
 		},
 		{
-			name:           "invalid (non-positive) index",
+			name:           "no shows",
 			shows:          []data.Show{},
-			listIndex:      0,
+			id:             1,
 			expected:       nil,
 			expectedFinish: false,
 			expectError:    true,
 		},
 		{
-			name: "index out of range",
+			name: "id not found",
 			shows: []data.Show{
-				{Name: "Show D", Episodes: []int{2}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+				{ID: 4, Name: "Show D", Episodes: []int{2}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
 			},
-			listIndex:      2,
+			id:             2,
 			expected:       nil,
 			expectedFinish: false,
 			expectError:    true,
@@ -126,9 +185,9 @@ func TestMarkEpisodeWatched(t *testing.T) {
 		{
 			name: "selected show not marked as watching",
 			shows: []data.Show{
-				{Name: "Show E", Episodes: []int{3}},
+				{ID: 5, Name: "Show E", Episodes: []int{3}},
 			},
-			listIndex:      1,
+			id:             5,
 			expected:       nil,
 			expectedFinish: false,
 			expectError:    true,
@@ -136,9 +195,9 @@ func TestMarkEpisodeWatched(t *testing.T) {
 		{
 			name: "selected show not marked as watching for series only",
 			shows: []data.Show{
-				{Name: "Show E", Episodes: []int{3}, CurrentEpisode: intPtr(1)},
+				{ID: 5, Name: "Show E", Episodes: []int{3}, CurrentEpisode: intPtr(1)},
 			},
-			listIndex:      1,
+			id:             5,
 			expected:       nil,
 			expectedFinish: false,
 			expectError:    true,
@@ -146,9 +205,9 @@ func TestMarkEpisodeWatched(t *testing.T) {
 		{
 			name: "selected show not marked as watching for episode only",
 			shows: []data.Show{
-				{Name: "Show E", Episodes: []int{3}, CurrentSeries: intPtr(1)},
+				{ID: 5, Name: "Show E", Episodes: []int{3}, CurrentSeries: intPtr(1)},
 			},
-			listIndex:      1,
+			id:             5,
 			expected:       nil,
 			expectedFinish: false,
 			expectError:    true,
@@ -157,7 +216,7 @@ func TestMarkEpisodeWatched(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, finish, err := MarkEpisodeWatched(tt.shows, tt.listIndex)
+			result, finish, err := MarkEpisodeWatched(tt.shows, tt.id)
 			if tt.expectError {
 				if err == nil {
 					t.Fatalf("expected error but got none")
@@ -180,117 +239,589 @@ func TestMarkEpisodeWatched(t *testing.T) {
 	}
 }
 
-func TestGetUniqueGenres(t *testing.T) {
+func TestArchiveCompleted(t *testing.T) {
+	completedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+	earlier := time.Date(2025, 10, 1, 20, 30, 0, 0, time.UTC)
+
 	tests := []struct {
-		name     string
-		shows    []data.Show
-		expected []string
+		name              string
+		current           []data.Show
+		completed         []data.Show
+		expectedCurrent   []data.Show
+		expectedCompleted []data.Show
 	}{
 		{
-			name:     "no shows",
-			shows:    []data.Show{},
-			expected: []string(nil),
+			name: "nothing finished",
+			current: []data.Show{
+				{Name: "Show A", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			completed: nil,
+			expectedCurrent: []data.Show{
+				{Name: "Show A", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			expectedCompleted: nil,
 		},
 		{
-			name: "single genre",
+			name: "finished show is moved and stamped",
+			current: []data.Show{
+				{Name: "Show A", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+				{Name: "Show B"},
+			},
+			completed: []data.Show{
+				{Name: "Show C", CompletedAt: &earlier},
+			},
+			expectedCurrent: []data.Show{
+				{Name: "Show A", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			expectedCompleted: []data.Show{
+				{Name: "Show C", CompletedAt: &earlier},
+				{Name: "Show B", CompletedAt: &completedAt},
+			},
+		},
+		{
+			name: "partially set progress is not treated as finished",
+			current: []data.Show{
+				{Name: "Show A", CurrentSeries: intPtr(2)},
+			},
+			completed: nil,
+			expectedCurrent: []data.Show{
+				{Name: "Show A", CurrentSeries: intPtr(2)},
+			},
+			expectedCompleted: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, completed := ArchiveCompleted(tt.current, tt.completed, completedAt)
+
+			if !reflect.DeepEqual(current, tt.expectedCurrent) {
+				t.Errorf("expected current %+v, got %+v", tt.expectedCurrent, current)
+			}
+
+			if !reflect.DeepEqual(completed, tt.expectedCompleted) {
+				t.Errorf("expected completed %+v, got %+v", tt.expectedCompleted, completed)
+			}
+		})
+	}
+}
+
+func TestMarkEpisodesWatched(t *testing.T) {
+	tests := []struct {
+		name           string
+		shows          []data.Show
+		id             int
+		count          int
+		expected       []data.Show
+		expectedPlaces []string
+		expectedFinish bool
+		expectedErr    error
+	}{
+		{
+			name: "advances within a series",
 			shows: []data.Show{
-				{Name: "Show A", Genre: "Drama"},
-				{Name: "Show B", Genre: "Drama"},
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			id: 1, count: 4,
+			expected: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(6)},
 			},
-			expected: []string{"Drama"},
+			expectedPlaces: []string{"S1E2", "S1E3", "S1E4", "S1E5"},
 		},
 		{
-			name: "multiple genres",
+			name: "rolls across series boundaries",
 			shows: []data.Show{
-				{Name: "Show A", Genre: "Drama"},
-				{Name: "Show B", Genre: "Comedy"},
-				{Name: "Show C", Genre: "Sci-Fi"},
-				{Name: "Show D", Genre: "Drama"},
+				{ID: 1, Name: "Show A", Episodes: []int{3, 1, 4}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
 			},
-			expected: []string{"Drama", "Comedy", "Sci-Fi"},
+			id: 1, count: 3,
+			expected: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{3, 1, 4}, CurrentSeries: intPtr(3), CurrentEpisode: intPtr(2)},
+			},
+			expectedPlaces: []string{"S1E3", "S2E1", "S3E1"},
 		},
 		{
-			name: "empty genre is ignored",
+			name: "stops when the show is finished",
 			shows: []data.Show{
-				{Name: "Show A", Genre: "Drama"},
-				{Name: "Show B", Genre: ""},
+				{ID: 1, Name: "Show A", Episodes: []int{3, 2}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(1)},
+			},
+			id: 1, count: 5,
+			expected: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{3, 2}},
 			},
-			expected: []string{"Drama"},
+			expectedPlaces: []string{"S2E1", "S2E2"},
+			expectedFinish: true,
+		},
+		{
+			name:  "zero count",
+			shows: []data.Show{{ID: 1, Name: "Show A", Episodes: []int{3}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)}},
+			id:    1, count: 0,
+			expectedErr: ErrInvalidProgress,
+		},
+		{
+			name:  "unknown id",
+			shows: []data.Show{{ID: 1, Name: "Show A", Episodes: []int{3}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)}},
+			id:    2, count: 1,
+			expectedErr: ErrShowNotFound,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GetUniqueGenres(tt.shows)
-			// Note: order of genres is not guaranteed, so we use a map-based comparison
-			if len(result) != len(tt.expected) {
-				t.Errorf("expected %d genres, got %d", len(tt.expected), len(result))
+			result, watched, finish, err := MarkEpisodesWatched(tt.shows, tt.id, tt.count)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+			if finish != tt.expectedFinish {
+				t.Errorf("expected finish %v, got %v", tt.expectedFinish, finish)
+			}
+
+			var places []string
+			for _, w := range watched {
+				places = append(places, fmt.Sprintf("S%dE%d", *w.CurrentSeries, *w.CurrentEpisode))
+			}
+			if !reflect.DeepEqual(places, tt.expectedPlaces) {
+				t.Errorf("expected watched %v, got %v", tt.expectedPlaces, places)
+			}
+		})
+	}
+}
+
+func TestSetProgress(t *testing.T) {
+	tests := []struct {
+		name        string
+		shows       []data.Show
+		id          int
+		series      int
+		episode     int
+		expected    []data.Show
+		expectedErr error
+	}{
+		{
+			name: "jumps forward across series",
+			shows: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10, 8, 6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			id: 1, series: 3, episode: 5,
+			expected: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10, 8, 6}, CurrentSeries: intPtr(3), CurrentEpisode: intPtr(5)},
+			},
+		},
+		{
+			name: "jumps backwards",
+			shows: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10, 8}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(4)},
+			},
+			id: 1, series: 1, episode: 1,
+			expected: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10, 8}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+			},
+		},
+		{
+			name: "last episode of last series",
+			shows: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10, 8}},
+			},
+			id: 1, series: 2, episode: 8,
+			expected: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10, 8}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(8)},
+			},
+		},
+		{
+			name:  "series out of range",
+			shows: []data.Show{{ID: 1, Name: "Show A", Episodes: []int{10, 8}}},
+			id:    1, series: 3, episode: 1,
+			expectedErr: ErrInvalidProgress,
+		},
+		{
+			name:  "episode out of range",
+			shows: []data.Show{{ID: 1, Name: "Show A", Episodes: []int{10, 8}}},
+			id:    1, series: 2, episode: 9,
+			expectedErr: ErrInvalidProgress,
+		},
+		{
+			name:  "zero episode",
+			shows: []data.Show{{ID: 1, Name: "Show A", Episodes: []int{10, 8}}},
+			id:    1, series: 1, episode: 0,
+			expectedErr: ErrInvalidProgress,
+		},
+		{
+			name:  "unknown id",
+			shows: []data.Show{{ID: 1, Name: "Show A", Episodes: []int{10, 8}}},
+			id:    2, series: 1, episode: 1,
+			expectedErr: ErrShowNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var previous data.Show
+			if pos := findShow(tt.shows, tt.id); pos != -1 {
+				previous = tt.shows[pos]
+			}
+
+			result, before, show, err := SetProgress(tt.shows, tt.id, tt.series, tt.episode)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
 			}
-			resultMap := make(map[string]bool)
-			for _, g := range result {
-				resultMap[g] = true
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
 			}
-			for _, g := range tt.expected {
-				if !resultMap[g] {
-					t.Errorf("expected genre %s not found in result", g)
+			if !reflect.DeepEqual(show, tt.expected[0]) {
+				t.Errorf("expected show %+v, got %+v", tt.expected[0], show)
+			}
+			if !reflect.DeepEqual(before, previous) {
+				t.Errorf("expected previous show %+v, got %+v", previous, before)
+			}
+		})
+	}
+}
+
+func TestRestoreProgress(t *testing.T) {
+	completedAt := time.Date(2025, 11, 1, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		current           []data.Show
+		completed         []data.Show
+		id                int
+		series            int
+		episode           int
+		expectedCurrent   []data.Show
+		expectedCompleted []data.Show
+		expectError       bool
+	}{
+		{
+			name: "steps back within a series",
+			current: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+			},
+			id: 1, series: 1, episode: 2,
+			expectedCurrent: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+		},
+		{
+			name: "steps back across a series rollover",
+			current: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10, 8}, CurrentSeries: intPtr(2), CurrentEpisode: intPtr(1)},
+			},
+			id: 1, series: 1, episode: 10,
+			expectedCurrent: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10, 8}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(10)},
+			},
+		},
+		{
+			name: "completed show is moved back into current",
+			current: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+			},
+			completed: []data.Show{
+				{ID: 2, Name: "Show B", Episodes: []int{6}, CompletedAt: &completedAt},
+				{ID: 3, Name: "Show C", Episodes: []int{4}, CompletedAt: &completedAt},
+			},
+			id: 2, series: 1, episode: 6,
+			expectedCurrent: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+				{ID: 2, Name: "Show B", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(6)},
+			},
+			expectedCompleted: []data.Show{
+				{ID: 3, Name: "Show C", Episodes: []int{4}, CompletedAt: &completedAt},
+			},
+		},
+		{
+			name: "unknown id",
+			current: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{10}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+			},
+			id: 9, series: 1, episode: 1,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, completed, _, err := RestoreProgress(tt.current, tt.completed, tt.id, tt.series, tt.episode)
+			if tt.expectError {
+				if !errors.Is(err, ErrShowNotFound) {
+					t.Fatalf("expected ErrShowNotFound, got %v", err)
 				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(current, tt.expectedCurrent) {
+				t.Errorf("expected current %+v, got %+v", tt.expectedCurrent, current)
+			}
+
+			if len(completed) != len(tt.expectedCompleted) || (len(completed) > 0 && !reflect.DeepEqual(completed, tt.expectedCompleted)) {
+				t.Errorf("expected completed %+v, got %+v", tt.expectedCompleted, completed)
 			}
 		})
 	}
 }
 
-func TestGetUnwatchedShowsByGenre(t *testing.T) {
+func TestGetGenreCounts(t *testing.T) {
+	watchedAt := time.Date(2025, 11, 1, 20, 0, 0, 0, time.UTC)
+	catalogue := []data.Show{
+		{Name: "Show A", Genres: []string{"drama", "crime"}},
+		{Name: "Show B", Genres: []string{"comedy"}},
+		{Name: "Show C", Genres: []string{"drama"}},
+	}
+	current := []data.Show{
+		{Name: "Show D", Genres: []string{"Comedy"}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+		{Name: "Show E", Genres: []string{"horror"}},
+	}
+	films := []data.Film{
+		{Name: "Film A", Genres: []string{"drama", "war"}},
+		{Name: "Film B", Genres: []string{"comedy"}, WatchedAt: &watchedAt},
+	}
+
+	expected := []GenreCount{
+		{Genre: "comedy", Unwatched: 1, Watching: 1},
+		{Genre: "crime", Unwatched: 1},
+		{Genre: "drama", Unwatched: 2, Films: 1},
+		{Genre: "war", Films: 1},
+	}
+
+	result := GetGenreCounts(catalogue, current, films)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+
+	if result := GetGenreCounts(nil, nil, nil); len(result) != 0 {
+		t.Errorf("expected no genres, got %+v", result)
+	}
+}
+
+func TestGetProviderCounts(t *testing.T) {
+	watchedAt := time.Date(2025, 11, 1, 20, 0, 0, 0, time.UTC)
+	catalogue := []data.Show{
+		{Name: "Show A", Provider: "Netflix"},
+		{Name: "Show B", Provider: "BBC iPlayer"},
+		{Name: "Show C", Provider: "Netflix", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+	}
+	current := []data.Show{
+		{Name: "Show D", Provider: "netflix", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+		{Name: "Show E", Provider: "Channel 4"},
+	}
+	films := []data.Film{
+		{Name: "Film A", Provider: "Disney+"},
+		{Name: "Film B", Provider: "BBC iPlayer", WatchedAt: &watchedAt},
+		{Name: "Film C"},
+	}
+
+	expected := []ProviderCount{
+		{Provider: "BBC iPlayer", Unwatched: 1},
+		{Provider: "Disney+", Films: 1},
+		{Provider: "Netflix", Unwatched: 1, Watching: 1},
+	}
+
+	result := GetProviderCounts(catalogue, current, films)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestGetUnwatchedShowsMatching(t *testing.T) {
+	shows := []data.Show{
+		{Name: "Show A", Genres: []string{"drama"}, Provider: "Netflix"},
+		{Name: "Show B", Genres: []string{"comedy"}, Provider: "BBC iPlayer"},
+		{Name: "Show C", Genres: []string{"drama"}, Provider: "Netflix", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+		{Name: "Show D", Genres: []string{"drama", "crime"}, Provider: "Disney+"},
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{name: "no filter", expected: []string{"Show A", "Show B", "Show D"}},
+		{name: "provider", filter: Filter{Providers: []string{"netflix"}}, expected: []string{"Show A"}},
+		{name: "several providers", filter: Filter{Providers: []string{"Disney+", "BBC iPlayer"}}, expected: []string{"Show B", "Show D"}},
+		{name: "genre and provider", filter: Filter{Genres: []string{"drama"}, Providers: []string{"Disney+", "BBC iPlayer"}}, expected: []string{"Show D"}},
+		{name: "all genres", filter: Filter{Genres: []string{"drama", "crime"}, MatchAll: true}, expected: []string{"Show D"}},
+		{name: "nothing matches", filter: Filter{Providers: []string{"Channel 4"}}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, s := range GetUnwatchedShowsMatching(shows, tt.filter) {
+				names = append(names, s.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestMatching(t *testing.T) {
+	shows := []data.Show{
+		{Name: "Show A", Provider: "Netflix", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+		{Name: "Show B", Provider: "BBC iPlayer", CurrentSeries: intPtr(2), CurrentEpisode: intPtr(1)},
+		{Name: "Show C", Provider: "Netflix"},
+	}
+
+	var names []string
+	for _, s := range Matching(shows, Filter{Providers: []string{"NETFLIX"}}) {
+		names = append(names, s.Name)
+	}
+
+	expected := []string{"Show A", "Show C"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestGetUnwatchedShows(t *testing.T) {
 	tests := []struct {
 		name     string
 		shows    []data.Show
-		genre    string
 		expected []data.Show
 	}{
 		{
 			name:     "no shows",
 			shows:    []data.Show{},
-			genre:    "Drama",
 			expected: []data.Show(nil),
 		},
 		{
-			name: "no unwatched shows for genre",
+			name: "filters out shows being watched",
 			shows: []data.Show{
-				{Name: "Show A", Genre: "Drama", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
-				{Name: "Show B", Genre: "Comedy"},
+				{Name: "Show A", Genres: []string{"Drama"}},
+				{Name: "Show B", Genres: []string{"Comedy"}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+				{Name: "Show C", Genres: []string{"Drama"}},
+			},
+			expected: []data.Show{
+				{Name: "Show A", Genres: []string{"Drama"}},
+				{Name: "Show C", Genres: []string{"Drama"}},
 			},
-			genre:    "Drama",
-			expected: []data.Show(nil),
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := GetUnwatchedShows(tt.shows)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestStartWatching(t *testing.T) {
+	tests := []struct {
+		name              string
+		catalogue         []data.Show
+		current           []data.Show
+		id                int
+		expectedCatalogue []data.Show
+		expectedCurrent   []data.Show
+		expectedErr       error
+	}{
 		{
-			name: "get unwatched shows for genre",
-			shows: []data.Show{
-				{Name: "Show A", Genre: "Drama"},
-				{Name: "Show B", Genre: "Comedy"},
-				{Name: "Show C", Genre: "Drama", CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
-				{Name: "Show D", Genre: "Drama"},
+			name: "start first show",
+			catalogue: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{6}},
+				{ID: 2, Name: "Show B", Episodes: []int{8}},
 			},
-			genre: "Drama",
-			expected: []data.Show{
-				{Name: "Show A", Genre: "Drama"},
-				{Name: "Show D", Genre: "Drama"},
+			current: []data.Show{},
+			id:      1,
+			expectedCatalogue: []data.Show{
+				{ID: 2, Name: "Show B", Episodes: []int{8}},
+			},
+			expectedCurrent: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
 			},
 		},
 		{
-			name: "genre does not exist",
-			shows: []data.Show{
-				{Name: "Show A", Genre: "Drama"},
-				{Name: "Show B", Genre: "Comedy"},
+			name: "append to currently watching shows",
+			catalogue: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+				{ID: 2, Name: "Show B", Episodes: []int{8}},
 			},
-			genre:    "Horror",
-			expected: []data.Show(nil),
+			current: []data.Show{
+				{ID: 3, Name: "Show C", Episodes: []int{4}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+			},
+			id: 2,
+			expectedCatalogue: []data.Show{
+				{ID: 1, Name: "Show A", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)},
+			},
+			expectedCurrent: []data.Show{
+				{ID: 3, Name: "Show C", Episodes: []int{4}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(2)},
+				{ID: 2, Name: "Show B", Episodes: []int{8}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(1)},
+			},
+		},
+		{
+			name:        "id not found",
+			catalogue:   []data.Show{{ID: 1, Name: "Show A", Episodes: []int{6}}},
+			id:          2,
+			expectedErr: ErrShowNotFound,
+		},
+		{
+			name:        "show already started in catalogue",
+			catalogue:   []data.Show{{ID: 1, Name: "Show A", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(3)}},
+			id:          1,
+			expectedErr: ErrAlreadyStarted,
+		},
+		{
+			name:        "show has no episodes",
+			catalogue:   []data.Show{{ID: 1, Name: "Show A"}},
+			id:          1,
+			expectedErr: ErrInvalidShow,
+		},
+		{
+			name:        "show already being watched",
+			catalogue:   []data.Show{{ID: 1, Name: "Show A", Episodes: []int{6}}},
+			current:     []data.Show{{ID: 1, Name: "Show A", Episodes: []int{6}, CurrentSeries: intPtr(1), CurrentEpisode: intPtr(4)}},
+			id:          1,
+			expectedErr: ErrAlreadyStarted,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GetUnwatchedShowsByGenre(tt.shows, tt.genre)
-			if !reflect.DeepEqual(result, tt.expected) {
-				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			catalogue, current, started, err := StartWatching(tt.catalogue, tt.current, tt.id)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(catalogue, tt.expectedCatalogue) {
+				t.Errorf("expected catalogue %+v, got %+v", tt.expectedCatalogue, catalogue)
+			}
+
+			if !reflect.DeepEqual(current, tt.expectedCurrent) {
+				t.Errorf("expected current %+v, got %+v", tt.expectedCurrent, current)
+			}
+
+			if !reflect.DeepEqual(started, tt.expectedCurrent[len(tt.expectedCurrent)-1]) {
+				t.Errorf("expected started show %+v, got %+v", tt.expectedCurrent[len(tt.expectedCurrent)-1], started)
 			}
 		})
 	}
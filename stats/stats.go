@@ -0,0 +1,206 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"what-to-watch/data"
+)
+
+// Weeks is the number of weeks, including the current one, covered by Stats.EpisodesPerWeek
+const Weeks = 8
+
+// Library holds the stored data the statistics are computed from.
+type Library struct {
+	Catalogue []data.Show
+	Current   []data.Show
+	Completed []data.Show
+	Films     []data.Film
+	// History is the watch log of every episode marked as watched
+	History []data.WatchEvent
+}
+
+// Stats are the aggregated viewing statistics.
+type Stats struct {
+	EpisodesWatched int `json:"episodesWatched"`
+	FilmsWatched    int `json:"filmsWatched"`
+	// EpisodesPerWeek covers the last Weeks weeks, oldest first, including weeks with nothing watched
+	EpisodesPerWeek []WeekCount `json:"episodesPerWeek"`
+	// FavouriteGenres and BusiestProviders count episodes and films watched, most watched first.
+	// Something with several genres counts towards each of them
+	FavouriteGenres  []Count `json:"favouriteGenres"`
+	BusiestProviders []Count `json:"busiestProviders"`
+	// CompletedPerMonth only includes months in which a show was completed, oldest first
+	CompletedPerMonth []MonthCount `json:"completedPerMonth"`
+	// CurrentStreak is the number of consecutive days, ending today or yesterday, with something watched
+	CurrentStreak int `json:"currentStreak"`
+}
+
+// WeekCount is the number of episodes watched in the week starting on Monday WeekStart.
+type WeekCount struct {
+	WeekStart string `json:"weekStart"`
+	Episodes  int    `json:"episodes"`
+}
+
+// MonthCount is the number of shows completed in a month, formatted as YYYY-MM.
+type MonthCount struct {
+	Month string `json:"month"`
+	Shows int    `json:"shows"`
+}
+
+// Count is the number of things watched for a genre or provider.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Compute aggregates the statistics as of now. Days and weeks are in now's location.
+// Episodes in the history are matched to their show by ID to find the genre and provider;
+// episodes of shows that have since been deleted still count towards the totals.
+func Compute(lib Library, now time.Time) Stats {
+	shows := map[int]data.Show{}
+	for _, list := range [][]data.Show{lib.Catalogue, lib.Current, lib.Completed} {
+		for _, s := range list {
+			shows[s.ID] = s
+		}
+	}
+
+	st := Stats{EpisodesWatched: len(lib.History)}
+	genres := newCounter()
+	providers := newCounter()
+	days := map[time.Time]bool{}
+
+	for _, e := range lib.History {
+		days[startOfDay(e.WatchedAt, now.Location())] = true
+		if s, ok := shows[e.ShowID]; ok {
+			genres.add(s.Genres...)
+			providers.add(s.Provider)
+		}
+	}
+
+	for _, f := range lib.Films {
+		if f.WatchedAt == nil {
+			continue
+		}
+		st.FilmsWatched++
+		days[startOfDay(*f.WatchedAt, now.Location())] = true
+		genres.add(f.Genres...)
+		providers.add(f.Provider)
+	}
+
+	st.EpisodesPerWeek = episodesPerWeek(lib.History, now)
+	st.FavouriteGenres = genres.sorted()
+	st.BusiestProviders = providers.sorted()
+	st.CompletedPerMonth = completedPerMonth(lib.Completed, now.Location())
+	st.CurrentStreak = currentStreak(days, now)
+
+	return st
+}
+
+// episodesPerWeek counts the episodes watched in each of the last Weeks weeks.
+func episodesPerWeek(history []data.WatchEvent, now time.Time) []WeekCount {
+	first := startOfWeek(now).AddDate(0, 0, -7*(Weeks-1))
+
+	weeks := make([]WeekCount, Weeks)
+	index := map[string]int{}
+	for i := range weeks {
+		weeks[i].WeekStart = first.AddDate(0, 0, 7*i).Format("2006-01-02")
+		index[weeks[i].WeekStart] = i
+	}
+
+	for _, e := range history {
+		if i, ok := index[startOfWeek(e.WatchedAt.In(now.Location())).Format("2006-01-02")]; ok {
+			weeks[i].Episodes++
+		}
+	}
+
+	return weeks
+}
+
+// completedPerMonth counts the shows completed in each month that had any completed.
+func completedPerMonth(completed []data.Show, loc *time.Location) []MonthCount {
+	counts := map[string]int{}
+	for _, s := range completed {
+		if s.CompletedAt != nil {
+			counts[s.CompletedAt.In(loc).Format("2006-01")]++
+		}
+	}
+
+	months := make([]MonthCount, 0, len(counts))
+	for month, n := range counts {
+		months = append(months, MonthCount{Month: month, Shows: n})
+	}
+	slices.SortFunc(months, func(a, b MonthCount) int { return strings.Compare(a.Month, b.Month) })
+
+	return months
+}
+
+// currentStreak counts back through consecutive days with something watched, starting today,
+// or yesterday if nothing has been watched yet today.
+func currentStreak(days map[time.Time]bool, now time.Time) int {
+	day := startOfDay(now, now.Location())
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for days[day] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// startOfWeek returns midnight on the Monday of the week containing t.
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// counter counts names case-insensitively, keeping the first spelling seen.
+type counter struct {
+	names  map[string]string
+	counts map[string]int
+}
+
+func newCounter() *counter {
+	return &counter{names: map[string]string{}, counts: map[string]int{}}
+}
+
+func (c *counter) add(names ...string) {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		key := strings.ToLower(name)
+		if _, ok := c.names[key]; !ok {
+			c.names[key] = name
+		}
+		c.counts[key]++
+	}
+}
+
+// sorted returns the counts, highest first and then by name.
+func (c *counter) sorted() []Count {
+	counts := make([]Count, 0, len(c.counts))
+	for key, n := range c.counts {
+		counts = append(counts, Count{Name: c.names[key], Count: n})
+	}
+	slices.SortFunc(counts, func(a, b Count) int {
+		if n := cmp.Compare(b.Count, a.Count); n != 0 {
+			return n
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return counts
+}
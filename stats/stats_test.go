@@ -0,0 +1,130 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+)
+
+func TestCompute(t *testing.T) {
+	// a Wednesday evening
+	now := time.Date(2025, 11, 19, 21, 0, 0, 0, time.UTC)
+	day := func(daysAgo int) time.Time { return now.AddDate(0, 0, -daysAgo) }
+	completedAt := func(month time.Month) *time.Time {
+		t := time.Date(2025, month, 10, 20, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	lib := Library{
+		Catalogue: []data.Show{{ID: 1, Name: "Show A", Genres: []string{"Drama"}, Provider: "Netflix"}},
+		Current:   []data.Show{{ID: 2, Name: "Show B", Genres: []string{"comedy"}, Provider: "BBC iPlayer"}},
+		Completed: []data.Show{
+			{ID: 3, Name: "Show C", Genres: []string{"Comedy"}, Provider: "Netflix", CompletedAt: completedAt(9)},
+			{ID: 4, Name: "Show D", Genres: []string{"Drama"}, Provider: "Netflix", CompletedAt: completedAt(11)},
+			{ID: 5, Name: "Show E", Genres: []string{"Drama"}, Provider: "Netflix", CompletedAt: completedAt(9)},
+		},
+		Films: []data.Film{
+			{ID: 1, Name: "Film A", Genres: []string{"Drama"}, Provider: "Disney+", WatchedAt: ptr(day(3))},
+			{ID: 2, Name: "Film B", Genres: []string{"Horror"}, Provider: "Netflix"},
+		},
+		History: []data.WatchEvent{
+			{ShowID: 2, WatchedAt: day(60)},
+			{ShowID: 3, WatchedAt: day(9)},
+			{ShowID: 2, WatchedAt: day(2)},
+			{ShowID: 2, WatchedAt: day(1)},
+			{ShowID: 2, WatchedAt: day(0)},
+			{ShowID: 3, WatchedAt: day(0)},
+			// a deleted show still counts towards the totals
+			{ShowID: 99, WatchedAt: day(0)},
+		},
+	}
+
+	expected := Stats{
+		EpisodesWatched: 7,
+		FilmsWatched:    1,
+		EpisodesPerWeek: []WeekCount{
+			{WeekStart: "2025-09-29"},
+			{WeekStart: "2025-10-06"},
+			{WeekStart: "2025-10-13"},
+			{WeekStart: "2025-10-20"},
+			{WeekStart: "2025-10-27"},
+			{WeekStart: "2025-11-03"},
+			{WeekStart: "2025-11-10", Episodes: 1},
+			{WeekStart: "2025-11-17", Episodes: 5},
+		},
+		FavouriteGenres: []Count{
+			{Name: "comedy", Count: 6},
+			{Name: "Drama", Count: 1},
+		},
+		BusiestProviders: []Count{
+			{Name: "BBC iPlayer", Count: 4},
+			{Name: "Netflix", Count: 2},
+			{Name: "Disney+", Count: 1},
+		},
+		CompletedPerMonth: []MonthCount{
+			{Month: "2025-09", Shows: 2},
+			{Month: "2025-11", Shows: 1},
+		},
+		CurrentStreak: 4,
+	}
+
+	result := Compute(lib, now)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	now := time.Date(2025, 11, 19, 21, 0, 0, 0, time.UTC)
+	watchedDays := func(daysAgo ...int) map[time.Time]bool {
+		days := map[time.Time]bool{}
+		for _, d := range daysAgo {
+			days[startOfDay(now.AddDate(0, 0, -d), time.UTC)] = true
+		}
+		return days
+	}
+
+	tests := []struct {
+		name     string
+		days     map[time.Time]bool
+		expected int
+	}{
+		{name: "nothing watched", days: watchedDays(), expected: 0},
+		{name: "watched today", days: watchedDays(0, 1, 2, 4), expected: 3},
+		{name: "nothing yet today", days: watchedDays(1, 2), expected: 2},
+		{name: "broken streak", days: watchedDays(2, 3), expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := currentStreak(tt.days, now); result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        time.Time
+		expected time.Time
+	}{
+		{name: "monday", t: time.Date(2025, 11, 17, 8, 0, 0, 0, time.UTC), expected: time.Date(2025, 11, 17, 0, 0, 0, 0, time.UTC)},
+		{name: "sunday", t: time.Date(2025, 11, 23, 23, 0, 0, 0, time.UTC), expected: time.Date(2025, 11, 17, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := startOfWeek(tt.t); !result.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,159 @@
+package subscriptions
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"what-to-watch/data"
+	"what-to-watch/shows"
+)
+
+// ErrInvalidSubscription is returned when a subscription being saved fails validation.
+var ErrInvalidSubscription = errors.New("invalid subscription")
+
+// ErrSubscriptionNotFound is returned when there is no subscription to the requested provider.
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// Validate checks that a subscription has a provider and does not end before it starts.
+func Validate(sub data.Subscription) error {
+	if strings.TrimSpace(sub.Provider) == "" {
+		return fmt.Errorf("%w: provider is required", ErrInvalidSubscription)
+	}
+	if sub.Start != nil && sub.End != nil && !sub.End.After(*sub.Start) {
+		return fmt.Errorf("%w: end must be after start", ErrInvalidSubscription)
+	}
+
+	return nil
+}
+
+// IsActive reports whether the subscription is active at now: on or after its start, if it has
+// one, and before its end, if it has one.
+func IsActive(sub data.Subscription, now time.Time) bool {
+	if sub.Start != nil && now.Before(*sub.Start) {
+		return false
+	}
+	if sub.End != nil && !now.Before(*sub.End) {
+		return false
+	}
+	return true
+}
+
+// ActiveProviders returns the providers with a subscription active at now, in the order
+// they were subscribed to.
+func ActiveProviders(subs []data.Subscription, now time.Time) []string {
+	var active []string
+	for _, sub := range subs {
+		if IsActive(sub, now) && !containsFold(active, sub.Provider) {
+			active = append(active, sub.Provider)
+		}
+	}
+	return active
+}
+
+// Restrict narrows the wanted providers to those that are active. When no providers are wanted,
+// every active provider is returned. An empty result means nothing can match.
+func Restrict(want, active []string) []string {
+	if len(want) == 0 {
+		return slices.Clone(active)
+	}
+
+	var restricted []string
+	for _, p := range want {
+		if containsFold(active, p) {
+			restricted = append(restricted, p)
+		}
+	}
+	return restricted
+}
+
+// Set validates the subscription and saves it, replacing any existing subscription to the same
+// provider (ignoring case) in place, or adding it to the end otherwise.
+// It returns the updated subscriptions and the saved subscription.
+func Set(subs []data.Subscription, sub data.Subscription) ([]data.Subscription, data.Subscription, error) {
+	if err := Validate(sub); err != nil {
+		return nil, data.Subscription{}, err
+	}
+	sub.Provider = strings.TrimSpace(sub.Provider)
+
+	updated := slices.Clone(subs)
+	if i := find(updated, sub.Provider); i >= 0 {
+		updated[i] = sub
+	} else {
+		updated = append(updated, sub)
+	}
+	return updated, sub, nil
+}
+
+// Remove deletes the subscription to the given provider, ignoring case.
+// It returns the updated subscriptions and the removed subscription.
+func Remove(subs []data.Subscription, provider string) ([]data.Subscription, data.Subscription, error) {
+	i := find(subs, provider)
+	if i < 0 {
+		return nil, data.Subscription{}, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, provider)
+	}
+
+	removed := subs[i]
+	return slices.Delete(slices.Clone(subs), i, i+1), removed, nil
+}
+
+// Unlock is how much of the backlog a provider holds, and whether it is currently subscribed to.
+type Unlock struct {
+	Provider  string `json:"provider"`
+	Active    bool   `json:"active"`
+	Unwatched int    `json:"unwatched"`
+	Watching  int    `json:"watching"`
+	Films     int    `json:"films"`
+	// Total is the number of unwatched shows, shows being watched and unwatched films together
+	Total int `json:"total"`
+}
+
+// Report counts the unwatched shows in the catalogue, the shows being watched and the unwatched
+// films on every provider, marking those with a subscription active at now. Subscribed providers
+// with nothing in the backlog are included too. The result is sorted with the provider that
+// unlocks the most first, then by provider.
+func Report(catalogue, current []data.Show, films []data.Film, subs []data.Subscription, now time.Time) []Unlock {
+	active := ActiveProviders(subs, now)
+
+	var report []Unlock
+	for _, c := range shows.GetProviderCounts(catalogue, current, films) {
+		report = append(report, Unlock{
+			Provider:  c.Provider,
+			Active:    containsFold(active, c.Provider),
+			Unwatched: c.Unwatched,
+			Watching:  c.Watching,
+			Films:     c.Films,
+			Total:     c.Unwatched + c.Watching + c.Films,
+		})
+	}
+	for _, sub := range subs {
+		if !slices.ContainsFunc(report, func(u Unlock) bool { return strings.EqualFold(u.Provider, sub.Provider) }) {
+			report = append(report, Unlock{Provider: sub.Provider, Active: containsFold(active, sub.Provider)})
+		}
+	}
+
+	slices.SortStableFunc(report, func(a, b Unlock) int {
+		if a.Total != b.Total {
+			return b.Total - a.Total
+		}
+		return strings.Compare(strings.ToLower(a.Provider), strings.ToLower(b.Provider))
+	})
+	return report
+}
+
+// find returns the position of the subscription to the given provider, ignoring case and
+// surrounding whitespace, or -1 if there is none.
+func find(subs []data.Subscription, provider string) int {
+	return slices.IndexFunc(subs, func(s data.Subscription) bool {
+		return strings.EqualFold(strings.TrimSpace(s.Provider), strings.TrimSpace(provider))
+	})
+}
+
+// containsFold reports whether list contains s, ignoring case and surrounding whitespace.
+func containsFold(list []string, s string) bool {
+	return slices.ContainsFunc(list, func(v string) bool {
+		return strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(s))
+	})
+}
@@ -0,0 +1,180 @@
+package subscriptions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"what-to-watch/data"
+)
+
+func date(month, day int) *time.Time {
+	t := time.Date(2025, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     data.Subscription
+		wantErr bool
+	}{
+		{name: "provider only", sub: data.Subscription{Provider: "Netflix"}},
+		{name: "start and end", sub: data.Subscription{Provider: "Netflix", Start: date(11, 1), End: date(12, 1)}},
+		{name: "missing provider", sub: data.Subscription{Provider: "  "}, wantErr: true},
+		{name: "ends before it starts", sub: data.Subscription{Provider: "Netflix", Start: date(12, 1), End: date(11, 1)}, wantErr: true},
+		{name: "ends as it starts", sub: data.Subscription{Provider: "Netflix", Start: date(11, 1), End: date(11, 1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.sub)
+			if tt.wantErr && !errors.Is(err, ErrInvalidSubscription) {
+				t.Errorf("expected ErrInvalidSubscription, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestActiveProviders(t *testing.T) {
+	subs := []data.Subscription{
+		{Provider: "Netflix"},
+		{Provider: "Disney+", Start: date(11, 1), End: date(12, 1)},
+		{Provider: "Prime Video", End: date(11, 15)},
+		{Provider: "NOW", Start: date(11, 20)},
+		{Provider: "netflix"},
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected []string
+	}{
+		{name: "before any dated subscription", now: *date(10, 1), expected: []string{"Netflix", "Prime Video"}},
+		{name: "start is inclusive", now: *date(11, 1), expected: []string{"Netflix", "Disney+", "Prime Video"}},
+		{name: "end is exclusive", now: *date(11, 15), expected: []string{"Netflix", "Disney+"}},
+		{name: "after every end", now: *date(12, 1), expected: []string{"Netflix", "NOW"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := ActiveProviders(subs, tt.now); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	active := []string{"Netflix", "Disney+"}
+
+	tests := []struct {
+		name     string
+		want     []string
+		expected []string
+	}{
+		{name: "no wanted providers gives every active one", expected: []string{"Netflix", "Disney+"}},
+		{name: "keeps wanted active providers", want: []string{"netflix", "NOW"}, expected: []string{"netflix"}},
+		{name: "nothing active wanted", want: []string{"NOW"}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Restrict(tt.want, active); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	if result := Restrict(nil, nil); len(result) != 0 {
+		t.Errorf("expected nothing when no subscriptions are active, got %v", result)
+	}
+}
+
+func TestSet(t *testing.T) {
+	subs := []data.Subscription{{Provider: "Netflix"}, {Provider: "Disney+"}}
+
+	updated, saved, err := Set(subs, data.Subscription{Provider: " NOW ", Start: date(11, 1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []data.Subscription{{Provider: "Netflix"}, {Provider: "Disney+"}, {Provider: "NOW", Start: date(11, 1)}}
+	if !reflect.DeepEqual(updated, expected) {
+		t.Errorf("expected %+v, got %+v", expected, updated)
+	}
+	if saved.Provider != "NOW" {
+		t.Errorf("expected saved provider to be trimmed, got %q", saved.Provider)
+	}
+
+	updated, _, err = Set(subs, data.Subscription{Provider: "netflix", End: date(12, 1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = []data.Subscription{{Provider: "netflix", End: date(12, 1)}, {Provider: "Disney+"}}
+	if !reflect.DeepEqual(updated, expected) {
+		t.Errorf("expected existing subscription to be replaced in place, got %+v", updated)
+	}
+	if subs[0].End != nil {
+		t.Errorf("expected the original subscriptions to be untouched, got %+v", subs)
+	}
+
+	if _, _, err := Set(subs, data.Subscription{}); !errors.Is(err, ErrInvalidSubscription) {
+		t.Errorf("expected ErrInvalidSubscription, got %v", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	subs := []data.Subscription{{Provider: "Netflix"}, {Provider: "Disney+"}}
+
+	updated, removed, err := Remove(subs, "disney+")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []data.Subscription{{Provider: "Netflix"}}; !reflect.DeepEqual(updated, expected) {
+		t.Errorf("expected %+v, got %+v", expected, updated)
+	}
+	if removed.Provider != "Disney+" {
+		t.Errorf("expected Disney+ to be removed, got %+v", removed)
+	}
+
+	if _, _, err := Remove(subs, "NOW"); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("expected ErrSubscriptionNotFound, got %v", err)
+	}
+}
+
+func TestReport(t *testing.T) {
+	series, episode := 1, 1
+	watchedAt := *date(10, 1)
+	catalogue := []data.Show{
+		{ID: 1, Name: "Show A", Provider: "Netflix"},
+		{ID: 2, Name: "Show B", Provider: "Disney+"},
+		{ID: 3, Name: "Show C", Provider: "Disney+"},
+	}
+	current := []data.Show{
+		{ID: 4, Name: "Show D", Provider: "Netflix", CurrentSeries: &series, CurrentEpisode: &episode},
+	}
+	films := []data.Film{
+		{ID: 1, Name: "Film A", Provider: "Disney+"},
+		{ID: 2, Name: "Film B", Provider: "Sky Cinema"},
+		{ID: 3, Name: "Film C", Provider: "Netflix", WatchedAt: &watchedAt},
+	}
+	subs := []data.Subscription{
+		{Provider: "Netflix"},
+		{Provider: "NOW"},
+		{Provider: "Sky Cinema", End: date(11, 1)},
+	}
+
+	expected := []Unlock{
+		{Provider: "Disney+", Unwatched: 2, Films: 1, Total: 3},
+		{Provider: "Netflix", Active: true, Unwatched: 1, Watching: 1, Total: 2},
+		{Provider: "Sky Cinema", Films: 1, Total: 1},
+		{Provider: "NOW", Active: true},
+	}
+	if result := Report(catalogue, current, films, subs, *date(11, 10)); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}